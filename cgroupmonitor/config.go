@@ -0,0 +1,23 @@
+package cgroupmonitor
+
+import (
+	"errors"
+)
+
+type ComponentConfig struct {
+	Cgroups     []string `json:"cgroups"`     // cgroup paths relative to /sys/fs/cgroup, or systemd unit names
+	ViamServer  bool     `json:"viam_server"` // Also monitor the cgroup viam-server runs in
+	SleepTimeMs int      `json:"sleep_time_ms"`
+}
+
+func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
+	if len(conf.Cgroups) == 0 && !conf.ViamServer {
+		return nil, nil, errors.New("cgroups or viam_server is required")
+	}
+	for _, cgroup := range conf.Cgroups {
+		if cgroup == "" {
+			return nil, nil, errors.New("cgroups must not contain empty names")
+		}
+	}
+	return nil, nil, nil
+}
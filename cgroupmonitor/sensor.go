@@ -0,0 +1,201 @@
+package cgroupmonitor
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	viamutils "go.viam.com/utils"
+)
+
+var (
+	Model       = resource.NewModel(utils.Namespace, "hwmonitor", "cgroup_monitor")
+	API         = sensor.API
+	PrettyName  = "SBC cgroup Monitor Sensor"
+	Description = "A sensor that reports the resource usage and limits of cgroups, like viam-server's"
+	Version     = utils.Version
+)
+
+// viamServerReading is the reading name of viam-server's cgroup, which is the cgroup of this module's parent process
+const viamServerReading = "viam_server"
+
+type Config struct {
+	resource.Named
+	readingsLock sync.RWMutex
+	configLock   sync.Mutex
+	logger       logging.Logger
+	sleepTime    time.Duration
+	cgroups      map[string]string // Reading name to configured cgroup path or unit name
+	workers      *viamutils.StoppableWorkers
+	reading      map[string]interface{}
+	config       *ComponentConfig
+	history      *utils.ReadingsHistory
+	commands     *utils.CommandDispatcher
+	refresh      *utils.Refresher
+}
+
+func init() {
+	resource.RegisterComponent(
+		API,
+		Model,
+		resource.Registration[sensor.Sensor, *ComponentConfig]{Constructor: NewSensor})
+}
+
+func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+	logger.Infof("Starting %s %s", PrettyName, Version)
+	b := Config{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		readingsLock: sync.RWMutex{},
+		configLock:   sync.Mutex{},
+		history:      utils.NewReadingsHistory(utils.DefaultHistorySize),
+		refresh:      utils.NewRefresher(),
+	}
+	b.commands = b.newCommandDispatcher()
+
+	if err := b.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Started %s %s", PrettyName, Version)
+	return &b, nil
+}
+
+func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawConf resource.Config) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Reconfiguring %s", PrettyName)
+
+	if c.workers != nil {
+		c.logger.Debug("Stopping background worker")
+		c.workers.Stop()
+		c.logger.Debugf("Background worker stopped")
+	}
+
+	conf, err := resource.NativeConfig[*ComponentConfig](rawConf)
+	if err != nil {
+		return err
+	}
+
+	cgroups := make(map[string]string)
+	for _, cgroup := range conf.Cgroups {
+		cgroups[cgroup] = cgroup
+	}
+	if conf.ViamServer {
+		cgroup, err := sensors.GetProcessCgroup(ctx, os.Getppid())
+		if err != nil {
+			return err
+		}
+		cgroups[viamServerReading] = cgroup
+	}
+
+	// In case the component has changed name
+	c.Named = rawConf.ResourceName().AsNamed()
+	if conf.SleepTimeMs <= 0 {
+		// Default to 1000ms if no sleep time is provided
+		c.logger.Warnf("Invalid sleep time %d, defaulting to 1000ms", conf.SleepTimeMs)
+		conf.SleepTimeMs = 1000 // Default to 1 second
+	}
+	c.sleepTime = time.Duration(conf.SleepTimeMs * int(time.Millisecond))
+	c.cgroups = cgroups
+	c.config = conf
+	c.workers = viamutils.NewBackgroundStoppableWorkers(c.startUpdating)
+
+	c.logger.Debugf("Reconfigure complete %s", PrettyName)
+	return nil
+}
+
+func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	c.readingsLock.RLock()
+	defer c.readingsLock.RUnlock()
+	return c.reading, nil
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.configLock.Lock()
+			defer c.configLock.Unlock()
+			return c.config
+		},
+		Refresh: c.refresh.Refresh(c.Readings),
+		History: c.history,
+	})
+}
+
+func (c *Config) Close(ctx context.Context) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Shutting down %v", PrettyName)
+	c.workers.Stop()
+	c.logger.Infof("%v Shutdown complete", PrettyName)
+	return nil
+}
+
+// startUpdating is a goroutine that samples the cgroups every sleepTime so the counters can be reported as rates
+func (c *Config) startUpdating(ctx context.Context) {
+	var last map[string]*sensors.CgroupStats
+	for {
+		if last == nil {
+			last = c.readSample(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case done := <-c.refresh.C():
+			last = c.updateReadings(ctx, last)
+			close(done)
+		case <-time.After(c.sleepTime):
+			last = c.updateReadings(ctx, last)
+		}
+	}
+}
+
+// readSample reads every cgroup, keyed by reading name. Unit names are resolved every time, so a container that is
+// started or restarted after this sensor is picked up.
+func (c *Config) readSample(ctx context.Context) map[string]*sensors.CgroupStats {
+	sample := make(map[string]*sensors.CgroupStats)
+	for name, cgroup := range c.cgroups {
+		path, err := sensors.ResolveCgroup(cgroup)
+		if err != nil {
+			c.logger.Debugf("Failed to find cgroup %s: %v", cgroup, err)
+			continue
+		}
+		stats, err := sensors.ReadCgroupStats(ctx, path)
+		if err != nil {
+			c.logger.Warnf("Failed to read cgroup %s: %v", path, err)
+			continue
+		}
+		sample[name] = stats
+	}
+	return sample
+}
+
+// updateReadings computes usage since last and returns the sample to diff against next time
+func (c *Config) updateReadings(ctx context.Context, last map[string]*sensors.CgroupStats) map[string]*sensors.CgroupStats {
+	curr := c.readSample(ctx)
+	ret := make(map[string]interface{})
+	for name, stats := range curr {
+		prev, ok := last[name]
+		if !ok {
+			continue
+		}
+		ret[name] = sensors.CalculateCgroupUsage(prev, stats)
+	}
+	c.readingsLock.Lock()
+	c.reading = ret
+	c.readingsLock.Unlock()
+	c.history.Record(ret)
+	return curr
+}
@@ -0,0 +1,56 @@
+package cgroupmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+)
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&ComponentConfig{}).Validate("")
+	assert.Error(t, err)
+	_, _, err = (&ComponentConfig{Cgroups: []string{""}}).Validate("")
+	assert.Error(t, err)
+	_, _, err = (&ComponentConfig{ViamServer: true}).Validate("")
+	assert.NoError(t, err)
+}
+
+func TestUpdateReadings(t *testing.T) {
+	utilstest.UseSensorsFixtures(t)
+	ctx := context.Background()
+	sensor := &Config{
+		logger: logging.NewTestLogger(t),
+		cgroups: map[string]string{
+			"viam-server.service": "viam-server.service",
+			"docker.service":      "docker.service",
+		},
+		history: utils.NewReadingsHistory(10),
+	}
+
+	last := sensor.updateReadings(ctx, nil)
+	require.Len(t, last, 1)
+	assert.Empty(t, sensor.reading)
+
+	// Pretend the previous sample was taken a second ago, 500ms of CPU time ago
+	last["viam-server.service"].Timestamp = time.Now().Add(-time.Second)
+	last["viam-server.service"].CPU.UsageUsec -= 500000
+	sensor.updateReadings(ctx, last)
+	require.Contains(t, sensor.reading, "viam-server.service")
+	assert.NotContains(t, sensor.reading, "docker.service")
+
+	readings := sensor.reading["viam-server.service"].(map[string]interface{})
+	assert.InDelta(t, 50.0, readings["cpu_usage_percent"], 5)
+	assert.Equal(t, 2.0, readings["cpu_limit"])
+	assert.InDelta(t, 25.0, readings["cpu_limit_percent"], 2.5)
+	assert.Equal(t, 50.0, readings["memory_limit_percent"])
+	assert.Equal(t, uint64(1), readings["memory_oom_kill"])
+	assert.Equal(t, uint64(42), readings["pids_current"])
+	assert.NotContains(t, readings, "pids_limit_percent")
+}
@@ -5,6 +5,7 @@ package clocks
 
 import (
 	"context"
+	"sort"
 	"testing"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+
 	. "github.com/rinzlerlabs/sbcidentify/test"
 )
 
@@ -30,7 +34,7 @@ func TestRaspberryPiGetReadings(t *testing.T) {
 	}
 	readings, err := sensor.Readings(ctx, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, len(clocks), len(readings))
+	assert.Equal(t, expectedReadingKeys(t, clocks), readingKeys(readings))
 }
 
 func TestNvidiaGetReadings(t *testing.T) {
@@ -52,8 +56,64 @@ func TestNvidiaGetReadings(t *testing.T) {
 	sensor.Close(ctx)
 	readings, err := sensor.Readings(ctx, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, len(clocks), len(readings))
+	assert.Equal(t, expectedReadingKeys(t, clocks), readingKeys(readings))
 	for i, reading := range readings {
 		t.Logf("Reading %s: %v", i, reading)
 	}
 }
+
+func TestSysFsGetReadings(t *testing.T) {
+	utilstest.UseFixtureFS(t, map[string]string{
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_cur_freq": "1500000\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_min_freq": "600000\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_max_freq": "1800000\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor": "ondemand\n",
+		"/sys/devices/system/cpu/cpu1/cpufreq/scaling_cur_freq": "1200000\n",
+		"/sys/class/devfreq/fb000000.gpu/cur_freq":              "300000000\n",
+	})
+	logger := logging.NewTestLogger(t)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	clocks, err := sensors.GetSysFsClockSensors(ctx, logger)
+	require.NoError(t, err)
+	require.Len(t, clocks, 3)
+	sensor := &Config{
+		sensors:    clocks,
+		logger:     logger,
+		cancelCtx:  ctx,
+		cancelFunc: cancelFunc,
+	}
+	defer sensor.Close(ctx)
+	readings, err := sensor.Readings(ctx, nil)
+	require.NoError(t, err)
+	// cpufreq frequencies stay in kHz, devfreq frequencies are in Hz
+	assert.Equal(t, map[string]interface{}{
+		"cpu0":          int64(1500000),
+		"cpu0_min":      int64(600000),
+		"cpu0_max":      int64(1800000),
+		"cpu0_governor": "ondemand",
+		"cpu1":          int64(1200000),
+		"gpu0":          int64(300000000),
+	}, readings)
+}
+
+// expectedReadingKeys returns the keys every clock reports on its own, sorted. Readings has to contain exactly these,
+// so a clock that is missing or shadows another clock's key fails the test.
+func expectedReadingKeys(t *testing.T, clocks []sensors.ClockSensor) []string {
+	keys := make([]string, 0, len(clocks))
+	for _, clock := range clocks {
+		r, err := clock.GetReadingMap()
+		require.NoError(t, err)
+		keys = append(keys, readingKeys(r)...)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readingKeys(readings map[string]interface{}) []string {
+	keys := make([]string, 0, len(readings))
+	for k := range readings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,9 +4,9 @@
 package cpumanager
 
 import (
-	"errors"
-	"slices"
-	"strconv"
+	"context"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/cpufreq"
 )
 
 type ComponentConfig struct {
@@ -14,27 +14,22 @@ type ComponentConfig struct {
 	Frequency int    `json:"frequency"`
 	Minimum   int    `json:"minimum"`
 	Maximum   int    `json:"maximum"`
+	// Policies overrides the settings above for individual cpufreq policies, keyed by policy name, ex: policy4
+	Policies map[string]cpufreq.Settings `json:"policies,omitempty"`
 }
 
 func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
-	if conf.Governor != "" {
-		availableGovernors, err := getAvailableGovernors()
-		if err != nil {
-			return nil, nil, err
-		}
-		if !slices.Contains(availableGovernors, conf.Governor) {
-			return nil, nil, errors.New("unknown governor")
-		}
+	if err := cpufreq.ValidatePolicies(context.Background(), conf.settings(), conf.Policies); err != nil {
+		return nil, nil, err
 	}
+	return nil, nil, nil
+}
 
-	if conf.Frequency != 0 {
-		min, max, err := getFrequencyLimits()
-		if err != nil {
-			return nil, nil, err
-		}
-		if conf.Frequency > max || conf.Frequency < min {
-			return nil, nil, errors.New("frequency out of range. valid range: " + strconv.Itoa(min) + " - " + strconv.Itoa(max))
-		}
+func (conf *ComponentConfig) settings() cpufreq.Settings {
+	return cpufreq.Settings{
+		Governor:  conf.Governor,
+		Frequency: conf.Frequency,
+		Minimum:   conf.Minimum,
+		Maximum:   conf.Maximum,
 	}
-	return nil, nil, nil
 }
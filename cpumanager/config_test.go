@@ -0,0 +1,34 @@
+//go:build linux
+// +build linux
+
+package cpumanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/cpufreq"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+)
+
+func TestValidateWithoutCpufreq(t *testing.T) {
+	utilstest.UseFixtureFS(t, nil)
+
+	_, _, err := (&ComponentConfig{}).Validate("")
+	assert.NoError(t, err)
+
+	_, _, err = (&ComponentConfig{Governor: "performance"}).Validate("")
+	assert.ErrorIs(t, err, cpufreq.ErrNoCpufreq)
+	_, _, err = (&ComponentConfig{Policies: map[string]cpufreq.Settings{"policy4": {Governor: "performance"}}}).Validate("")
+	assert.Error(t, err)
+}
+
+func TestValidatePolicyLimits(t *testing.T) {
+	// Crossed limits are rejected before cpufreq is read
+	utilstest.UseFixtureFS(t, nil)
+
+	conf := &ComponentConfig{Minimum: 1800000, Policies: map[string]cpufreq.Settings{"policy0": {Maximum: 1200000}}}
+	_, _, err := conf.Validate("")
+	assert.ErrorContains(t, err, "minimum 1800000 is greater than maximum 1200000 on policy0")
+}
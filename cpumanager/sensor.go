@@ -5,8 +5,6 @@ package cpumanager
 
 import (
 	"context"
-	"os/exec"
-	"strconv"
 	"sync"
 
 	"github.com/rinzlerlabs/sbcidentify"
@@ -14,6 +12,7 @@ import (
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/cpufreq"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/powermanager"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 )
@@ -36,6 +35,9 @@ type Config struct {
 	Frequency  int
 	Minimum    int
 	Maximum    int
+	config     *ComponentConfig
+	history    *utils.ReadingsHistory
+	commands   *utils.CommandDispatcher
 }
 
 func init() {
@@ -56,7 +58,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -82,64 +86,72 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 		return utils.ErrBoardNotSupported
 	}
 
-	err = utils.InstallPackage("cpufrequtils")
-	if err != nil {
-		c.logger.Errorf("Error installing cpufrequtils: %s", err)
-		return err
-	}
-
+	c.config = newConf
 	c.Governor = newConf.Governor
 	c.Frequency = newConf.Frequency
 	c.Minimum = newConf.Minimum
 	c.Maximum = newConf.Maximum
 
-	args := make([]string, 0)
-	if c.Governor != "" {
-		args = append(args, "--governor", c.Governor)
-	}
-	if c.Frequency != 0 {
-		args = append(args, "--freq", strconv.Itoa(c.Frequency))
-	}
-	if c.Minimum != 0 {
-		args = append(args, "--min", strconv.Itoa(c.Minimum))
-	}
-	if c.Maximum != 0 {
-		args = append(args, "--max", strconv.Itoa(c.Maximum))
-	}
-
-	if len(args) > 0 {
-		proc := exec.Command("cpufreq-set", args...)
-
-		outputBytes, err := proc.Output()
-		if err != nil {
-			c.logger.Errorf("Error configuring CPU: %s", err)
-		}
-		c.logger.Infof("CPU configured: %s", string(outputBytes))
-	} else {
+	settings := newConf.settings()
+	if settings.IsEmpty() && len(newConf.Policies) == 0 {
 		c.logger.Info("No configuration changes made")
+		return nil
 	}
-
+	if err := cpufreq.ApplyPolicies(ctx, settings, newConf.Policies); err != nil {
+		c.logger.Errorf("Error configuring CPU: %s", err)
+		return err
+	}
+	c.logger.Infof("CPU configured: %+v", settings)
 	return nil
 }
 
 func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	min, max, governor, err := getCurrentPolicy()
+	min, max, governor, err := cpufreq.GetCurrentPolicy(ctx)
 	if err != nil {
 		return nil, err
 
 	}
-	currentFrequency, err := getCurrentFrequency()
+	currentFrequency, err := cpufreq.GetCurrentFrequency(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return map[string]interface{}{
+	ret := map[string]interface{}{
 		"current_frequency": currentFrequency,
 		"minimum_frequency": min,
 		"maximum_frequency": max,
 		"governor":          governor,
-	}, nil
+	}
+	infos, err := cpufreq.GetPolicyInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string]interface{}, len(infos))
+	for _, info := range infos {
+		policies[info.Name] = info.Map()
+	}
+	ret["policies"] = policies
+	c.history.Record(ret)
+	return ret, nil
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
 }
 
 func (c *Config) Close(ctx context.Context) error {
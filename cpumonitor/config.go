@@ -1,7 +1,9 @@
 package cpumonitor
 
 type ComponentConfig struct {
-	SleepTimeMs int `json:"sleep_time_ms"`
+	SleepTimeMs int  `json:"sleep_time_ms"`
+	Breakdown   bool `json:"breakdown,omitempty"`
+	Residency   bool `json:"residency,omitempty"`
 }
 
 func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
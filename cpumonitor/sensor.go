@@ -30,6 +30,10 @@ type Config struct {
 	sleepTime    time.Duration
 	workers      *viamutils.StoppableWorkers
 	reading      map[string]interface{}
+	config       *ComponentConfig
+	history      *utils.ReadingsHistory
+	commands     *utils.CommandDispatcher
+	refresh      *utils.Refresher
 }
 
 func init() {
@@ -46,7 +50,10 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		logger:       logger,
 		readingsLock: sync.RWMutex{},
 		configLock:   sync.Mutex{},
+		history:      utils.NewReadingsHistory(utils.DefaultHistorySize),
+		refresh:      utils.NewRefresher(),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -80,6 +87,7 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawCo
 		conf.SleepTimeMs = 1000 // Default to 1 second
 	}
 	c.sleepTime = time.Duration(conf.SleepTimeMs * int(time.Millisecond))
+	c.config = conf
 	c.workers = viamutils.NewBackgroundStoppableWorkers(c.startUpdating)
 
 	c.logger.Debugf("Reconfigure complete %s", PrettyName)
@@ -92,6 +100,22 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 	return c.reading, nil
 }
 
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.configLock.Lock()
+			defer c.configLock.Unlock()
+			return c.config
+		},
+		Refresh: c.refresh.Refresh(c.Readings),
+		History: c.history,
+	})
+}
+
 func (c *Config) Close(ctx context.Context) error {
 	c.configLock.Lock()
 	defer c.configLock.Unlock()
@@ -101,42 +125,90 @@ func (c *Config) Close(ctx context.Context) error {
 	return nil
 }
 
+// cpuSample is what the background worker diffs the next sample against
+type cpuSample struct {
+	stats     map[string]sensors.CPUCoreStats
+	residency map[string]sensors.CPUResidencyStats
+}
+
 // startUpdating is a goroutine that updates the CPU stats every sleepTime
 // It ensures if there are multiple readers of this sensor, it doesn't cause short samples
 func (c *Config) startUpdating(ctx context.Context) {
-	var err error
-	var lastStats map[string]sensors.CPUCoreStats
+	var last *cpuSample
 	for {
-		if lastStats == nil {
-			lastStats, err = sensors.ReadCPUStats()
-			if err != nil {
-				c.logger.Warnf("Failed to read CPU stats, skipping iteration: %v", err)
-				continue
-			}
+		if last == nil {
+			last = c.readSample(ctx)
 		}
 		select {
 		case <-ctx.Done():
 			return
+		case done := <-c.refresh.C():
+			last = c.updateReadings(ctx, last)
+			close(done)
 		case <-time.After(c.sleepTime):
-			currStats, err := sensors.ReadCPUStats()
-			if err != nil {
-				c.logger.Warnf("Failed to read CPU stats, skipping iteration: %v", err)
+			last = c.updateReadings(ctx, last)
+		}
+	}
+}
+
+// readSample reads the CPU stats and, if enabled, the frequency and idle residency counters
+func (c *Config) readSample(ctx context.Context) *cpuSample {
+	stats, err := sensors.ReadCPUStats()
+	if err != nil {
+		c.logger.Warnf("Failed to read CPU stats, skipping iteration: %v", err)
+		return nil
+	}
+	sample := &cpuSample{stats: stats}
+	if c.config != nil && c.config.Residency {
+		sample.residency, err = sensors.ReadCPUResidencyStats(ctx)
+		if err != nil {
+			c.logger.Warnf("Failed to read CPU residency stats: %v", err)
+		}
+	}
+	return sample
+}
+
+// updateReadings computes usage since last and returns the sample to diff against next time
+func (c *Config) updateReadings(ctx context.Context, last *cpuSample) *cpuSample {
+	curr := c.readSample(ctx)
+	if curr == nil {
+		return last
+	}
+	if last == nil {
+		return curr
+	}
+	ret := make(map[string]interface{})
+	var breakdown map[string]interface{}
+	if c.config != nil && c.config.Breakdown {
+		breakdown = make(map[string]interface{})
+		ret["breakdown"] = breakdown
+	}
+	for core, prev := range last.stats {
+		stats, ok := curr.stats[core]
+		if !ok {
+			c.logger.Warnf("Core %s not found in current stats", core)
+			continue
+		}
+		usage := sensors.CalculateUsage(prev, stats)
+		ret[core] = usage
+		if breakdown != nil {
+			breakdown[core] = sensors.CalculateBreakdown(prev, stats)
+		}
+	}
+	if curr.residency != nil {
+		residency := make(map[string]interface{})
+		for core, stats := range curr.residency {
+			prev, ok := last.residency[core]
+			if !ok {
 				continue
 			}
-			ret := make(map[string]interface{})
-			for core, prev := range lastStats {
-				curr, ok := currStats[core]
-				if !ok {
-					c.logger.Warnf("Core %s not found in current stats", core)
-					continue
-				}
-				usage := sensors.CalculateUsage(prev, curr)
-				ret[core] = usage
-			}
-			lastStats = currStats
-			c.readingsLock.Lock()
-			c.reading = ret
-			c.readingsLock.Unlock()
+			residency[core] = sensors.CalculateResidency(prev, stats)
 		}
+		ret["residency"] = residency
 	}
+	c.readingsLock.Lock()
+	c.reading = ret
+	c.readingsLock.Unlock()
+	c.history.Record(ret)
+	return curr
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
 	viamutils "go.viam.com/utils"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 )
 
 func TestCaptureCPUStats(t *testing.T) {
@@ -71,3 +73,32 @@ func TestCaptureCPUStatsRespectsSleepTime(t *testing.T) {
 	assert.True(t, testLength > 100*time.Millisecond)
 	assert.True(t, testLength < 200*time.Millisecond)
 }
+
+func TestDoCommandRefreshSamplesImmediately(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	sensor := &Config{
+		logger:    logger,
+		sleepTime: 1 * time.Hour,
+		config:    &ComponentConfig{SleepTimeMs: 3600000},
+		history:   utils.NewReadingsHistory(10),
+		refresh:   utils.NewRefresher(),
+	}
+	sensor.commands = sensor.newCommandDispatcher()
+	sensor.workers = viamutils.NewBackgroundStoppableWorkers(sensor.startUpdating)
+	defer sensor.Close(ctx)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	res, err := sensor.DoCommand(timeoutCtx, map[string]interface{}{utils.CommandKey: utils.RefreshCommand})
+	require.NoError(t, err)
+	assert.Len(t, res["readings"], runtime.NumCPU()+1)
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.HistoryCommand})
+	require.NoError(t, err)
+	assert.Len(t, res["samples"], 1)
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.GetConfigCommand})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"sleep_time_ms": 3600000.0}, res["config"])
+}
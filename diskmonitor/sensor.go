@@ -30,6 +30,9 @@ type Config struct {
 	cancelFunc        func()
 	disks             []*localDisk
 	includeIOCounters bool
+	config            *ComponentConfig
+	history           *utils.ReadingsHistory
+	commands          *utils.CommandDispatcher
 }
 
 func init() {
@@ -49,7 +52,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -72,6 +77,7 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 	}
 	c.disks = disks
 	c.includeIOCounters = newConf.IncludeIOCounters
+	c.config = newConf
 
 	// In case the module has changed name
 	c.Named = conf.ResourceName().AsNamed()
@@ -123,9 +129,28 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 		ret[name+"_used_percent"] = math.Round(usage.UsedPercent*100) / 100
 	}
 
+	c.history.Record(ret)
 	return ret, nil
 }
 
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
+}
+
 func (c *Config) Close(ctx context.Context) error {
 	c.logger.Infof("Shutting down %s", PrettyName)
 	return nil
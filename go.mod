@@ -11,6 +11,7 @@ require (
 	go.viam.com/rdk v0.132.0
 	go.viam.com/utils v0.6.5
 	golang.org/x/net v0.49.0
+	golang.org/x/sys v0.42.0
 )
 
 require (
@@ -200,7 +201,6 @@ require (
 	golang.org/x/mod v0.31.0 // indirect
 	golang.org/x/oauth2 v0.34.0 // indirect
 	golang.org/x/sync v0.19.0 // indirect
-	golang.org/x/sys v0.42.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
 	golang.org/x/time v0.10.0 // indirect
 	golang.org/x/tools v0.40.0 // indirect
@@ -26,6 +26,9 @@ type Config struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 	gpuMonitor gpuMonitor
+	config     *ComponentConfig
+	history    *utils.ReadingsHistory
+	commands   *utils.CommandDispatcher
 }
 
 func init() {
@@ -45,7 +48,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -65,10 +70,11 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 
 	c.cancelCtx, c.cancelFunc = context.WithCancel(context.Background())
 
-	_, err := resource.NativeConfig[*ComponentConfig](conf)
+	newConf, err := resource.NativeConfig[*ComponentConfig](conf)
 	if err != nil {
 		return err
 	}
+	c.config = newConf
 
 	// In case the module has changed name
 	c.Named = conf.ResourceName().AsNamed()
@@ -99,9 +105,28 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 		m[key] = stats
 	}
 
+	c.history.Record(m)
 	return m, nil
 }
 
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
+}
+
 func (c *Config) Close(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
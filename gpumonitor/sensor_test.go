@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
 )
@@ -35,3 +36,34 @@ func (m *mockGpuMonitor) GetGPUStats(context.Context) (map[string][]sensors.GPUS
 		},
 	}, nil
 }
+
+func TestDoCommand(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	sensor := &Config{
+		logger:     logger,
+		gpuMonitor: &mockGpuMonitor{},
+		config:     &ComponentConfig{},
+		history:    utils.NewReadingsHistory(10),
+	}
+	sensor.commands = sensor.newCommandDispatcher()
+
+	res, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.CapabilitiesCommand})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []interface{}{utils.CapabilitiesCommand, utils.GetConfigCommand, utils.HistoryCommand, utils.RefreshCommand}, res["commands"])
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.GetConfigCommand})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{}, res["config"])
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.RefreshCommand})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"gpu0": map[string]interface{}{"clocksGraphics": 1000.0}}, res["readings"])
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.HistoryCommand})
+	require.NoError(t, err)
+	require.Len(t, res["samples"], 1)
+
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: "set_fan_speed"})
+	require.ErrorIs(t, err, utils.ErrUnknownCommand)
+}
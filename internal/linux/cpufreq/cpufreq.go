@@ -0,0 +1,584 @@
+// Package cpufreq reads and writes the cpufreq policies in sysfs. All frequencies are in kHz, the unit the kernel uses.
+//
+// Heterogeneous SoCs like the RK3588 or Orin have a policy per cluster, each with its own frequency table. The
+// package level functions apply to every policy, Policy applies to a single cluster.
+package cpufreq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+const (
+	policyGlob = "/sys/devices/system/cpu/cpufreq/policy*"
+	// Kernels without policy directories still expose cpufreq per cpu
+	cpuGlob = "/sys/devices/system/cpu/cpu[0-9]*/cpufreq"
+
+	// UserspaceGovernor is the only governor that honours scaling_setspeed
+	UserspaceGovernor = "userspace"
+)
+
+var (
+	ErrNoCpufreq            = errors.New("cpufreq is not available")
+	ErrUnknownPolicy        = errors.New("unknown cpufreq policy")
+	ErrUnknownGovernor      = errors.New("unknown governor")
+	ErrFrequencyOutOfRange  = errors.New("frequency out of range")
+	ErrFrequencyUnavailable = errors.New("frequency is not available")
+)
+
+// Settings is a cpufreq configuration, zero values are left unchanged.
+type Settings struct {
+	Governor  string `json:"governor,omitempty"`
+	Frequency int    `json:"frequency,omitempty"`
+	Minimum   int    `json:"minimum,omitempty"`
+	Maximum   int    `json:"maximum,omitempty"`
+}
+
+func (s Settings) IsEmpty() bool {
+	return s == Settings{}
+}
+
+// Merge returns s with every non zero setting of override in its place. A governor without a frequency overrides a
+// pinned frequency too, since pinning switches to the userspace governor.
+func (s Settings) Merge(override Settings) Settings {
+	if override.Governor != "" {
+		s.Governor = override.Governor
+		s.Frequency = 0
+	}
+	if override.Frequency != 0 {
+		s.Frequency = override.Frequency
+	}
+	if override.Minimum != 0 {
+		s.Minimum = override.Minimum
+	}
+	if override.Maximum != 0 {
+		s.Maximum = override.Maximum
+	}
+	return s
+}
+
+// Policy is a cpufreq policy, the set of cpus that share a clock.
+type Policy struct {
+	// Name is the directory name, ex: policy0, or cpu0 on kernels without policy directories
+	Name string
+	Path string
+}
+
+// PolicyInfo is the state of a policy.
+type PolicyInfo struct {
+	Name                 string
+	RelatedCPUs          []int
+	Governor             string
+	AvailableGovernors   []string
+	CurrentFrequency     int
+	Minimum              int
+	Maximum              int
+	HardwareMinimum      int
+	HardwareMaximum      int
+	AvailableFrequencies []int
+}
+
+// Map returns the info in the shape used by readings. Lists are []interface{} since readings cannot hold typed
+// slices.
+func (i PolicyInfo) Map() map[string]interface{} {
+	ret := map[string]interface{}{
+		"related_cpus":               toInterfaces(i.RelatedCPUs),
+		"governor":                   i.Governor,
+		"available_governors":        toInterfaces(i.AvailableGovernors),
+		"current_frequency":          i.CurrentFrequency,
+		"minimum_frequency":          i.Minimum,
+		"maximum_frequency":          i.Maximum,
+		"hardware_minimum_frequency": i.HardwareMinimum,
+		"hardware_maximum_frequency": i.HardwareMaximum,
+	}
+	if i.AvailableFrequencies != nil {
+		ret["available_frequencies"] = toInterfaces(i.AvailableFrequencies)
+	}
+	return ret
+}
+
+func toInterfaces[T any](values []T) []interface{} {
+	ret := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// GetPolicies returns every policy, ordered by the number in the name.
+func GetPolicies() ([]Policy, error) {
+	paths, err := utils.Glob(policyGlob)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		paths, err = utils.Glob(cpuGlob)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoCpufreq
+	}
+	policies := make([]Policy, 0, len(paths))
+	for _, p := range paths {
+		name := path.Base(p)
+		if name == "cpufreq" {
+			name = path.Base(path.Dir(p))
+		}
+		policies = append(policies, Policy{Name: name, Path: p})
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policyNumber(policies[i].Name) < policyNumber(policies[j].Name)
+	})
+	return policies, nil
+}
+
+func policyNumber(name string) int {
+	n, _ := strconv.Atoi(strings.TrimLeft(name, "policycu"))
+	return n
+}
+
+// GetPolicy returns the policy called name, ex: policy4.
+func GetPolicy(name string) (Policy, error) {
+	policies, err := GetPolicies()
+	if err != nil {
+		return Policy{}, err
+	}
+	for _, policy := range policies {
+		if policy.Name == name {
+			return policy, nil
+		}
+	}
+	return Policy{}, fmt.Errorf("%w %s", ErrUnknownPolicy, name)
+}
+
+func firstPolicy() (Policy, error) {
+	policies, err := GetPolicies()
+	if err != nil {
+		return Policy{}, err
+	}
+	return policies[0], nil
+}
+
+func (p Policy) readString(ctx context.Context, attribute string) (string, error) {
+	return utils.ReadFileWithContext(ctx, path.Join(p.Path, attribute))
+}
+
+func (p Policy) readInt(ctx context.Context, attribute string) (int, error) {
+	value, err := utils.ReadInt64FromFileWithContext(ctx, path.Join(p.Path, attribute))
+	return int(value), err
+}
+
+func (p Policy) readInts(ctx context.Context, attribute string) ([]int, error) {
+	data, err := p.readString(ctx, attribute)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int, 0)
+	for _, field := range strings.Fields(data) {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (p Policy) write(attribute, value string) error {
+	f, err := utils.OpenFile(path.Join(p.Path, attribute), os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(value); err != nil {
+		return fmt.Errorf("failed to write %s to %s: %w", value, path.Join(p.Path, attribute), err)
+	}
+	return nil
+}
+
+// RelatedCPUs returns the cpus in the policy.
+func (p Policy) RelatedCPUs(ctx context.Context) ([]int, error) {
+	return p.readInts(ctx, "related_cpus")
+}
+
+func (p Policy) AvailableGovernors(ctx context.Context) ([]string, error) {
+	data, err := p.readString(ctx, "scaling_available_governors")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(data), nil
+}
+
+// AvailableFrequencies returns the frequency table of the policy. Drivers like intel_pstate do not publish one, in
+// which case nil is returned and any frequency within the hardware limits is accepted.
+func (p Policy) AvailableFrequencies(ctx context.Context) ([]int, error) {
+	frequencies, err := p.readInts(ctx, "scaling_available_frequencies")
+	if err != nil && os.IsNotExist(err) {
+		return nil, nil
+	}
+	return frequencies, err
+}
+
+// HardwareLimits returns cpuinfo_min_freq and cpuinfo_max_freq.
+func (p Policy) HardwareLimits(ctx context.Context) (minimum int, maximum int, err error) {
+	if minimum, err = p.readInt(ctx, "cpuinfo_min_freq"); err != nil {
+		return 0, 0, err
+	}
+	if maximum, err = p.readInt(ctx, "cpuinfo_max_freq"); err != nil {
+		return 0, 0, err
+	}
+	return minimum, maximum, nil
+}
+
+// Limits returns the scaling limits currently applied.
+func (p Policy) Limits(ctx context.Context) (minimum int, maximum int, err error) {
+	if minimum, err = p.readInt(ctx, "scaling_min_freq"); err != nil {
+		return 0, 0, err
+	}
+	if maximum, err = p.readInt(ctx, "scaling_max_freq"); err != nil {
+		return 0, 0, err
+	}
+	return minimum, maximum, nil
+}
+
+func (p Policy) Governor(ctx context.Context) (string, error) {
+	return p.readString(ctx, "scaling_governor")
+}
+
+func (p Policy) CurrentFrequency(ctx context.Context) (int, error) {
+	return p.readInt(ctx, "scaling_cur_freq")
+}
+
+// Info reads the full state of the policy.
+func (p Policy) Info(ctx context.Context) (PolicyInfo, error) {
+	var err error
+	info := PolicyInfo{Name: p.Name}
+	if info.RelatedCPUs, err = p.RelatedCPUs(ctx); err != nil {
+		// Per cpu directories on old kernels only describe themselves
+		cpu, convErr := strconv.Atoi(strings.TrimPrefix(p.Name, "cpu"))
+		if convErr != nil {
+			return PolicyInfo{}, err
+		}
+		info.RelatedCPUs = []int{cpu}
+	}
+	if info.Governor, err = p.Governor(ctx); err != nil {
+		return PolicyInfo{}, err
+	}
+	if info.AvailableGovernors, err = p.AvailableGovernors(ctx); err != nil {
+		return PolicyInfo{}, err
+	}
+	if info.CurrentFrequency, err = p.CurrentFrequency(ctx); err != nil {
+		return PolicyInfo{}, err
+	}
+	if info.Minimum, info.Maximum, err = p.Limits(ctx); err != nil {
+		return PolicyInfo{}, err
+	}
+	if info.HardwareMinimum, info.HardwareMaximum, err = p.HardwareLimits(ctx); err != nil {
+		return PolicyInfo{}, err
+	}
+	if info.AvailableFrequencies, err = p.AvailableFrequencies(ctx); err != nil {
+		return PolicyInfo{}, err
+	}
+	return info, nil
+}
+
+// ValidateGovernor returns ErrUnknownGovernor if the policy does not support governor.
+func (p Policy) ValidateGovernor(ctx context.Context, governor string) error {
+	governors, err := p.AvailableGovernors(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(governors, governor) {
+		return fmt.Errorf("%w %s on %s, available governors: %s", ErrUnknownGovernor, governor, p.Name, strings.Join(governors, ", "))
+	}
+	return nil
+}
+
+// ValidateLimit checks a scaling limit against the hardware limits. Limits do not have to be in the frequency
+// table, the kernel picks the closest frequency within them.
+func (p Policy) ValidateLimit(ctx context.Context, frequency int) error {
+	minimum, maximum, err := p.HardwareLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if frequency < minimum || frequency > maximum {
+		return fmt.Errorf("%w on %s, valid range: %d - %d", ErrFrequencyOutOfRange, p.Name, minimum, maximum)
+	}
+	return nil
+}
+
+// ValidateFrequency checks a frequency to pin against the hardware limits and the frequency table, if published.
+func (p Policy) ValidateFrequency(ctx context.Context, frequency int) error {
+	if err := p.ValidateLimit(ctx, frequency); err != nil {
+		return err
+	}
+	frequencies, err := p.AvailableFrequencies(ctx)
+	if err != nil {
+		return err
+	}
+	if frequencies != nil && !slices.Contains(frequencies, frequency) {
+		return fmt.Errorf("%w on %s: %d", ErrFrequencyUnavailable, p.Name, frequency)
+	}
+	return nil
+}
+
+// Validate checks every non zero setting against the policy without changing anything.
+func (p Policy) Validate(ctx context.Context, s Settings) error {
+	if s.Governor != "" {
+		if err := p.ValidateGovernor(ctx, s.Governor); err != nil {
+			return err
+		}
+	}
+	for _, limit := range []int{s.Minimum, s.Maximum} {
+		if limit == 0 {
+			continue
+		}
+		if err := p.ValidateLimit(ctx, limit); err != nil {
+			return err
+		}
+	}
+	if s.Minimum != 0 && s.Maximum != 0 && s.Minimum > s.Maximum {
+		return fmt.Errorf("minimum %d is greater than maximum %d", s.Minimum, s.Maximum)
+	}
+	if s.Frequency != 0 {
+		if err := p.ValidateFrequency(ctx, s.Frequency); err != nil {
+			return err
+		}
+		if err := p.ValidateGovernor(ctx, UserspaceGovernor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply validates settings and applies them to the policy. Pinning a frequency switches to the userspace
+// governor, so Frequency takes precedence over Governor.
+func (p Policy) Apply(ctx context.Context, s Settings) error {
+	if err := p.Validate(ctx, s); err != nil {
+		return err
+	}
+	return p.apply(ctx, s)
+}
+
+func (p Policy) apply(ctx context.Context, s Settings) error {
+	if s.Governor != "" {
+		if err := p.write("scaling_governor", s.Governor); err != nil {
+			return err
+		}
+	}
+	if s.Minimum != 0 || s.Maximum != 0 {
+		if err := p.setLimits(ctx, s.Minimum, s.Maximum); err != nil {
+			return err
+		}
+	}
+	if s.Frequency != 0 {
+		if err := p.setFrequency(ctx, s.Frequency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Policy) setFrequency(ctx context.Context, frequency int) error {
+	governor, err := p.Governor(ctx)
+	if err != nil {
+		return err
+	}
+	if governor != UserspaceGovernor {
+		if err := p.write("scaling_governor", UserspaceGovernor); err != nil {
+			return err
+		}
+	}
+	return p.write("scaling_setspeed", strconv.Itoa(frequency))
+}
+
+func (p Policy) setLimits(ctx context.Context, minimum int, maximum int) error {
+	_, currentMaximum, err := p.Limits(ctx)
+	if err != nil {
+		return err
+	}
+	// The kernel rejects a minimum above the current maximum, so raise the maximum first in that case
+	limits := []struct {
+		attribute string
+		value     int
+	}{{"scaling_min_freq", minimum}, {"scaling_max_freq", maximum}}
+	if minimum > currentMaximum {
+		slices.Reverse(limits)
+	}
+	for _, limit := range limits {
+		if limit.value == 0 {
+			continue
+		}
+		if err := p.write(limit.attribute, strconv.Itoa(limit.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAvailableGovernors returns the governors every policy supports.
+func GetAvailableGovernors(ctx context.Context) ([]string, error) {
+	policies, err := GetPolicies()
+	if err != nil {
+		return nil, err
+	}
+	var governors []string
+	for i, policy := range policies {
+		available, err := policy.AvailableGovernors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			governors = available
+			continue
+		}
+		governors = slices.DeleteFunc(governors, func(g string) bool { return !slices.Contains(available, g) })
+	}
+	return governors, nil
+}
+
+// GetAvailableFrequencies returns the frequency table of the first policy.
+func GetAvailableFrequencies(ctx context.Context) ([]int, error) {
+	policy, err := firstPolicy()
+	if err != nil {
+		return nil, err
+	}
+	return policy.AvailableFrequencies(ctx)
+}
+
+// GetFrequencyLimits returns the hardware limits of the first policy.
+func GetFrequencyLimits(ctx context.Context) (minimum int, maximum int, err error) {
+	policy, err := firstPolicy()
+	if err != nil {
+		return 0, 0, err
+	}
+	return policy.HardwareLimits(ctx)
+}
+
+// GetCurrentPolicy returns the limits and governor currently applied to the first policy.
+func GetCurrentPolicy(ctx context.Context) (minimum int, maximum int, governor string, err error) {
+	policy, err := firstPolicy()
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if minimum, maximum, err = policy.Limits(ctx); err != nil {
+		return 0, 0, "", err
+	}
+	if governor, err = policy.Governor(ctx); err != nil {
+		return 0, 0, "", err
+	}
+	return minimum, maximum, governor, nil
+}
+
+// GetCurrentFrequency returns the frequency of the first policy.
+func GetCurrentFrequency(ctx context.Context) (int, error) {
+	policy, err := firstPolicy()
+	if err != nil {
+		return 0, err
+	}
+	return policy.CurrentFrequency(ctx)
+}
+
+// GetPolicyInfos returns the state of every policy.
+func GetPolicyInfos(ctx context.Context) ([]PolicyInfo, error) {
+	policies, err := GetPolicies()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]PolicyInfo, 0, len(policies))
+	for _, policy := range policies {
+		info, err := policy.Info(ctx)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ValidateGovernor returns ErrUnknownGovernor if any policy does not support governor.
+func ValidateGovernor(ctx context.Context, governor string) error {
+	return Settings{Governor: governor}.Validate(ctx)
+}
+
+// ValidateFrequency checks frequency against every policy.
+func ValidateFrequency(ctx context.Context, frequency int) error {
+	return Settings{Frequency: frequency}.Validate(ctx)
+}
+
+// Validate checks every non zero setting against every policy without changing anything.
+func (s Settings) Validate(ctx context.Context) error {
+	return ValidatePolicies(ctx, s, nil)
+}
+
+// ValidatePolicies checks the settings each policy ends up with, the common settings merged with its entry of
+// policies, against that policy. Nothing to change is valid, even without cpufreq.
+func ValidatePolicies(ctx context.Context, settings Settings, policies map[string]Settings) error {
+	if settings.IsEmpty() && len(policies) == 0 {
+		return nil
+	}
+	// Crossed limits are a config error whatever the hardware
+	for name, override := range policies {
+		merged := settings.Merge(override)
+		if merged.Minimum != 0 && merged.Maximum != 0 && merged.Minimum > merged.Maximum {
+			return fmt.Errorf("minimum %d is greater than maximum %d on %s", merged.Minimum, merged.Maximum, name)
+		}
+	}
+	all, err := GetPolicies()
+	if err != nil {
+		return err
+	}
+	for name := range policies {
+		if _, err := GetPolicy(name); err != nil {
+			return err
+		}
+	}
+	for _, policy := range all {
+		merged := settings.Merge(policies[policy.Name])
+		if merged.IsEmpty() {
+			continue
+		}
+		if err := policy.Validate(ctx, merged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply validates settings and applies them to every policy.
+func Apply(ctx context.Context, settings Settings) error {
+	return ApplyPolicies(ctx, settings, nil)
+}
+
+// ApplyPolicies applies settings to every policy, merged with the entry of policies, keyed by policy name, for that
+// policy so a cluster can override the common settings. Everything is validated before anything is written.
+func ApplyPolicies(ctx context.Context, settings Settings, policies map[string]Settings) error {
+	if err := ValidatePolicies(ctx, settings, policies); err != nil {
+		return err
+	}
+	all, err := GetPolicies()
+	if err != nil {
+		return err
+	}
+	for _, policy := range all {
+		merged := settings.Merge(policies[policy.Name])
+		if merged.IsEmpty() {
+			continue
+		}
+		if err := policy.apply(ctx, merged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
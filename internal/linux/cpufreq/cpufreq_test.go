@@ -0,0 +1,207 @@
+package cpufreq
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useSysFs writes a two policy cpufreq tree, policy4 is a big core without the powersave governor
+func useSysFs(t *testing.T) string {
+	t.Helper()
+	policies := map[string]map[string]string{
+		"policy0": {
+			"scaling_available_governors":   "ondemand userspace powersave performance schedutil",
+			"scaling_available_frequencies": "408000 600000 816000 1008000 1200000 1416000",
+			"cpuinfo_min_freq":              "408000",
+			"cpuinfo_max_freq":              "1416000",
+			"scaling_min_freq":              "408000",
+			"scaling_max_freq":              "1416000",
+			"scaling_cur_freq":              "816000",
+			"scaling_governor":              "schedutil",
+			"scaling_setspeed":              "<unsupported>",
+			"related_cpus":                  "0 1 2 3",
+		},
+		"policy4": {
+			"scaling_available_governors":   "ondemand userspace performance schedutil",
+			"scaling_available_frequencies": "408000 1416000 1800000",
+			"cpuinfo_min_freq":              "408000",
+			"cpuinfo_max_freq":              "1800000",
+			"scaling_min_freq":              "408000",
+			"scaling_max_freq":              "600000",
+			"scaling_cur_freq":              "600000",
+			"scaling_governor":              "schedutil",
+			"scaling_setspeed":              "<unsupported>",
+			"related_cpus":                  "4 5",
+		},
+	}
+	fixture := make(map[string]string)
+	for policy, files := range policies {
+		for name, contents := range files {
+			fixture["/sys/devices/system/cpu/cpufreq/"+policy+"/"+name] = contents + "\n"
+		}
+	}
+	return utilstest.UseFixtureFS(t, fixture)
+}
+
+func readPolicy(t *testing.T, root, policy, attribute string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, "sys", "devices", "system", "cpu", "cpufreq", policy, attribute))
+	require.NoError(t, err)
+	return strings.TrimSpace(string(data))
+}
+
+func TestReadPolicy(t *testing.T) {
+	useSysFs(t)
+	ctx := context.Background()
+
+	governors, err := GetAvailableGovernors(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ondemand", "userspace", "performance", "schedutil"}, governors)
+
+	minimum, maximum, err := GetFrequencyLimits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 408000, minimum)
+	assert.Equal(t, 1416000, maximum)
+
+	minimum, maximum, governor, err := GetCurrentPolicy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 408000, minimum)
+	assert.Equal(t, 1416000, maximum)
+	assert.Equal(t, "schedutil", governor)
+
+	frequency, err := GetCurrentFrequency(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 816000, frequency)
+}
+
+func TestValidate(t *testing.T) {
+	useSysFs(t)
+	ctx := context.Background()
+
+	assert.NoError(t, Settings{Governor: "ondemand", Minimum: 600000, Maximum: 1200000}.Validate(ctx))
+	assert.ErrorIs(t, Settings{Governor: "powersave"}.Validate(ctx), ErrUnknownGovernor)
+	assert.ErrorIs(t, Settings{Frequency: 2000000}.Validate(ctx), ErrFrequencyOutOfRange)
+	assert.ErrorIs(t, Settings{Frequency: 700000}.Validate(ctx), ErrFrequencyUnavailable)
+	// 1800000 is only in the big cluster's table
+	assert.ErrorIs(t, Settings{Maximum: 1800000}.Validate(ctx), ErrFrequencyOutOfRange)
+	assert.NoError(t, ValidatePolicies(ctx, Settings{}, map[string]Settings{"policy4": {Maximum: 1800000}}))
+	assert.ErrorIs(t, ValidatePolicies(ctx, Settings{}, map[string]Settings{"policy8": {Governor: "ondemand"}}), ErrUnknownPolicy)
+	assert.Error(t, Settings{Minimum: 1200000, Maximum: 600000}.Validate(ctx))
+	// A policy's own limit is checked against the common one it does not override
+	assert.ErrorContains(t, ValidatePolicies(ctx, Settings{Minimum: 1200000}, map[string]Settings{"policy0": {Maximum: 600000}}), "policy0")
+	assert.ErrorContains(t, ValidatePolicies(ctx, Settings{Maximum: 1008000}, map[string]Settings{"policy4": {Minimum: 1416000}}), "policy4")
+	assert.NoError(t, ValidatePolicies(ctx, Settings{Minimum: 1200000, Maximum: 1416000}, map[string]Settings{"policy4": {Minimum: 408000, Maximum: 600000}}))
+}
+
+func TestApply(t *testing.T) {
+	root := useSysFs(t)
+	ctx := context.Background()
+
+	require.NoError(t, Apply(ctx, Settings{Governor: "performance", Minimum: 1008000}))
+	for _, policy := range []string{"policy0", "policy4"} {
+		assert.Equal(t, "performance", readPolicy(t, root, policy, "scaling_governor"))
+		assert.Equal(t, "1008000", readPolicy(t, root, policy, "scaling_min_freq"))
+	}
+	// Unset limits are left alone
+	assert.Equal(t, "600000", readPolicy(t, root, "policy4", "scaling_max_freq"))
+
+	// A pinned frequency has to be in every cluster's table
+	require.ErrorIs(t, Apply(ctx, Settings{Frequency: 1200000}), ErrFrequencyUnavailable)
+	require.NoError(t, Apply(ctx, Settings{Frequency: 1416000}))
+	for _, policy := range []string{"policy0", "policy4"} {
+		assert.Equal(t, UserspaceGovernor, readPolicy(t, root, policy, "scaling_governor"))
+		assert.Equal(t, "1416000", readPolicy(t, root, policy, "scaling_setspeed"))
+	}
+}
+
+func TestApplyRejectsInvalidSettingsWithoutWriting(t *testing.T) {
+	root := useSysFs(t)
+
+	err := Apply(context.Background(), Settings{Governor: "performance", Frequency: 700000})
+	require.ErrorIs(t, err, ErrFrequencyUnavailable)
+	assert.Equal(t, "schedutil", readPolicy(t, root, "policy0", "scaling_governor"))
+}
+
+func TestGetPoliciesFallsBackToCpus(t *testing.T) {
+	utilstest.UseFixtureFS(t, map[string]string{"/sys/devices/system/cpu/cpu0/cpufreq/": ""})
+
+	policies, err := GetPolicies()
+	require.NoError(t, err)
+	assert.Equal(t, []Policy{{Name: "cpu0", Path: "/sys/devices/system/cpu/cpu0/cpufreq"}}, policies)
+
+	utilstest.UseFixtureFS(t, nil)
+	_, err = GetPolicies()
+	assert.ErrorIs(t, err, ErrNoCpufreq)
+}
+
+func TestGetPolicyInfos(t *testing.T) {
+	useSysFs(t)
+
+	infos, err := GetPolicyInfos(context.Background())
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "policy0", infos[0].Name)
+	assert.Equal(t, []int{0, 1, 2, 3}, infos[0].RelatedCPUs)
+	assert.Equal(t, PolicyInfo{
+		Name:                 "policy4",
+		RelatedCPUs:          []int{4, 5},
+		Governor:             "schedutil",
+		AvailableGovernors:   []string{"ondemand", "userspace", "performance", "schedutil"},
+		CurrentFrequency:     600000,
+		Minimum:              408000,
+		Maximum:              600000,
+		HardwareMinimum:      408000,
+		HardwareMaximum:      1800000,
+		AvailableFrequencies: []int{408000, 1416000, 1800000},
+	}, infos[1])
+	assert.Equal(t, []interface{}{4, 5}, infos[1].Map()["related_cpus"])
+}
+
+func TestApplyPolicies(t *testing.T) {
+	root := useSysFs(t)
+
+	err := ApplyPolicies(context.Background(), Settings{Governor: "ondemand"}, map[string]Settings{
+		"policy4": {Governor: "performance", Maximum: 1800000},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ondemand", readPolicy(t, root, "policy0", "scaling_governor"))
+	assert.Equal(t, "1416000", readPolicy(t, root, "policy0", "scaling_max_freq"))
+	assert.Equal(t, "performance", readPolicy(t, root, "policy4", "scaling_governor"))
+	assert.Equal(t, "1800000", readPolicy(t, root, "policy4", "scaling_max_freq"))
+
+	policy, err := GetPolicy("policy0")
+	require.NoError(t, err)
+	require.ErrorIs(t, policy.Apply(context.Background(), Settings{Maximum: 1800000}), ErrFrequencyOutOfRange)
+	assert.Equal(t, "1416000", readPolicy(t, root, "policy0", "scaling_max_freq"))
+}
+
+func TestApplyPoliciesMergesOverrides(t *testing.T) {
+	root := useSysFs(t)
+	ctx := context.Background()
+
+	// 1800000 is only valid on the big cluster, the little one overrides it
+	require.ErrorIs(t, ApplyPolicies(ctx, Settings{Maximum: 1800000}, nil), ErrFrequencyOutOfRange)
+	err := ApplyPolicies(ctx, Settings{Governor: "ondemand", Maximum: 1800000}, map[string]Settings{
+		"policy0": {Maximum: 1416000},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ondemand", readPolicy(t, root, "policy0", "scaling_governor"))
+	assert.Equal(t, "1416000", readPolicy(t, root, "policy0", "scaling_max_freq"))
+	assert.Equal(t, "ondemand", readPolicy(t, root, "policy4", "scaling_governor"))
+	assert.Equal(t, "1800000", readPolicy(t, root, "policy4", "scaling_max_freq"))
+}
+
+func TestSettingsMerge(t *testing.T) {
+	common := Settings{Governor: "ondemand", Minimum: 408000, Maximum: 1800000}
+	assert.Equal(t, Settings{Governor: "ondemand", Minimum: 408000, Maximum: 1416000}, common.Merge(Settings{Maximum: 1416000}))
+	assert.Equal(t, common, common.Merge(Settings{}))
+	// A policy choosing its own governor is not pinned to the common frequency
+	assert.Equal(t, Settings{Governor: "performance"}, Settings{Frequency: 1416000}.Merge(Settings{Governor: "performance"}))
+}
@@ -2,33 +2,34 @@ package jetson
 
 import (
 	"context"
-	"runtime"
-	"strconv"
 	"testing"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
 )
 
 func TestGetNvidiaClockSensorsReturnsAllSensors(t *testing.T) {
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
 	logger := logging.NewTestLogger(t)
 	ctx := context.Background()
 	clocks, err := GetClockSensors(ctx, logger)
-	assert.NoError(t, err)
-	assert.NotNil(t, clocks)
-	assert.Equal(t, runtime.NumCPU()+1, len(clocks))
-	requiredKeys := []string{"gpu0"}
-	for i := 0; i < runtime.NumCPU(); i++ {
-		requiredKeys = append(requiredKeys, "cpu"+strconv.Itoa(i))
+	require.NoError(t, err)
+	names := make([]string, 0, len(clocks))
+	for _, clock := range clocks {
+		names = append(names, clock.Name())
+		defer clock.Close()
 	}
-	for i, clock := range clocks {
-		t.Logf("Clock %d: %v", i, clock.Name())
-		for j, key := range requiredKeys {
-			if clock.Name() == key {
-				requiredKeys = append(requiredKeys[:j], requiredKeys[j+1:]...)
-				break
-			}
-		}
-	}
-	assert.Empty(t, requiredKeys)
+	assert.Equal(t, []string{"cpu0", "cpu1", "gpu0"}, names)
+
+	readings, err := clocks[2].GetReadingMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"gpu0":          int64(306000000),
+		"gpu0_min":      int64(306000000),
+		"gpu0_max":      int64(624750000),
+		"gpu0_governor": "nvhost_podgov",
+	}, readings)
 }
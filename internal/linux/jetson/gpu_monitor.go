@@ -101,9 +101,9 @@ func (s *jetsonGpuSensor) GetSensorReading(ctx context.Context) (*sensors.GPUSen
 }
 
 func getJetsonGpuSensors() ([]jetsonGpuSensor, error) {
-	if _, err := os.Stat(jetpack5Sensors[0].currentValuePath); !os.IsNotExist(err) {
+	if _, err := utils.Stat(jetpack5Sensors[0].currentValuePath); !os.IsNotExist(err) {
 		return jetpack5Sensors, nil
-	} else if _, err := os.Stat(jetpack6Sensors[0].currentValuePath); !os.IsNotExist(err) {
+	} else if _, err := utils.Stat(jetpack6Sensors[0].currentValuePath); !os.IsNotExist(err) {
 		return jetpack6Sensors, nil
 	}
 
@@ -64,7 +64,7 @@ func (s *jetsonPowerSensor) GetReadingMap() (map[string]interface{}, error) {
 	ret := make(map[string]interface{})
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	current, voltage, power, err := s.GetReading()
+	voltage, current, power, err := s.GetReading()
 	if err != nil {
 		return nil, err
 	}
@@ -92,8 +92,8 @@ func (s *jetsonPowerSensor) GetReadingMap() (map[string]interface{}, error) {
 	return ret, nil
 }
 
-func newJetsonPowerSensor(ctx context.Context, logger logging.Logger, index int) (*jetsonPowerSensor, error) {
-	name, err := utils.ReadFileWithContext(ctx, fmt.Sprintf("/sys/bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon1/in%v_label", index))
+func newJetsonPowerSensor(ctx context.Context, logger logging.Logger, dir string, index int) (*jetsonPowerSensor, error) {
+	name, err := utils.ReadFileWithContext(ctx, filepath.Join(dir, fmt.Sprintf("in%v_label", index)))
 	if err != nil {
 		return nil, err
 	}
@@ -108,16 +108,16 @@ func newJetsonPowerSensor(ctx context.Context, logger logging.Logger, index int)
 		name:                         name,
 		cancelCtx:                    ctx,
 		cancelFunc:                   cancel,
-		overCurrentAlarmFile:         fmt.Sprintf("/sys/bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon1/curr%v_alarm", index),
-		criticalOverCurrentAlarmFile: fmt.Sprintf("/sys/bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon1/curr%v_crit_alarm", index),
-		voltageFile:                  fmt.Sprintf("/sys/bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon1/in%v_input", index),
-		currentFile:                  fmt.Sprintf("/sys/bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon1/curr%v_input", index),
+		overCurrentAlarmFile:         filepath.Join(dir, fmt.Sprintf("curr%v_alarm", index)),
+		criticalOverCurrentAlarmFile: filepath.Join(dir, fmt.Sprintf("curr%v_crit_alarm", index)),
+		voltageFile:                  filepath.Join(dir, fmt.Sprintf("in%v_input", index)),
+		currentFile:                  filepath.Join(dir, fmt.Sprintf("curr%v_input", index)),
 	}, nil
 }
 
 func GetPowerSensors(ctx context.Context, logger logging.Logger) ([]sensors.PowerSensor, error) {
 	sensors := make([]sensors.PowerSensor, 0)
-	matches, err := filepath.Glob("/sys/bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon*/in*_label")
+	matches, err := utils.Glob("/sys/bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon*/in*_label")
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +128,7 @@ func GetPowerSensors(ctx context.Context, logger logging.Logger) ([]sensors.Powe
 		if err != nil {
 			return nil, err
 		}
-		sensor, err := newJetsonPowerSensor(ctx, logger, index)
+		sensor, err := newJetsonPowerSensor(ctx, logger, filepath.Dir(match), index)
 		if err == ErrIgnoredSensor {
 			logger.Debugf("Ignoring sensor %s", index)
 			continue
@@ -1,21 +1,21 @@
 package jetson
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"go.viam.com/rdk/logging"
 )
 
 type PowerManagerConfig struct {
-	PowerMode int    `json:"power_mode"`
-	Governor  string `json:"governor"`
-	Frequency int    `json:"frequency"`
-	Minimum   int    `json:"minimum"`
-	Maximum   int    `json:"maximum"`
+	PowerMode int `json:"power_mode"`
+	// LinuxConfig holds the cpufreq settings, which are applied after the power mode
+	linux.LinuxConfig `json:",squash"`
 }
 
 type jetsonPowerManager struct {
@@ -33,43 +33,45 @@ func NewPowerManager(config *PowerManagerConfig, logger logging.Logger) (*jetson
 	}, nil
 }
 
-func (pm *jetsonPowerManager) ApplyPowerMode() (rebootRequired bool, err error) {
-	currentPowerMode, err := pm.GetCurrentPowerMode()
+func (pm *jetsonPowerManager) ApplyPowerMode(ctx context.Context) (rebootRequired bool, err error) {
+	currentPowerMode, err := pm.GetCurrentPowerMode(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to get current power mode: %v", err)
-	}
-	if currentPowerMode == nil {
-		return false, errors.New("current power mode is nil")
+		return false, err
 	}
 	if currentPowerMode == pm.config.PowerMode {
 		pm.logger.Debugf("Power mode is already set to %d", pm.config.PowerMode)
-		return false, nil
+	} else {
+		// nvpmodel asks whether to reboot now, answer no and let the caller decide
+		cmd := utils.Command{Name: "nvpmodel", Args: []string{"-m", fmt.Sprintf("%d", pm.config.PowerMode)}, Stdin: strings.NewReader("no\n")}
+		if _, err := cmd.Run(ctx); err != nil {
+			return false, fmt.Errorf("failed to set power mode: %w", err)
+		}
+		rebootRequired = true
 	}
-	cmd := exec.Command("nvpmodel", "-m", fmt.Sprintf("%d", pm.config.PowerMode))
-	cmd.Stdin = strings.NewReader("no\n")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return false, fmt.Errorf("failed to set power mode: %v, output: %s", err, string(output))
+	if err := linux.ApplyCpufreq(ctx, &pm.config.LinuxConfig, pm.logger); err != nil {
+		return rebootRequired, err
 	}
-	return true, nil
+	return rebootRequired, nil
 }
 
-func (pm *jetsonPowerManager) GetCurrentPowerMode() (interface{}, error) {
-	cmd := exec.Command("nvpmodel", "-q")
-	output, err := cmd.CombinedOutput()
+// GetCurrentPowerMode returns the nvpmodel mode id as an int.
+func (pm *jetsonPowerManager) GetCurrentPowerMode(ctx context.Context) (interface{}, error) {
+	output, err := utils.RunCommand(ctx, "nvpmodel", "-q")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current power mode: %v, output: %s", err, string(output))
+		return nil, fmt.Errorf("failed to get current power mode: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return parsePowerModeOutput(strings.TrimSpace(string(output)))
 }
 
+// parsePowerModeOutput reads the mode id from nvpmodel -q, which is the last line after the mode name, ex:
+// NV Power Mode: MAXN
+// 0
 func parsePowerModeOutput(output string) (int, error) {
-	lines := strings.Split(output, "\n")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
 	if len(lines) < 2 {
 		return 0, errors.New("unexpected output format")
 	}
-	powerModeLine := lines[1]
-	powerMode := strings.TrimSpace(powerModeLine)
+	powerMode := strings.TrimSpace(lines[len(lines)-1])
 	powerModeInt, err := strconv.Atoi(powerMode)
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse power mode: %v", err)
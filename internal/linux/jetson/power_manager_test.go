@@ -1,12 +1,34 @@
 package jetson
 
 import (
+	"context"
 	"testing"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
 )
 
 func TestPowerModeOnlyAppliesOnce(t *testing.T) {
+	ctx := context.Background()
+	fake := utilstest.NewFakeCommandRunner().
+		OnOutput("NV Power Mode: 15W\n0\n", "nvpmodel", "-q").
+		OnOutput("NV Power Mode: 7W\n1\n", "nvpmodel", "-q").
+		OnOutput("", "nvpmodel", "-m", "1")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	pm, err := NewPowerManager(&PowerManagerConfig{PowerMode: 1}, logging.NewTestLogger(t))
+	require.NoError(t, err)
+
+	rebootRequired, err := pm.ApplyPowerMode(ctx)
+	require.NoError(t, err)
+	require.True(t, rebootRequired)
+
+	rebootRequired, err = pm.ApplyPowerMode(ctx)
+	require.NoError(t, err)
+	require.False(t, rebootRequired)
+	require.Len(t, fake.Calls(), 3)
 }
 
 func TestParsePowerModeOutput(t *testing.T) {
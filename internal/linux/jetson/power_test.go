@@ -7,6 +7,8 @@ import (
 
 	"github.com/rinzlerlabs/sbcidentify/boardtype"
 	. "github.com/rinzlerlabs/sbcidentify/test"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
@@ -29,3 +31,30 @@ func TestJetsonPowerSensors(t *testing.T) {
 		defer s.Close()
 	}
 }
+
+func TestJetsonPowerSensorsFromFixture(t *testing.T) {
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	res, err := GetPowerSensors(ctx, logger)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	defer res[0].Close()
+	defer res[1].Close()
+
+	assert.Equal(t, "VDD_IN", res[0].GetName())
+	readings, err := res[0].GetReadingMap()
+	require.NoError(t, err)
+	assert.Equal(t, 5.08, readings["voltage"])
+	assert.Equal(t, 1.2, readings["current"])
+	assert.InDelta(t, 6.096, readings["power"], 0.0001)
+	assert.Equal(t, false, readings["over_current_alarm"])
+	assert.Equal(t, true, readings["critical_over_current"])
+
+	assert.Equal(t, "VDD_CPU_GPU_CV", res[1].GetName())
+	readings, err = res[1].GetReadingMap()
+	require.NoError(t, err)
+	assert.Equal(t, 5.072, readings["voltage"])
+	assert.NotContains(t, readings, "over_current_alarm")
+	assert.NotContains(t, readings, "critical_over_current")
+}
@@ -0,0 +1,24 @@
+package jetson
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTemperaturesFromFixture(t *testing.T) {
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+
+	temps, err := GetTemperatures(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, temps.CPU)
+	require.NotNil(t, temps.GPU)
+	assert.Equal(t, 48.5, *temps.CPU)
+	assert.Equal(t, 47.0, *temps.GPU)
+	// cv0 has no temp file so it is skipped
+	assert.Equal(t, map[string]float64{"SOC0": 46.25, "TJ": 49.06}, temps.Extra)
+}
@@ -0,0 +1,12 @@
+package linux
+
+import (
+	"context"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"go.viam.com/rdk/logging"
+)
+
+func GetPowerSensors(ctx context.Context, logger logging.Logger) ([]sensors.PowerSensor, error) {
+	return sensors.GetHwmonPowerSensors(ctx, logger)
+}
@@ -1,29 +1,62 @@
 package linux
 
 import (
+	"context"
 	"errors"
 
-	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/cpufreq"
 	"go.viam.com/rdk/logging"
 )
 
 type LinuxConfig struct {
+	Governor  string `json:"governor"`
+	Frequency int    `json:"frequency"`
+	Minimum   int    `json:"minimum"`
+	Maximum   int    `json:"maximum"`
+	// Policies overrides the settings above for individual cpufreq policies, keyed by policy name, ex: policy4
+	Policies map[string]cpufreq.Settings `json:"policies,omitempty"`
 }
 
 type linuxPowerManager struct {
+	config *LinuxConfig
+	logger logging.Logger
 }
 
 func NewPowerManager(config *LinuxConfig, logger logging.Logger) (*linuxPowerManager, error) {
 	if config == nil {
 		return nil, errors.New("configuration cannot be nil")
 	}
-	return nil, utils.ErrPlatformNotSupported
+	return &linuxPowerManager{
+		config: config,
+		logger: logger,
+	}, nil
 }
 
-func (pm *linuxPowerManager) ApplyPowerMode() (rebootRequired bool, err error) {
-	return false, utils.ErrPlatformNotSupported
+func (pm *linuxPowerManager) ApplyPowerMode(ctx context.Context) (rebootRequired bool, err error) {
+	return false, ApplyCpufreq(ctx, pm.config, pm.logger)
 }
 
-func (pm *linuxPowerManager) GetCurrentPowerMode() (interface{}, error) {
-	return nil, utils.ErrPlatformNotSupported
+// ApplyCpufreq writes the settings of config to the cpufreq policies. It is shared by every board that manages its
+// CPU through cpufreq, and does nothing when config has no settings.
+func ApplyCpufreq(ctx context.Context, config *LinuxConfig, logger logging.Logger) error {
+	settings := cpufreq.Settings{
+		Governor:  config.Governor,
+		Frequency: config.Frequency,
+		Minimum:   config.Minimum,
+		Maximum:   config.Maximum,
+	}
+	if settings.IsEmpty() && len(config.Policies) == 0 {
+		logger.Info("No CPU frequency changes made")
+		return nil
+	}
+	if err := cpufreq.ApplyPolicies(ctx, settings, config.Policies); err != nil {
+		return err
+	}
+	logger.Infof("CPU configured: %+v", settings)
+	return nil
+}
+
+// GetCurrentPowerMode returns nil, generic Linux machines have no power modes beyond the cpufreq policy.
+func (pm *linuxPowerManager) GetCurrentPowerMode(_ context.Context) (interface{}, error) {
+	return nil, nil
 }
@@ -2,9 +2,6 @@ package raspberrypi
 
 import (
 	"context"
-	"errors"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -46,20 +43,16 @@ type raspberryPiClockSensor struct {
 	logger     logging.Logger
 	mu         sync.RWMutex
 	name       string
-	sensorType string
-	path       string
 	cancelCtx  context.Context
 	cancelFunc context.CancelFunc
 }
 
 func (s *raspberryPiClockSensor) readVcgencmdClock() (int64, error) {
-	cmd := exec.CommandContext(s.cancelCtx, "vcgencmd", "measure_clock", s.name)
-	output, err := cmd.Output()
+	outputStr, err := Vcgencmd().Query(s.cancelCtx, "measure_clock", s.name)
 	if err != nil {
 		s.logger.Errorw("failed to measure clock", "sensor", s.name, "error", err)
 		return 0, err
 	}
-	outputStr := string(output)
 	parts := strings.Split(outputStr, "=")
 	if len(parts) != 2 {
 		s.logger.Errorw("unexpected output format", "sensor", s.name, "output", outputStr)
@@ -74,16 +67,6 @@ func (s *raspberryPiClockSensor) readVcgencmdClock() (int64, error) {
 	return frequency, nil
 }
 
-func (s *raspberryPiClockSensor) readSysfsClock() (int64, error) {
-	current, err := sensors.GetSysFsClock(s.cancelCtx, s.path)
-	if err != nil {
-		s.logger.Errorw("failed to read sysfs clock", "sensor", s.name, "error", err)
-		return 0, err
-	}
-	s.logger.Debugw("measured clock frequency", "sensor", s.name, "current", current)
-	return current, nil
-}
-
 func (s *raspberryPiClockSensor) Close() error {
 	s.cancelFunc()
 	return nil
@@ -92,16 +75,7 @@ func (s *raspberryPiClockSensor) Close() error {
 func (s *raspberryPiClockSensor) GetReadingMap() (map[string]interface{}, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	var frequency int64
-	var err error
-	switch s.sensorType {
-	case "vcgencmd":
-		frequency, err = s.readVcgencmdClock()
-	case "sysfs":
-		frequency, err = s.readSysfsClock()
-	default:
-		return nil, errors.New("unknown sensor type")
-	}
+	frequency, err := s.readVcgencmdClock()
 	return map[string]interface{}{
 		s.name: frequency,
 	}, err
@@ -136,21 +110,6 @@ func newRaspberryPiVcgencmdSensor(ctx context.Context, logger logging.Logger, na
 		name:       name,
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
-		sensorType: "vcgencmd",
-	}
-}
-
-func newRaspberryPiSysFsSensor(ctx context.Context, logger logging.Logger, path string) *raspberryPiClockSensor {
-	cancelCtx, cancelFunc := context.WithCancel(ctx)
-	parts := strings.Split(path, "/")
-	sensorName := parts[len(parts)-1]
-	return &raspberryPiClockSensor{
-		logger:     logger,
-		cancelCtx:  cancelCtx,
-		cancelFunc: cancelFunc,
-		name:       sensorName,
-		sensorType: "sysfs",
-		path:       filepath.Join(path, "cpufreq/cpuinfo_cur_freq"),
 	}
 }
 
@@ -164,13 +123,9 @@ func GetClockSensors(ctx context.Context, logger logging.Logger) ([]sensors.Cloc
 		b, e := sbcidentify.GetBoardType()
 		logger.Warnf("No vcgencmd clock sensors found for %s %s", b, e)
 	}
-	sysFsCpus, err := sensors.GetSysFsCpuPaths()
+	cpus, err := sensors.GetCpufreqClockSensors(ctx, logger)
 	if err != nil {
 		return nil, err
 	}
-	for _, cpu := range sysFsCpus {
-		sensor := newRaspberryPiSysFsSensor(ctx, logger, cpu)
-		s = append(s, sensor)
-	}
-	return s, nil
+	return append(s, cpus...), nil
 }
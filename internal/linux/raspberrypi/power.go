@@ -3,7 +3,6 @@ package raspberrypi
 import (
 	"context"
 	"errors"
-	"os/exec"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,26 +12,29 @@ import (
 )
 
 type raspberryPiPowerSensor struct {
-	logger logging.Logger
-	mu     sync.RWMutex
-	name   string
+	logger     logging.Logger
+	mu         sync.RWMutex
+	name       string
+	cancelCtx  context.Context
+	cancelFunc context.CancelFunc
 }
 
 func (s *raspberryPiPowerSensor) Close() error {
+	s.cancelFunc()
 	return nil
 }
 
 func (s *raspberryPiPowerSensor) GetReading() (voltage, current, power float64, err error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	voltage, err = getRaspberryPiComponentVoltage(s.name)
+	voltage, err = getRaspberryPiComponentVoltage(s.cancelCtx, s.name)
 	return
 }
 
 func (s *raspberryPiPowerSensor) GetReadingMap() (map[string]interface{}, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	voltage, err := getRaspberryPiComponentVoltage(s.name)
+	voltage, err := getRaspberryPiComponentVoltage(s.cancelCtx, s.name)
 	return map[string]interface{}{
 		"voltage": voltage,
 	}, err
@@ -44,9 +46,12 @@ func (s *raspberryPiPowerSensor) GetName() string {
 
 func newRaspberryPiPowerSensor(ctx context.Context, logger logging.Logger, name string) (*raspberryPiPowerSensor, error) {
 	logger.Infof("Creating Raspberry Pi power sensor for %s", name)
+	cancelCtx, cancelFunc := context.WithCancel(ctx)
 	s := &raspberryPiPowerSensor{
-		logger: logger,
-		name:   name,
+		logger:     logger,
+		name:       name,
+		cancelCtx:  cancelCtx,
+		cancelFunc: cancelFunc,
 	}
 	return s, nil
 }
@@ -69,13 +74,11 @@ func GetPowerSensors(ctx context.Context, logger logging.Logger) ([]sensors.Powe
 	return sensors, nil
 }
 
-func getRaspberryPiComponentVoltage(component string) (Voltage float64, Err error) {
-	proc := exec.Command("vcgencmd", "measure_volts", component)
-	outputBytes, err := proc.Output()
+func getRaspberryPiComponentVoltage(ctx context.Context, component string) (Voltage float64, Err error) {
+	output, err := Vcgencmd().Query(ctx, "measure_volts", component)
 	if err != nil {
 		return 0, err
 	}
-	output := string(outputBytes)
 	return parseVcgencmdVoltage(output)
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/rinzlerlabs/sbcidentify/boardtype"
 	. "github.com/rinzlerlabs/sbcidentify/test"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
@@ -66,3 +67,22 @@ func waitForValues(t *testing.T, sensors []sensors.PowerSensor) {
 		}
 	}
 }
+
+func TestRaspberryPiPowerSensorsWithFakeVcgencmd(t *testing.T) {
+	fake := utilstest.NewFakeCommandRunner().
+		OnOutput("volt=0.8563V\n", "vcgencmd", "measure_volts", "core").
+		OnOutput("volt=1.1000V\n", "vcgencmd", "measure_volts", "sdram_c").
+		OnOutput("volt=1.1000V\n", "vcgencmd", "measure_volts", "sdram_i").
+		OnOutput("volt=1.1000V\n", "vcgencmd", "measure_volts", "sdram_p")
+	useFakeVcgencmd(t, fake)
+	logger := logging.NewTestLogger(t)
+	sensors, err := GetPowerSensors(context.Background(), logger)
+	require.NoError(t, err)
+	require.Len(t, sensors, 4)
+	defer sensors[0].Close()
+
+	m, err := sensors[0].GetReadingMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"voltage": 0.8563}, m)
+	assert.Equal(t, []string{"vcgencmd measure_volts core"}, fake.Calls())
+}
@@ -2,7 +2,6 @@ package raspberrypi
 
 import (
 	"context"
-	"os/exec"
 	"strconv"
 	"strings"
 
@@ -44,12 +43,11 @@ type VcgencmdSensor struct {
 }
 
 func (t *VcgencmdSensor) Read(ctx context.Context) (float64, error) {
-	proc := exec.CommandContext(ctx, "vcgencmd", "measure_temp", t.subcommand)
-	outputBytes, err := proc.Output()
+	output, err := Vcgencmd().Query(ctx, "measure_temp", t.subcommand)
 	if err != nil {
 		return 0, err
 	}
-	return parseTemperature(string(outputBytes))
+	return parseTemperature(output)
 }
 
 func (t *VcgencmdSensor) Name() string {
@@ -1,8 +1,10 @@
 package raspberrypi
 
 import (
+	"context"
 	"testing"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -12,3 +14,16 @@ func TestTemperatureParse(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 47.2, temp)
 }
+
+func TestGetTemperaturesWithFakeVcgencmd(t *testing.T) {
+	fake := utilstest.NewFakeCommandRunner().
+		OnOutput("temp=47.2'C\n", "vcgencmd", "measure_temp", "").
+		OnOutput("temp=51.3'C\n", "vcgencmd", "measure_temp", "pmic")
+	useFakeVcgencmd(t, fake)
+
+	temps, err := GetTemperatures(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, temps.CPU)
+	require.Equal(t, 47.2, *temps.CPU)
+	require.Equal(t, map[string]float64{"PMIC": 51.3}, temps.Extra)
+}
@@ -0,0 +1,202 @@
+package raspberrypi
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+const (
+	// DefaultVcgencmdCacheTTL is how long a vcgencmd result is reused, it is shorter than any sensible poll interval
+	// so components polling at the same rate share one invocation
+	DefaultVcgencmdCacheTTL = time.Second
+	// VcgencmdCacheTTLEnv overrides DefaultVcgencmdCacheTTL, ex: "500ms". A TTL of 0 disables the cache.
+	VcgencmdCacheTTLEnv = "VCGENCMD_CACHE_TTL"
+	// vcgencmdTimeout bounds an invocation, it does not use the deadline of any one caller since they all share it
+	vcgencmdTimeout = 5 * time.Second
+
+	VcgencmdStatsCommand = "vcgencmd_stats"
+)
+
+// VcgencmdStats are the counters reported by the vcgencmd service.
+type VcgencmdStats struct {
+	Requests      int64   `json:"requests"`
+	CacheHits     int64   `json:"cache_hits"`
+	Coalesced     int64   `json:"coalesced"`
+	Invocations   int64   `json:"invocations"`
+	Errors        int64   `json:"errors"`
+	AvgLatencyMs  float64 `json:"avg_latency_ms"`
+	MaxLatencyMs  float64 `json:"max_latency_ms"`
+	LastLatencyMs float64 `json:"last_latency_ms"`
+	CacheTTLMs    int64   `json:"cache_ttl_ms"`
+}
+
+type vcgencmdResult struct {
+	output string
+	at     time.Time
+}
+
+type vcgencmdCall struct {
+	done   chan struct{}
+	output string
+	err    error
+}
+
+// VcgencmdService runs vcgencmd for every component in the module. vcgencmd only takes one command per
+// invocation, so requests are batched by caching results for the TTL and by coalescing identical requests
+// that arrive while one is already running. Invocations are serialized since they share the VideoCore mailbox.
+type VcgencmdService struct {
+	mu       sync.Mutex
+	mailbox  sync.Mutex
+	ttl      time.Duration
+	cache    map[string]vcgencmdResult
+	inflight map[string]*vcgencmdCall
+
+	requests     int64
+	cacheHits    int64
+	coalesced    int64
+	invocations  int64
+	errors       int64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	lastLatency  time.Duration
+}
+
+func NewVcgencmdService(ttl time.Duration) *VcgencmdService {
+	return &VcgencmdService{
+		ttl:      ttl,
+		cache:    make(map[string]vcgencmdResult),
+		inflight: make(map[string]*vcgencmdCall),
+	}
+}
+
+var (
+	vcgencmdOnce    sync.Once
+	vcgencmdService *VcgencmdService
+)
+
+// Vcgencmd returns the module wide vcgencmd service.
+func Vcgencmd() *VcgencmdService {
+	vcgencmdOnce.Do(func() {
+		ttl := DefaultVcgencmdCacheTTL
+		if v, ok := os.LookupEnv(VcgencmdCacheTTLEnv); ok {
+			if parsed, err := time.ParseDuration(v); err == nil && parsed >= 0 {
+				ttl = parsed
+			}
+		}
+		vcgencmdService = NewVcgencmdService(ttl)
+	})
+	return vcgencmdService
+}
+
+// SetTTL changes how long results are cached, cached results are dropped.
+func (s *VcgencmdService) SetTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+	s.cache = make(map[string]vcgencmdResult)
+}
+
+// Query returns the output of vcgencmd with args, from the cache if it is fresh enough.
+func (s *VcgencmdService) Query(ctx context.Context, args ...string) (string, error) {
+	key := strings.Join(args, " ")
+	s.mu.Lock()
+	s.requests++
+	if result, ok := s.cache[key]; ok && time.Since(result.at) < s.ttl {
+		s.cacheHits++
+		s.mu.Unlock()
+		return result.output, nil
+	}
+	call, ok := s.inflight[key]
+	if ok {
+		s.coalesced++
+	} else {
+		call = &vcgencmdCall{done: make(chan struct{})}
+		s.inflight[key] = call
+		go s.run(ctx, key, call, args)
+	}
+	s.mu.Unlock()
+	// Each caller only gives up on its own deadline, the invocation keeps going for the others
+	select {
+	case <-call.done:
+		return call.output, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// run invokes vcgencmd for everyone waiting on call, detached from the caller that started it
+func (s *VcgencmdService) run(ctx context.Context, key string, call *vcgencmdCall, args []string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), vcgencmdTimeout)
+	defer cancel()
+	call.output, call.err = s.invoke(ctx, args)
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	if call.err == nil && s.ttl > 0 {
+		s.cache[key] = vcgencmdResult{output: call.output, at: time.Now()}
+	}
+	s.mu.Unlock()
+	close(call.done)
+}
+
+func (s *VcgencmdService) invoke(ctx context.Context, args []string) (string, error) {
+	s.mailbox.Lock()
+	defer s.mailbox.Unlock()
+	start := time.Now()
+	output, err := utils.RunCommand(ctx, "vcgencmd", args...)
+	latency := time.Since(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invocations++
+	s.totalLatency += latency
+	s.lastLatency = latency
+	if latency > s.maxLatency {
+		s.maxLatency = latency
+	}
+	if err != nil {
+		s.errors++
+	}
+	return string(output), err
+}
+
+func (s *VcgencmdService) Stats() VcgencmdStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := VcgencmdStats{
+		Requests:      s.requests,
+		CacheHits:     s.cacheHits,
+		Coalesced:     s.coalesced,
+		Invocations:   s.invocations,
+		Errors:        s.errors,
+		MaxLatencyMs:  milliseconds(s.maxLatency),
+		LastLatencyMs: milliseconds(s.lastLatency),
+		CacheTTLMs:    s.ttl.Milliseconds(),
+	}
+	if s.invocations > 0 {
+		stats.AvgLatencyMs = milliseconds(s.totalLatency / time.Duration(s.invocations))
+	}
+	return stats
+}
+
+func milliseconds(d time.Duration) float64 {
+	return utils.RoundValue(float64(d)/float64(time.Millisecond), 3)
+}
+
+type VcgencmdStatsRequest struct{}
+
+type VcgencmdStatsResponse struct {
+	Stats VcgencmdStats `json:"stats"`
+}
+
+// RegisterVcgencmdCommands adds the vcgencmd_stats command to a component that reads through vcgencmd.
+func RegisterVcgencmdCommands(d *utils.CommandDispatcher) {
+	d.Register(VcgencmdStatsCommand, utils.NewCommandHandler(func(ctx context.Context, req VcgencmdStatsRequest) (VcgencmdStatsResponse, error) {
+		return VcgencmdStatsResponse{Stats: Vcgencmd().Stats()}, nil
+	}))
+}
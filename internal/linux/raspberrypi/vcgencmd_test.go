@@ -0,0 +1,130 @@
+package raspberrypi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFakeVcgencmd routes vcgencmd through fake and clears anything the module wide service has cached
+func useFakeVcgencmd(t *testing.T, fake *utilstest.FakeCommandRunner) {
+	t.Helper()
+	previous := utils.SetCommandRunner(fake)
+	Vcgencmd().SetTTL(DefaultVcgencmdCacheTTL)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+}
+
+func TestVcgencmdServiceCachesResults(t *testing.T) {
+	ctx := context.Background()
+	fake := utilstest.NewFakeCommandRunner().
+		OnOutput("frequency(48)=1500000000\n", "vcgencmd", "measure_clock", "arm").
+		OnOutput("frequency(48)=600000000\n", "vcgencmd", "measure_clock", "arm")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	s := NewVcgencmdService(50 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		output, err := s.Query(ctx, "measure_clock", "arm")
+		require.NoError(t, err)
+		assert.Equal(t, "frequency(48)=1500000000\n", output)
+	}
+	assert.Len(t, fake.Calls(), 1)
+
+	time.Sleep(60 * time.Millisecond)
+	output, err := s.Query(ctx, "measure_clock", "arm")
+	require.NoError(t, err)
+	assert.Equal(t, "frequency(48)=600000000\n", output)
+
+	stats := s.Stats()
+	assert.Equal(t, int64(4), stats.Requests)
+	assert.Equal(t, int64(2), stats.CacheHits)
+	assert.Equal(t, int64(2), stats.Invocations)
+	assert.Equal(t, int64(50), stats.CacheTTLMs)
+}
+
+func TestVcgencmdServiceCoalescesConcurrentRequests(t *testing.T) {
+	ctx := context.Background()
+	fake := utilstest.NewFakeCommandRunner().
+		On(utilstest.FakeResponse{Output: "throttled=0x0\n", Delay: 50 * time.Millisecond}, "vcgencmd", "get_throttled")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	s := NewVcgencmdService(0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, err := s.Query(ctx, "get_throttled")
+			assert.NoError(t, err)
+			assert.Equal(t, "throttled=0x0\n", output)
+		}()
+	}
+	wg.Wait()
+
+	stats := s.Stats()
+	assert.Equal(t, int64(5), stats.Requests)
+	assert.Equal(t, stats.Invocations, int64(len(fake.Calls())))
+	assert.Equal(t, stats.Requests, stats.Invocations+stats.Coalesced)
+	assert.Less(t, stats.Invocations, int64(5))
+	assert.Greater(t, stats.MaxLatencyMs, 0.0)
+}
+
+func TestVcgencmdServiceCallerDeadlineOnlyFailsThatCaller(t *testing.T) {
+	fake := utilstest.NewFakeCommandRunner().
+		On(utilstest.FakeResponse{Output: "temp=48.3'C\n", Delay: 50 * time.Millisecond}, "vcgencmd", "measure_temp")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	s := NewVcgencmdService(0)
+
+	// The first caller starts the invocation and gives up before it finishes
+	impatient, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		_, err := s.Query(impatient, "measure_temp")
+		errs <- err
+	}()
+	require.Eventually(t, func() bool { return len(fake.Calls()) == 1 }, time.Second, time.Millisecond)
+
+	output, err := s.Query(context.Background(), "measure_temp")
+	require.NoError(t, err)
+	assert.Equal(t, "temp=48.3'C\n", output)
+	assert.ErrorIs(t, <-errs, context.DeadlineExceeded)
+	assert.Len(t, fake.Calls(), 1)
+	assert.Equal(t, int64(1), s.Stats().Coalesced)
+}
+
+func TestVcgencmdServiceDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	fake := utilstest.NewFakeCommandRunner().
+		On(utilstest.FakeResponse{ExitCode: 255, Stderr: "VCHI initialization failed"}, "vcgencmd", "measure_volts", "core").
+		OnOutput("volt=0.8563V\n", "vcgencmd", "measure_volts", "core")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	s := NewVcgencmdService(time.Minute)
+
+	_, err := s.Query(ctx, "measure_volts", "core")
+	require.Error(t, err)
+	output, err := s.Query(ctx, "measure_volts", "core")
+	require.NoError(t, err)
+	assert.Equal(t, "volt=0.8563V\n", output)
+	assert.Equal(t, int64(1), s.Stats().Errors)
+}
+
+func TestVcgencmdStatsCommand(t *testing.T) {
+	d := utils.NewCommandDispatcher(utils.CommonCommands{})
+	RegisterVcgencmdCommands(d)
+	res, err := d.DoCommand(context.Background(), map[string]interface{}{utils.CommandKey: VcgencmdStatsCommand})
+	require.NoError(t, err)
+	stats := res["stats"].(map[string]interface{})
+	assert.Contains(t, stats, "invocations")
+	assert.Contains(t, stats, "cache_hits")
+	assert.Contains(t, stats, "avg_latency_ms")
+}
@@ -7,6 +7,15 @@ import (
 )
 
 func GetTemperatures(ctx context.Context) (*sensors.SystemTemperatures, error) {
-	systemTemps := &sensors.SystemTemperatures{Extra: make(map[string]float64)}
-	return systemTemps, nil
+	temps, err := sensors.GetThermalZoneTemperatures(ctx, sensors.ThermalZoneName)
+	if err != nil {
+		return nil, err
+	}
+	if err := sensors.AddHwmonTemperatures(ctx, temps); err != nil {
+		return nil, err
+	}
+	if err := sensors.AddHwmonFans(ctx, temps); err != nil {
+		return nil, err
+	}
+	return temps, nil
 }
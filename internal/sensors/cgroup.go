@@ -0,0 +1,306 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+var (
+	ErrNoCgroupV2      = errors.New("process is not in a cgroup v2 hierarchy")
+	ErrCgroupNotFound  = errors.New("cgroup not found")
+	ErrInvalidCgroupFS = errors.New("invalid cgroup file")
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// Unlimited is what the cgroup limits are set to when the file says max
+const Unlimited int64 = -1
+
+// GetProcessCgroup returns the cgroup v2 path of a process, relative to /sys/fs/cgroup
+func GetProcessCgroup(ctx context.Context, pid int) (string, error) {
+	contents, err := utils.ReadFileWithContext(ctx, fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(contents, "\n") {
+		// The cgroup v2 entry is always 0::<path>
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", ErrNoCgroupV2
+}
+
+// ResolveCgroup turns a cgroup path or a systemd unit name, like viam-server.service, into a path relative to
+// /sys/fs/cgroup. Units are looked up in the slices systemd creates, like system.slice or user.slice/user-1000.slice.
+func ResolveCgroup(name string) (string, error) {
+	if strings.Contains(name, "/") {
+		cgroup := "/" + strings.TrimPrefix(name, "/")
+		if _, err := utils.Stat(filepath.Join(cgroupRoot, cgroup)); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrCgroupNotFound, name)
+		}
+		return cgroup, nil
+	}
+	for _, pattern := range []string{name, filepath.Join("*", name), filepath.Join("*", "*", name), filepath.Join("*", "*", "*", name)} {
+		matches, err := utils.Glob(filepath.Join(cgroupRoot, pattern))
+		if err != nil {
+			return "", err
+		}
+		if len(matches) > 0 {
+			return strings.TrimPrefix(matches[0], cgroupRoot), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrCgroupNotFound, name)
+}
+
+type CgroupCPUStats struct {
+	UsageUsec     uint64
+	UserUsec      uint64
+	SystemUsec    uint64
+	Periods       uint64
+	Throttled     uint64
+	ThrottledUsec uint64
+	Quota         int64 // Microseconds per period, Unlimited if there is no limit
+	Period        uint64
+}
+
+type CgroupMemoryStats struct {
+	Current uint64
+	Max     int64 // Bytes, Unlimited if there is no limit
+	OOM     uint64
+	OOMKill uint64
+}
+
+type CgroupIOStats struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadIOs    uint64
+	WriteIOs   uint64
+}
+
+type CgroupPidsStats struct {
+	Current uint64
+	Max     int64 // Unlimited if there is no limit
+}
+
+// CgroupStats holds the counters of a cgroup. Controllers that are not enabled for the cgroup are nil.
+type CgroupStats struct {
+	Timestamp time.Time
+	CPU       *CgroupCPUStats
+	Memory    *CgroupMemoryStats
+	IO        *CgroupIOStats
+	Pids      *CgroupPidsStats
+}
+
+// ReadCgroupStats reads cpu.stat, cpu.max, memory.current, memory.max, memory.events, io.stat, pids.current and
+// pids.max of a cgroup, relative to /sys/fs/cgroup
+func ReadCgroupStats(ctx context.Context, cgroup string) (*CgroupStats, error) {
+	dir := filepath.Join(cgroupRoot, cgroup)
+	if _, err := utils.Stat(dir); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCgroupNotFound, cgroup)
+	}
+	read := func(name string) (string, bool, error) {
+		contents, err := utils.ReadFileWithContext(ctx, filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimSpace(contents), true, nil
+	}
+
+	stats := &CgroupStats{Timestamp: time.Now()}
+	// cpu.stat is always there, the throttling counters only when the cpu controller is enabled
+	if contents, ok, err := read("cpu.stat"); err != nil {
+		return nil, err
+	} else if ok {
+		values, err := parseCgroupKeyedFile(contents)
+		if err != nil {
+			return nil, err
+		}
+		stats.CPU = &CgroupCPUStats{
+			UsageUsec:     values["usage_usec"],
+			UserUsec:      values["user_usec"],
+			SystemUsec:    values["system_usec"],
+			Periods:       values["nr_periods"],
+			Throttled:     values["nr_throttled"],
+			ThrottledUsec: values["throttled_usec"],
+			Quota:         Unlimited,
+		}
+		if contents, ok, err := read("cpu.max"); err != nil {
+			return nil, err
+		} else if ok {
+			quota, period, _ := strings.Cut(contents, " ")
+			if stats.CPU.Quota, err = parseCgroupLimit(quota); err != nil {
+				return nil, err
+			}
+			if stats.CPU.Period, err = strconv.ParseUint(period, 10, 64); err != nil {
+				return nil, fmt.Errorf("%w: cpu.max %q", ErrInvalidCgroupFS, contents)
+			}
+		}
+	}
+
+	if contents, ok, err := read("memory.current"); err != nil {
+		return nil, err
+	} else if ok {
+		stats.Memory = &CgroupMemoryStats{Max: Unlimited}
+		if stats.Memory.Current, err = strconv.ParseUint(contents, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: memory.current %q", ErrInvalidCgroupFS, contents)
+		}
+		if contents, ok, err := read("memory.max"); err != nil {
+			return nil, err
+		} else if ok {
+			if stats.Memory.Max, err = parseCgroupLimit(contents); err != nil {
+				return nil, err
+			}
+		}
+		if contents, ok, err := read("memory.events"); err != nil {
+			return nil, err
+		} else if ok {
+			values, err := parseCgroupKeyedFile(contents)
+			if err != nil {
+				return nil, err
+			}
+			stats.Memory.OOM = values["oom"]
+			stats.Memory.OOMKill = values["oom_kill"]
+		}
+	}
+
+	if contents, ok, err := read("io.stat"); err != nil {
+		return nil, err
+	} else if ok {
+		stats.IO = &CgroupIOStats{}
+		// One line per device, like 179:0 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=0 dios=0
+		for _, line := range strings.Split(contents, "\n") {
+			fields := strings.Fields(line)
+			for _, field := range fields[min(1, len(fields)):] {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					continue
+				}
+				v, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: io.stat %q", ErrInvalidCgroupFS, line)
+				}
+				switch key {
+				case "rbytes":
+					stats.IO.ReadBytes += v
+				case "wbytes":
+					stats.IO.WriteBytes += v
+				case "rios":
+					stats.IO.ReadIOs += v
+				case "wios":
+					stats.IO.WriteIOs += v
+				}
+			}
+		}
+	}
+
+	if contents, ok, err := read("pids.current"); err != nil {
+		return nil, err
+	} else if ok {
+		stats.Pids = &CgroupPidsStats{Max: Unlimited}
+		if stats.Pids.Current, err = strconv.ParseUint(contents, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: pids.current %q", ErrInvalidCgroupFS, contents)
+		}
+		if contents, ok, err := read("pids.max"); err != nil {
+			return nil, err
+		} else if ok {
+			if stats.Pids.Max, err = parseCgroupLimit(contents); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stats, nil
+}
+
+// parseCgroupKeyedFile parses the "key value" per line format of cpu.stat and memory.events
+func parseCgroupKeyedFile(contents string) (map[string]uint64, error) {
+	ret := make(map[string]uint64)
+	for _, line := range strings.Split(contents, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCgroupFS, line)
+		}
+		ret[key] = v
+	}
+	return ret, nil
+}
+
+func parseCgroupLimit(value string) (int64, error) {
+	if value == "max" {
+		return Unlimited, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: limit %q", ErrInvalidCgroupFS, value)
+	}
+	return limit, nil
+}
+
+// CalculateCgroupUsage reports usage between prev and curr, and how close it is to each limit. CPU usage is a
+// percentage of one core, so 200 is two cores. Limits that are not set are left out.
+func CalculateCgroupUsage(prev, curr *CgroupStats) map[string]interface{} {
+	ret := make(map[string]interface{})
+	seconds := curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	if curr.CPU != nil && prev.CPU != nil {
+		usage := 0.0
+		if seconds > 0 {
+			usage = float64(counterDelta(prev.CPU.UsageUsec, curr.CPU.UsageUsec)) / 1e6 / seconds * 100
+		}
+		ret["cpu_usage_percent"] = utils.RoundValue(usage, 2)
+		ret["cpu_throttled_usec"] = counterDelta(prev.CPU.ThrottledUsec, curr.CPU.ThrottledUsec)
+		ret["cpu_throttled_periods_percent"] = residencyPercentage(
+			counterDelta(prev.CPU.Throttled, curr.CPU.Throttled),
+			counterDelta(prev.CPU.Periods, curr.CPU.Periods))
+		if curr.CPU.Quota != Unlimited && curr.CPU.Period > 0 {
+			limit := float64(curr.CPU.Quota) / float64(curr.CPU.Period)
+			ret["cpu_limit"] = utils.RoundValue(limit, 2)
+			ret["cpu_limit_percent"] = utils.RoundValue(usage/limit, 2)
+		}
+	}
+	if curr.Memory != nil {
+		ret["memory_current"] = curr.Memory.Current
+		ret["memory_oom"] = curr.Memory.OOM
+		ret["memory_oom_kill"] = curr.Memory.OOMKill
+		if curr.Memory.Max != Unlimited {
+			ret["memory_max"] = curr.Memory.Max
+			ret["memory_limit_percent"] = limitPercentage(curr.Memory.Current, curr.Memory.Max)
+		}
+	}
+	if curr.IO != nil && prev.IO != nil {
+		ret["io_read_bytes_per_sec"] = perSecond(counterDelta(prev.IO.ReadBytes, curr.IO.ReadBytes), seconds)
+		ret["io_write_bytes_per_sec"] = perSecond(counterDelta(prev.IO.WriteBytes, curr.IO.WriteBytes), seconds)
+		ret["io_read_iops"] = perSecond(counterDelta(prev.IO.ReadIOs, curr.IO.ReadIOs), seconds)
+		ret["io_write_iops"] = perSecond(counterDelta(prev.IO.WriteIOs, curr.IO.WriteIOs), seconds)
+	}
+	if curr.Pids != nil {
+		ret["pids_current"] = curr.Pids.Current
+		if curr.Pids.Max != Unlimited {
+			ret["pids_max"] = curr.Pids.Max
+			ret["pids_limit_percent"] = limitPercentage(curr.Pids.Current, curr.Pids.Max)
+		}
+	}
+	return ret
+}
+
+func limitPercentage(current uint64, limit int64) float64 {
+	// A limit of 0 means nothing is allowed, anything is over it
+	if limit <= 0 {
+		return 100.0
+	}
+	return utils.RoundValue(float64(current)/float64(limit)*100, 2)
+}
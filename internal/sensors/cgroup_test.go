@@ -0,0 +1,113 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useCgroupFixture(t *testing.T) {
+	t.Helper()
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+}
+
+func TestGetProcessCgroup(t *testing.T) {
+	useCgroupFixture(t)
+
+	cgroup, err := GetProcessCgroup(context.Background(), 4242)
+	require.NoError(t, err)
+	assert.Equal(t, "/system.slice/viam-server.service", cgroup)
+}
+
+func TestResolveCgroup(t *testing.T) {
+	useCgroupFixture(t)
+
+	cgroup, err := ResolveCgroup("viam-server.service")
+	require.NoError(t, err)
+	assert.Equal(t, "/system.slice/viam-server.service", cgroup)
+
+	cgroup, err = ResolveCgroup("session-1.scope")
+	require.NoError(t, err)
+	assert.Equal(t, "/user.slice/user-1000.slice/session-1.scope", cgroup)
+
+	cgroup, err = ResolveCgroup("system.slice/viam-server.service")
+	require.NoError(t, err)
+	assert.Equal(t, "/system.slice/viam-server.service", cgroup)
+
+	_, err = ResolveCgroup("docker.service")
+	assert.ErrorIs(t, err, ErrCgroupNotFound)
+	_, err = ResolveCgroup("/system.slice/docker.service")
+	assert.ErrorIs(t, err, ErrCgroupNotFound)
+}
+
+func TestReadCgroupStats(t *testing.T) {
+	useCgroupFixture(t)
+
+	stats, err := ReadCgroupStats(context.Background(), "/system.slice/viam-server.service")
+	require.NoError(t, err)
+	assert.Equal(t, &CgroupCPUStats{
+		UsageUsec:     8123456,
+		UserUsec:      6000000,
+		SystemUsec:    2123456,
+		Periods:       1200,
+		Throttled:     30,
+		ThrottledUsec: 450000,
+		Quota:         200000,
+		Period:        100000,
+	}, stats.CPU)
+	assert.Equal(t, &CgroupMemoryStats{Current: 536870912, Max: 1073741824, OOM: 2, OOMKill: 1}, stats.Memory)
+	assert.Equal(t, &CgroupIOStats{ReadBytes: 2097152, WriteBytes: 2097152, ReadIOs: 272, WriteIOs: 512}, stats.IO)
+	assert.Equal(t, &CgroupPidsStats{Current: 42, Max: Unlimited}, stats.Pids)
+
+	// Only cpu.stat, no controllers enabled
+	stats, err = ReadCgroupStats(context.Background(), "/user.slice/user-1000.slice/session-1.scope")
+	require.NoError(t, err)
+	assert.Equal(t, &CgroupCPUStats{UsageUsec: 1000, UserUsec: 600, SystemUsec: 400, Quota: Unlimited}, stats.CPU)
+	assert.Nil(t, stats.Memory)
+	assert.Nil(t, stats.IO)
+	assert.Nil(t, stats.Pids)
+
+	_, err = ReadCgroupStats(context.Background(), "/system.slice/docker.service")
+	assert.ErrorIs(t, err, ErrCgroupNotFound)
+}
+
+func TestCalculateCgroupUsage(t *testing.T) {
+	start := time.Now()
+	prev := &CgroupStats{
+		Timestamp: start,
+		CPU:       &CgroupCPUStats{UsageUsec: 1000000, Periods: 100, Throttled: 10, ThrottledUsec: 5000, Quota: 50000, Period: 100000},
+		Memory:    &CgroupMemoryStats{Current: 100, Max: 400},
+		IO:        &CgroupIOStats{ReadBytes: 1000, WriteBytes: 2000},
+		Pids:      &CgroupPidsStats{Current: 5, Max: Unlimited},
+	}
+	curr := &CgroupStats{
+		Timestamp: start.Add(2 * time.Second),
+		CPU:       &CgroupCPUStats{UsageUsec: 1800000, Periods: 120, Throttled: 15, ThrottledUsec: 25000, Quota: 50000, Period: 100000},
+		Memory:    &CgroupMemoryStats{Current: 300, Max: 400, OOM: 1},
+		IO:        &CgroupIOStats{ReadBytes: 5000, WriteBytes: 2000, ReadIOs: 4, WriteIOs: 0},
+		Pids:      &CgroupPidsStats{Current: 7, Max: Unlimited},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"cpu_usage_percent":             40.0,
+		"cpu_throttled_usec":            uint64(20000),
+		"cpu_throttled_periods_percent": 25.0,
+		"cpu_limit":                     0.5,
+		"cpu_limit_percent":             80.0,
+		"memory_current":                uint64(300),
+		"memory_max":                    int64(400),
+		"memory_limit_percent":          75.0,
+		"memory_oom":                    uint64(1),
+		"memory_oom_kill":               uint64(0),
+		"io_read_bytes_per_sec":         2000.0,
+		"io_write_bytes_per_sec":        0.0,
+		"io_read_iops":                  2.0,
+		"io_write_iops":                 0.0,
+		"pids_current":                  uint64(7),
+	}, CalculateCgroupUsage(prev, curr))
+}
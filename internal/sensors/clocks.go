@@ -2,15 +2,31 @@ package sensors
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
-	"path/filepath"
+	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"go.viam.com/rdk/logging"
 )
 
+const devfreqGlob = "/sys/class/devfreq/*"
+
+// Devfreq device names, ex: 17000000.gpu or dmc, are matched against these to decide what the device is
+var devfreqDeviceTypes = []struct {
+	name    string
+	devices []string
+}{
+	{"gpu", []string{"gpu", "ga10b", "gv11b", "gp10b", "mali", "g3d"}},
+	{"memory", []string{"dmc", "emc", "ddr", "memory"}},
+	{"npu", []string{"npu", "dla"}},
+}
+
 type ClockSensor interface {
 	Close() error
 	GetReadingMap() (map[string]interface{}, error)
@@ -22,13 +38,13 @@ func GetSysFsClock(ctx context.Context, path string) (int64, error) {
 }
 
 func GetSysFsCpuPaths() ([]string, error) {
-	paths, err := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*")
+	paths, err := utils.Glob("/sys/devices/system/cpu/cpu[0-9]*")
 	if err != nil {
 		return nil, err
 	}
 	validPaths := make([]string, 0)
 	for _, path := range paths {
-		if _, err := os.Stat(path); os.IsNotExist(err) {
+		if _, err := utils.Stat(path); os.IsNotExist(err) {
 			continue
 		}
 		validPaths = append(validPaths, path)
@@ -37,6 +53,156 @@ func GetSysFsCpuPaths() ([]string, error) {
 	return validPaths, nil
 }
 
+// sysfsClockSensor reads a cpufreq policy or devfreq device. Frequencies are reported in the unit sysfs uses, kHz
+// for cpufreq and Hz for devfreq, as name, name_min and name_max along with name_governor.
+type sysfsClockSensor struct {
+	logger logging.Logger
+	mu     sync.RWMutex
+	name   string
+	// current is tried in order, cpuinfo_cur_freq is only readable by root so cpufreq falls back to scaling_cur_freq
+	current    []string
+	min        string
+	max        string
+	governor   string
+	cancelCtx  context.Context
+	cancelFunc context.CancelFunc
+}
+
+func (s *sysfsClockSensor) Close() error {
+	s.cancelFunc()
+	return nil
+}
+
+func (s *sysfsClockSensor) Name() string {
+	return s.name
+}
+
+func (s *sysfsClockSensor) GetReadingMap() (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var errs error
+	ret := make(map[string]interface{})
+	for _, p := range s.current {
+		current, err := GetSysFsClock(s.cancelCtx, p)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		ret[s.name] = current
+		break
+	}
+	if _, ok := ret[s.name]; !ok {
+		s.logger.Errorw("failed to read sysfs clock", "sensor", s.name, "error", errs)
+		return nil, errs
+	}
+	if min, err := GetSysFsClock(s.cancelCtx, s.min); err == nil {
+		ret[s.name+"_min"] = min
+	}
+	if max, err := GetSysFsClock(s.cancelCtx, s.max); err == nil {
+		ret[s.name+"_max"] = max
+	}
+	if governor, err := utils.ReadFileWithContext(s.cancelCtx, s.governor); err == nil {
+		ret[s.name+"_governor"] = governor
+	}
+	s.logger.Debugw("measured clock frequency", "sensor", s.name, "current", ret[s.name])
+	return ret, nil
+}
+
+// NewCpufreqClockSensor creates a clock sensor for a cpu directory, ex: /sys/devices/system/cpu/cpu0, named after
+// the directory. Frequencies are in kHz, like the cpuN readings of the Raspberry Pi and Jetson always were.
+func NewCpufreqClockSensor(ctx context.Context, logger logging.Logger, cpuPath string) ClockSensor {
+	name := path.Base(cpuPath)
+	cpufreq := path.Join(cpuPath, "cpufreq")
+	cancelCtx, cancelFunc := context.WithCancel(ctx)
+	return &sysfsClockSensor{
+		logger:     logger.Sublogger(name),
+		name:       name,
+		current:    []string{path.Join(cpufreq, "cpuinfo_cur_freq"), path.Join(cpufreq, "scaling_cur_freq")},
+		min:        path.Join(cpufreq, "scaling_min_freq"),
+		max:        path.Join(cpufreq, "scaling_max_freq"),
+		governor:   path.Join(cpufreq, "scaling_governor"),
+		cancelCtx:  cancelCtx,
+		cancelFunc: cancelFunc,
+	}
+}
+
+// GetCpufreqClockSensors returns a clock sensor for every cpu with a cpufreq directory.
+func GetCpufreqClockSensors(ctx context.Context, logger logging.Logger) ([]ClockSensor, error) {
+	cpus, err := GetSysFsCpuPaths()
+	if err != nil {
+		return nil, err
+	}
+	s := make([]ClockSensor, 0, len(cpus))
+	for _, cpu := range cpus {
+		if _, err := utils.Stat(path.Join(cpu, "cpufreq")); err != nil {
+			logger.Debugf("Skipping %s, no cpufreq", cpu)
+			continue
+		}
+		s = append(s, NewCpufreqClockSensor(ctx, logger, cpu))
+	}
+	return s, nil
+}
+
+// GetDevfreqClockSensors returns a clock sensor for every device under /sys/class/devfreq. GPU, memory controller
+// and NPU devices are named gpu, memory and npu followed by their index, anything else keeps its device name.
+func GetDevfreqClockSensors(ctx context.Context, logger logging.Logger) ([]ClockSensor, error) {
+	devices, err := utils.Glob(devfreqGlob)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	s := make([]ClockSensor, 0, len(devices))
+	for _, device := range devices {
+		if _, err := utils.Stat(path.Join(device, "cur_freq")); err != nil {
+			continue
+		}
+		name := devfreqDeviceType(path.Base(device))
+		if name == "" {
+			name = strings.ReplaceAll(path.Base(device), ":", "_")
+		} else {
+			name, counts[name] = fmt.Sprintf("%s%d", name, counts[name]), counts[name]+1
+		}
+		logger.Debugf("Found devfreq device %s: %s", name, device)
+		cancelCtx, cancelFunc := context.WithCancel(ctx)
+		s = append(s, &sysfsClockSensor{
+			logger:     logger.Sublogger(name),
+			name:       name,
+			current:    []string{path.Join(device, "cur_freq")},
+			min:        path.Join(device, "min_freq"),
+			max:        path.Join(device, "max_freq"),
+			governor:   path.Join(device, "governor"),
+			cancelCtx:  cancelCtx,
+			cancelFunc: cancelFunc,
+		})
+	}
+	return s, nil
+}
+
+// GetSysFsClockSensors returns the cpufreq clock of every cpu followed by the devfreq clocks.
+func GetSysFsClockSensors(ctx context.Context, logger logging.Logger) ([]ClockSensor, error) {
+	s, err := GetCpufreqClockSensors(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	devfreq, err := GetDevfreqClockSensors(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	return append(s, devfreq...), nil
+}
+
+func devfreqDeviceType(device string) string {
+	device = strings.ToLower(device)
+	for _, deviceType := range devfreqDeviceTypes {
+		for _, name := range deviceType.devices {
+			if strings.Contains(device, name) {
+				return deviceType.name
+			}
+		}
+	}
+	return ""
+}
+
 func readIntFromFile(ctx context.Context, path string) (int64, error) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
 	defer cancel()
@@ -0,0 +1,71 @@
+package sensors
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+)
+
+func TestGetSysFsCpuPaths(t *testing.T) {
+	utilstest.UseFixtureFS(t, map[string]string{
+		"/sys/devices/system/cpu/cpu0/cpufreq/":                 "",
+		"/sys/devices/system/cpu/cpufreq/cpufreq/":              "",
+		"/sys/devices/system/cpu/cpuidle/cpufreq/":              "",
+		"/sys/devices/system/cpu/cpu1/cpufreq/scaling_cur_freq": "1500000\n",
+	})
+
+	paths, err := GetSysFsCpuPaths()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/sys/devices/system/cpu/cpu0", "/sys/devices/system/cpu/cpu1"}, paths)
+
+	freq, err := GetSysFsClock(context.Background(), filepath.Join(paths[1], "cpufreq", "scaling_cur_freq"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500000), freq)
+}
+
+func TestGetSysFsClockSensors(t *testing.T) {
+	utilstest.UseFixtureFS(t, map[string]string{
+		"/sys/devices/system/cpu/cpu0/cpufreq/cpuinfo_cur_freq": "1800000\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_cur_freq": "1700000\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_min_freq": "408000\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_max_freq": "1800000\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor": "ondemand\n",
+		"/sys/devices/system/cpu/cpu1/cpufreq/scaling_cur_freq": "1200000\n",
+		"/sys/devices/system/cpu/cpu2/online":                   "1\n",
+		"/sys/class/devfreq/dmc/cur_freq":                       "528000000\n",
+		"/sys/class/devfreq/fb000000.gpu/cur_freq":              "300000000\n",
+		"/sys/class/devfreq/fb000000.gpu/governor":              "simple_ondemand\n",
+		"/sys/class/devfreq/fdab0000.npu/cur_freq":              "1000000000\n",
+		"/sys/class/devfreq/1c00000.isp/cur_freq":               "400000000\n",
+	})
+
+	clocks, err := GetSysFsClockSensors(context.Background(), logging.NewTestLogger(t))
+	require.NoError(t, err)
+	readings := make(map[string]interface{})
+	for _, clock := range clocks {
+		r, err := clock.GetReadingMap()
+		require.NoError(t, err)
+		for k, v := range r {
+			readings[k] = v
+		}
+		require.NoError(t, clock.Close())
+	}
+	// cpu2 has no cpufreq directory
+	assert.Equal(t, map[string]interface{}{
+		"cpu0":          int64(1800000),
+		"cpu0_min":      int64(408000),
+		"cpu0_max":      int64(1800000),
+		"cpu0_governor": "ondemand",
+		"cpu1":          int64(1200000),
+		"1c00000.isp":   int64(400000000),
+		"memory0":       int64(528000000),
+		"gpu0":          int64(300000000),
+		"gpu0_governor": "simple_ondemand",
+		"npu0":          int64(1000000000),
+	}, readings)
+}
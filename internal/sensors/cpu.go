@@ -3,9 +3,7 @@ package sensors
 import (
 	"context"
 	"errors"
-	"os"
-	"path/filepath"
-	"slices"
+	"fmt"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,23 +19,32 @@ var (
 	ErrProcessNotFound = errors.New("process not found")
 )
 
+// DefaultProcessResyncInterval is how often a ProcessMonitor that caches PIDs scans for new matching processes, a
+// cached process that exits or is replaced by another process with the same PID triggers a scan right away
+const DefaultProcessResyncInterval = 10 * time.Second
+
+// CPUCoreStats holds the cumulative time, in seconds, a core spent in each state.
+// As in /proc/stat, User includes Guest and Nice includes GuestNice.
 type CPUCoreStats struct {
-	User    uint64
-	Nice    uint64
-	System  uint64
-	Idle    uint64
-	IOWait  uint64
-	IRQ     uint64
-	SoftIRQ uint64
-	Steal   uint64
+	User      float64
+	Nice      float64
+	System    float64
+	Idle      float64
+	IOWait    float64
+	IRQ       float64
+	SoftIRQ   float64
+	Steal     float64
+	Guest     float64
+	GuestNice float64
 }
 
 type Process struct {
 	*process.Process
-	PID     int32
-	Name    string // Name of the process (for convenience)
-	exe     string // Path to the executable
-	cmdline string // Command line of the process
+	PID       int32
+	Name      string // Name of the process (for convenience)
+	exe       string // Path to the executable
+	cmdline   string // Command line of the process
+	startTime uint64 // When the process started, in clock ticks since boot, to tell it from a later process with the same PID
 }
 
 func (p *Process) Exe() (string, error) {
@@ -80,25 +87,29 @@ func ReadCPUStats() (map[string]CPUCoreStats, error) {
 	for _, stat := range rawStats {
 		// Add per-core stats
 		stats[stat.CPU] = CPUCoreStats{
-			User:    uint64(stat.User),
-			Nice:    uint64(stat.Nice),
-			System:  uint64(stat.System),
-			Idle:    uint64(stat.Idle),
-			IOWait:  uint64(stat.Iowait),
-			IRQ:     uint64(stat.Irq),
-			SoftIRQ: uint64(stat.Softirq),
-			Steal:   uint64(stat.Steal),
+			User:      stat.User,
+			Nice:      stat.Nice,
+			System:    stat.System,
+			Idle:      stat.Idle,
+			IOWait:    stat.Iowait,
+			IRQ:       stat.Irq,
+			SoftIRQ:   stat.Softirq,
+			Steal:     stat.Steal,
+			Guest:     stat.Guest,
+			GuestNice: stat.GuestNice,
 		}
 
 		// Add total stats
-		totalStats.User += uint64(stat.User)
-		totalStats.Nice += uint64(stat.Nice)
-		totalStats.System += uint64(stat.System)
-		totalStats.Idle += uint64(stat.Idle)
-		totalStats.IOWait += uint64(stat.Iowait)
-		totalStats.IRQ += uint64(stat.Irq)
-		totalStats.SoftIRQ += uint64(stat.Softirq)
-		totalStats.Steal += uint64(stat.Steal)
+		totalStats.User += stat.User
+		totalStats.Nice += stat.Nice
+		totalStats.System += stat.System
+		totalStats.Idle += stat.Idle
+		totalStats.IOWait += stat.Iowait
+		totalStats.IRQ += stat.Irq
+		totalStats.SoftIRQ += stat.Softirq
+		totalStats.Steal += stat.Steal
+		totalStats.Guest += stat.Guest
+		totalStats.GuestNice += stat.GuestNice
 	}
 
 	stats["cpu"] = totalStats
@@ -109,7 +120,9 @@ func ReadCPUStats() (map[string]CPUCoreStats, error) {
 type ProcessMonitor struct {
 	Processes         utils.OrderedMap[int32, *Process] // List of processes to monitor
 	lastSync          time.Time
+	resyncInterval    time.Duration
 	name              string
+	matcher           *processMatcher
 	disablePidCaching bool
 	logger            logging.Logger
 	mu                sync.Mutex
@@ -129,11 +142,46 @@ func CalculateUsage(prev, curr CPUCoreStats) float64 {
 	totalDelta := currTotal - prevTotal
 	idleDelta := currIdle - prevIdle
 
-	if totalDelta == 0 {
+	if totalDelta <= 0 {
 		return 0.0
 	}
 
-	return utils.RoundValue((float64(totalDelta-idleDelta)/float64(totalDelta))*100, 2)
+	return utils.RoundValue(((totalDelta-idleDelta)/totalDelta)*100, 2)
+}
+
+// CalculateBreakdown calculates the percentage of time spent in each state. Guest time is taken out of user and nice
+// so the categories add up to 100.
+func CalculateBreakdown(prev, curr CPUCoreStats) map[string]interface{} {
+	deltas := map[string]float64{
+		"user":       (curr.User - curr.Guest) - (prev.User - prev.Guest),
+		"nice":       (curr.Nice - curr.GuestNice) - (prev.Nice - prev.GuestNice),
+		"system":     curr.System - prev.System,
+		"idle":       curr.Idle - prev.Idle,
+		"iowait":     curr.IOWait - prev.IOWait,
+		"irq":        curr.IRQ - prev.IRQ,
+		"softirq":    curr.SoftIRQ - prev.SoftIRQ,
+		"steal":      curr.Steal - prev.Steal,
+		"guest":      curr.Guest - prev.Guest,
+		"guest_nice": curr.GuestNice - prev.GuestNice,
+	}
+	total := 0.0
+	for state, d := range deltas {
+		// iowait can go backwards on some kernels
+		if d < 0 {
+			deltas[state] = 0
+			d = 0
+		}
+		total += d
+	}
+	ret := make(map[string]interface{}, len(deltas))
+	for state, d := range deltas {
+		if total <= 0 {
+			ret[state] = 0.0
+			continue
+		}
+		ret[state] = utils.RoundValue(d/total*100, 2)
+	}
+	return ret
 }
 
 func NewProcessMonitor(logger logging.Logger, name string, disablePidCaching bool) *ProcessMonitor {
@@ -141,28 +189,42 @@ func NewProcessMonitor(logger logging.Logger, name string, disablePidCaching boo
 	pm := &ProcessMonitor{
 		Processes:         utils.NewOrderedMap[int32, *Process](),
 		name:              name,
+		matcher:           &processMatcher{selector: &ProcessSelector{Name: name}},
+		resyncInterval:    DefaultProcessResyncInterval,
 		disablePidCaching: disablePidCaching,
 		logger:            logger,
 	}
 	return pm
 }
 
+// NewProcessMonitorWithSelector returns a process monitor for every process the selector matches
+func NewProcessMonitorWithSelector(logger logging.Logger, selector *ProcessSelector, disablePidCaching bool) (*ProcessMonitor, error) {
+	matcher, err := selector.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &ProcessMonitor{
+		Processes:         utils.NewOrderedMap[int32, *Process](),
+		name:              selector.String(),
+		matcher:           matcher,
+		resyncInterval:    DefaultProcessResyncInterval,
+		disablePidCaching: disablePidCaching,
+		logger:            logger,
+	}, nil
+}
+
 func (p *ProcessMonitor) GetProcessesWithContext(ctx context.Context) (utils.OrderedMap[int32, *Process], error) {
 	p.mu.Lock()
 	defer p.mu.Unlock() // Ensure the mutex is unlocked after the function completes
 	if p.Processes.Len() > 0 {
-		if !p.disablePidCaching {
-			if p.lastSync.Add(10 * time.Second).Before(time.Now()) {
-				p.logger.Debugf("Returning %d cached processes, last sync: %v, current time: %v", p.Processes.Len(), p.lastSync, time.Now())
-				return p.Processes, nil // Return cached processes if within the sync interval
-			} else {
-				// If within the sync interval and pid caching is enabled, return cached processes
-				p.logger.Debugf("Have %d cached processes, but sync interval elapsed: %v", p.Processes.Len(), p.lastSync.Add(10*time.Second))
-				return p.Processes, nil
-			}
-		} else {
+		if p.disablePidCaching {
 			// If pid caching is disabled, always perform a sync
 			p.logger.Debugf("Have %d cached processes, but pid caching is disabled, performing a sync", p.Processes.Len())
+		} else if reason, stale := p.cacheIsStale(time.Now()); stale {
+			p.logger.Debugf("%s, performing a sync", reason)
+		} else {
+			p.logger.Debugf("Returning %d cached processes, last sync: %v, current time: %v", p.Processes.Len(), p.lastSync, time.Now())
+			return p.Processes, nil
 		}
 	} else {
 		p.logger.Debugf("No cached processes found for %s, performing a sync", p.name)
@@ -170,13 +232,6 @@ func (p *ProcessMonitor) GetProcessesWithContext(ctx context.Context) (utils.Ord
 
 	p.logger.Debugf("Syncing processes for %s, last sync: %v, current time: %v, disablePidCaching: %v", p.name, p.lastSync, time.Now(), p.disablePidCaching)
 
-	for _, pid := range slices.Collect(p.Processes.Keys()) {
-		if ret, err := process.PidExistsWithContext(ctx, int32(pid)); err != nil || !ret {
-			// Remove the process from the map if it no longer exists
-			p.Processes.Delete(pid) // Keep the process in the list if it still exists
-		}
-	}
-
 	process.EnableBootTimeCache(true)
 	ret := utils.NewOrderedMap[int32, *Process]()
 	procs, err := process.ProcessesWithContext(ctx)
@@ -184,40 +239,20 @@ func (p *ProcessMonitor) GetProcessesWithContext(ctx context.Context) (utils.Ord
 		p.logger.Debugf("Failed to get processes: %v", err)
 		return nil, errors.Join(errors.New("failed to get processes"), err)
 	}
+	table := make(procTable)
 	for _, proc := range procs {
-		// The linux kernel seems to limit the contents of /proc/<pid>/comm to 15 bytes,
-		// if the process name is longer than that we need to fall back to /proc/<pid>/cmdline
-		if len(p.name) <= 15 {
-			procName, err := getProcName(proc) // Get the process name
-			if err != nil {
-				p.logger.Debugf("Failed to get process name for PID %d: %v", proc.Pid, err)
-				continue
-			}
-			if procName == p.name {
-				p.logger.Debugf("Found process %s with PID %d", procName, proc.Pid)
-				ret.Set(proc.Pid, &Process{Process: proc, PID: proc.Pid, Name: procName}) // Store the process in the ordered map
-				continue
-			}
-		} else {
-			cmdline, err := getProcCmdline(proc)
-			if err != nil {
-				p.logger.Debugf("Failed to get process cmdline for PID %d: %v", proc.Pid, err)
-				continue
-			}
-			if cmdline == "" {
-				continue
-			}
-			if filepath.Base(cmdline) == p.name {
-				p.logger.Debugf("Found process %s with PID %d", filepath.Base(cmdline), proc.Pid)
-				ret.Set(proc.Pid, &Process{Process: proc, PID: proc.Pid, Name: filepath.Base(cmdline)}) // Store the process in the ordered map
-				continue
-			}
-			if cmdline == p.name {
-				p.logger.Debugf("Found process %s with PID %d", cmdline, proc.Pid)
-				ret.Set(proc.Pid, &Process{Process: proc, PID: proc.Pid, Name: cmdline}) // Store the process in the ordered map
-				continue
-			}
+		attributes := table.get(proc.Pid)
+		if !p.matcher.matches(attributes) {
+			continue
 		}
+		name := attributes.name()
+		p.logger.Debugf("Found process %s with PID %d", name, proc.Pid)
+		startTime, err := ReadProcessStartTime(proc.Pid)
+		if err != nil {
+			// The process exited since it was listed
+			continue
+		}
+		ret.Set(proc.Pid, &Process{Process: proc, PID: proc.Pid, Name: name, startTime: startTime}) // Store the process in the ordered map
 	}
 	p.Processes = ret
 	p.lastSync = time.Now() // Update the last sync time
@@ -225,26 +260,36 @@ func (p *ProcessMonitor) GetProcessesWithContext(ctx context.Context) (utils.Ord
 	return ret, nil
 }
 
-func getProcName(proc *process.Process) (string, error) {
-	pid := proc.Pid
-	statPath := filepath.Join("/proc", strconv.Itoa(int(pid)), "comm")
-	contents, err := os.ReadFile(statPath)
-	if err != nil {
-		return "", err
+// cacheIsStale returns why the cached processes need a sync: one of them exited or its PID now belongs to another
+// process, or the resync interval passed so processes that started since the last sync are picked up
+func (p *ProcessMonitor) cacheIsStale(now time.Time) (string, bool) {
+	for pid, proc := range p.Processes.AllFromFront() {
+		startTime, err := ReadProcessStartTime(pid)
+		if err != nil {
+			return fmt.Sprintf("Cached process %d exited", pid), true
+		}
+		if startTime != proc.startTime {
+			return fmt.Sprintf("Cached process %d was replaced by a new process with the same PID", pid), true
+		}
+	}
+	if now.Sub(p.lastSync) >= p.resyncInterval {
+		return "Resync interval passed", true
 	}
-	return strings.TrimSuffix(string(contents), "\n"), nil
+	return "", false
 }
 
-func getProcCmdline(proc *process.Process) (string, error) {
-	cmdlinePath := filepath.Join("/proc", strconv.Itoa(int(proc.Pid)), "cmdline")
-	data, err := os.ReadFile(cmdlinePath)
+// ReadProcessStartTime reads when a process started, in clock ticks since boot, from /proc/<pid>/stat. Unlike the
+// create time gopsutil caches on a Process, it is read fresh every time.
+func ReadProcessStartTime(pid int32) (uint64, error) {
+	data, err := utils.ReadFile(procPath(pid, "stat"))
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-	// The cmdline is null-separated, so split it and return the first argument
-	args := strings.Split(string(data), "\x00")
-	if len(args) > 0 {
-		return args[0], nil
+	contents := string(data)
+	// The fields after the name start at state, the 3rd field, starttime is the 22nd
+	fields := strings.Fields(contents[strings.LastIndex(contents, ")")+1:])
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("invalid stat %q", contents)
 	}
-	return "", errors.New("cmdline is empty")
+	return strconv.ParseUint(fields[19], 10, 64)
 }
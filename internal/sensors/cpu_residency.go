@@ -0,0 +1,145 @@
+package sensors
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// CPUIdleStateStats holds the cumulative counters of a single cpuidle state
+type CPUIdleStateStats struct {
+	Name  string
+	Time  uint64 // Time spent in the state, in microseconds
+	Usage uint64 // Number of times the state was entered
+}
+
+// CPUResidencyStats holds the cumulative cpufreq and cpuidle counters of a core
+type CPUResidencyStats struct {
+	Timestamp   time.Time
+	TimeInState map[int64]uint64 // Frequency in kHz to time spent there, in 10ms units
+	TotalTrans  uint64
+	IdleStates  []CPUIdleStateStats
+}
+
+// ReadCPUResidencyStats reads cpufreq/stats and cpuidle for every core, keyed by core name (cpu0, cpu1, ...).
+// Cores without either are left out, the kernel only has them when CONFIG_CPU_FREQ_STAT and CONFIG_CPU_IDLE are set.
+func ReadCPUResidencyStats(ctx context.Context) (map[string]CPUResidencyStats, error) {
+	paths, err := GetSysFsCpuPaths()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]CPUResidencyStats)
+	for _, path := range paths {
+		stats := CPUResidencyStats{Timestamp: time.Now()}
+		stats.TimeInState, stats.TotalTrans = readTimeInState(ctx, filepath.Join(path, "cpufreq", "stats"))
+		stats.IdleStates = readIdleStates(ctx, filepath.Join(path, "cpuidle"))
+		if stats.TimeInState == nil && stats.IdleStates == nil {
+			continue
+		}
+		ret[filepath.Base(path)] = stats
+	}
+	return ret, nil
+}
+
+func readTimeInState(ctx context.Context, path string) (map[int64]uint64, uint64) {
+	contents, err := utils.ReadFileWithContext(ctx, filepath.Join(path, "time_in_state"))
+	if err != nil {
+		return nil, 0
+	}
+	timeInState := make(map[int64]uint64)
+	for _, line := range strings.Split(contents, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		frequency, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		t, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		timeInState[frequency] = t
+	}
+	// total_trans is missing on some drivers, it is only reported when present
+	totalTrans, _ := readIntFromFile(ctx, filepath.Join(path, "total_trans"))
+	return timeInState, uint64(totalTrans)
+}
+
+func readIdleStates(ctx context.Context, path string) []CPUIdleStateStats {
+	statePaths, err := utils.Glob(filepath.Join(path, "state[0-9]*"))
+	if err != nil || len(statePaths) == 0 {
+		return nil
+	}
+	states := make([]CPUIdleStateStats, 0, len(statePaths))
+	for _, statePath := range statePaths {
+		name, err := utils.ReadFileWithContext(ctx, filepath.Join(statePath, "name"))
+		if err != nil {
+			continue
+		}
+		t, err := readIntFromFile(ctx, filepath.Join(statePath, "time"))
+		if err != nil {
+			continue
+		}
+		usage, err := readIntFromFile(ctx, filepath.Join(statePath, "usage"))
+		if err != nil {
+			continue
+		}
+		states = append(states, CPUIdleStateStats{Name: strings.TrimSpace(name), Time: uint64(t), Usage: uint64(usage)})
+	}
+	return states
+}
+
+// CalculateResidency reports the share of the interval between prev and curr spent at each frequency and in each idle state.
+// Frequency residency is a percentage of the time cpufreq accounted for, idle residency is a percentage of wall time.
+func CalculateResidency(prev, curr CPUResidencyStats) map[string]interface{} {
+	ret := make(map[string]interface{})
+	if curr.TimeInState != nil {
+		var total uint64
+		for frequency, t := range curr.TimeInState {
+			total += counterDelta(prev.TimeInState[frequency], t)
+		}
+		frequencies := make(map[string]interface{})
+		for frequency, t := range curr.TimeInState {
+			frequencies[strconv.FormatInt(frequency, 10)] = residencyPercentage(counterDelta(prev.TimeInState[frequency], t), total)
+		}
+		ret["frequency"] = frequencies
+		ret["transitions"] = counterDelta(prev.TotalTrans, curr.TotalTrans)
+	}
+	if curr.IdleStates != nil {
+		elapsed := uint64(curr.Timestamp.Sub(prev.Timestamp).Microseconds())
+		previous := make(map[string]CPUIdleStateStats)
+		for _, state := range prev.IdleStates {
+			previous[state.Name] = state
+		}
+		idle := make(map[string]interface{})
+		entries := make(map[string]interface{})
+		for _, state := range curr.IdleStates {
+			idle[state.Name] = residencyPercentage(counterDelta(previous[state.Name].Time, state.Time), elapsed)
+			entries[state.Name] = counterDelta(previous[state.Name].Usage, state.Usage)
+		}
+		ret["idle"] = idle
+		ret["idle_entries"] = entries
+	}
+	return ret
+}
+
+// counterDelta returns curr-prev, or 0 if the counter went backwards (e.g. a core was hotplugged)
+func counterDelta(prev, curr uint64) uint64 {
+	if curr < prev {
+		return 0
+	}
+	return curr - prev
+}
+
+func residencyPercentage(part, total uint64) float64 {
+	if total == 0 {
+		return 0.0
+	}
+	return utils.RoundValue(float64(part)/float64(total)*100, 2)
+}
@@ -0,0 +1,63 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCPUResidencyStats(t *testing.T) {
+	utilstest.UseFixtureFS(t, map[string]string{
+		"/sys/devices/system/cpu/cpu0/cpufreq/stats/time_in_state": "408000 100\n816000 50\n1416000 10\n",
+		"/sys/devices/system/cpu/cpu0/cpufreq/stats/total_trans":   "42\n",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state0/name":         "WFI\n",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state0/time":         "1000\n",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state0/usage":        "10\n",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state1/name":         "cpu-sleep\n",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state1/time":         "5000\n",
+		"/sys/devices/system/cpu/cpu0/cpuidle/state1/usage":        "3\n",
+		"/sys/devices/system/cpu/cpu1/online":                      "1\n",
+	})
+
+	stats, err := ReadCPUResidencyStats(context.Background())
+	require.NoError(t, err)
+	// cpu1 has neither cpufreq stats nor cpuidle
+	require.Len(t, stats, 1)
+	cpu0 := stats["cpu0"]
+	assert.Equal(t, map[int64]uint64{408000: 100, 816000: 50, 1416000: 10}, cpu0.TimeInState)
+	assert.Equal(t, uint64(42), cpu0.TotalTrans)
+	assert.Equal(t, []CPUIdleStateStats{
+		{Name: "WFI", Time: 1000, Usage: 10},
+		{Name: "cpu-sleep", Time: 5000, Usage: 3},
+	}, cpu0.IdleStates)
+}
+
+func TestCalculateResidency(t *testing.T) {
+	start := time.Now()
+	prev := CPUResidencyStats{
+		Timestamp:   start,
+		TimeInState: map[int64]uint64{408000: 100, 816000: 50, 1416000: 10},
+		TotalTrans:  42,
+		IdleStates:  []CPUIdleStateStats{{Name: "WFI", Time: 1000, Usage: 10}, {Name: "cpu-sleep", Time: 5000, Usage: 3}},
+	}
+	curr := CPUResidencyStats{
+		Timestamp:   start.Add(time.Second),
+		TimeInState: map[int64]uint64{408000: 175, 816000: 50, 1416000: 35},
+		TotalTrans:  46,
+		IdleStates:  []CPUIdleStateStats{{Name: "WFI", Time: 251000, Usage: 110}, {Name: "cpu-sleep", Time: 505000, Usage: 8}},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"frequency":    map[string]interface{}{"408000": 75.0, "816000": 0.0, "1416000": 25.0},
+		"transitions":  uint64(4),
+		"idle":         map[string]interface{}{"WFI": 25.0, "cpu-sleep": 50.0},
+		"idle_entries": map[string]interface{}{"WFI": uint64(100), "cpu-sleep": uint64(5)},
+	}, CalculateResidency(prev, curr))
+
+	// Only what the kernel reported is included
+	assert.Equal(t, map[string]interface{}{}, CalculateResidency(CPUResidencyStats{}, CPUResidencyStats{}))
+}
@@ -5,6 +5,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
 )
 
 func TestGetProcCmdlineParseing(t *testing.T) {
@@ -18,3 +24,55 @@ func TestGetProcCmdlineParseing(t *testing.T) {
 	}
 	t.FailNow()
 }
+
+func TestCalculateBreakdown(t *testing.T) {
+	prev := CPUCoreStats{User: 100, Nice: 10, System: 20, Idle: 500, IOWait: 5, Guest: 4}
+	curr := CPUCoreStats{User: 103, Nice: 10, System: 21, Idle: 503, IOWait: 7, IRQ: 0.5, SoftIRQ: 0.5, Guest: 5}
+
+	breakdown := CalculateBreakdown(prev, curr)
+	assert.Equal(t, map[string]interface{}{
+		"user":       20.0,
+		"nice":       0.0,
+		"system":     10.0,
+		"idle":       30.0,
+		"iowait":     20.0,
+		"irq":        5.0,
+		"softirq":    5.0,
+		"steal":      0.0,
+		"guest":      10.0,
+		"guest_nice": 0.0,
+	}, breakdown)
+	assert.Equal(t, 50.0, CalculateUsage(prev, curr))
+}
+
+func TestProcessMonitorCacheIsStale(t *testing.T) {
+	stat := "42 (perception) S 1 42 42 0 -1 4194560 1000 0 0 0 10 5 0 0 20 0 2 0 %d 123456789 2000\n"
+	root := utilstest.UseFixtureFS(t, map[string]string{"/proc/42/stat": fmt.Sprintf(stat, 5000)})
+	startTime, err := ReadProcessStartTime(42)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5000), startTime)
+
+	synced := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := NewProcessMonitor(logging.NewTestLogger(t), "perception", false)
+	p.Processes.Set(42, &Process{PID: 42, Name: "perception", startTime: startTime})
+	p.lastSync = synced
+
+	_, stale := p.cacheIsStale(synced.Add(time.Second))
+	assert.False(t, stale)
+
+	// New matching processes are only found by a sync
+	reason, stale := p.cacheIsStale(synced.Add(DefaultProcessResyncInterval))
+	assert.True(t, stale)
+	assert.Equal(t, "Resync interval passed", reason)
+
+	// The process restarted and got the same PID
+	utilstest.WriteFixture(t, root, "/proc/42/stat", fmt.Sprintf(stat, 9000))
+	reason, stale = p.cacheIsStale(synced.Add(time.Second))
+	assert.True(t, stale)
+	assert.Contains(t, reason, "replaced")
+
+	utilstest.UseFixtureFS(t, nil)
+	reason, stale = p.cacheIsStale(synced.Add(time.Second))
+	assert.True(t, stale)
+	assert.Contains(t, reason, "exited")
+}
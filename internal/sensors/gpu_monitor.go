@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/csv"
 	"errors"
-	"os/exec"
 	"strconv"
 	"strings"
 
@@ -78,7 +77,7 @@ func (n *nvidiaGpuMonitor) Close() error {
 
 func (n *nvidiaGpuMonitor) GetGPUStats(ctx context.Context) (map[string][]GPUSensorReading, error) {
 
-	output, err := getNvidiaSmiOutput()
+	output, err := getNvidiaSmiOutput(ctx)
 	if err != nil {
 		return nil, errors.Join(errors.New("error detecting gpus with nvidia-smi"), err)
 	}
@@ -158,9 +157,8 @@ func (n *nvidiaGpuMonitor) parseNvidiaSmiOutput(output []byte) (map[string][]GPU
 	return stats, nil
 }
 
-func getNvidiaSmiOutput() ([]byte, error) {
-	cmd := exec.Command(nvidiaSmi, "--query-gpu", strings.Join(nvidiaSmiDefaultSensors, ","), "--format=csv,nounits")
-	output, err := cmd.CombinedOutput()
+func getNvidiaSmiOutput(ctx context.Context) ([]byte, error) {
+	output, err := utils.RunCommand(ctx, nvidiaSmi, "--query-gpu", strings.Join(nvidiaSmiDefaultSensors, ","), "--format=csv,nounits")
 	if err != nil {
 		return nil, errors.Join(errors.New("error detecting gpus with nvidia-smi"), err)
 	}
@@ -3,8 +3,11 @@ package sensors
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
 )
@@ -56,6 +59,25 @@ func TestNvidiaSmiParsing(t *testing.T) {
 	}
 }
 
+func TestNvidiaGPUWithFakeNvidiaSmi(t *testing.T) {
+	b, err := os.ReadFile("testdata/nvidia-smi.txt")
+	require.NoError(t, err)
+	fake := utilstest.NewFakeCommandRunner().OnOutput(string(b), nvidiaSmi, "--query-gpu", strings.Join(nvidiaSmiDefaultSensors, ","), "--format=csv,nounits")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	logger := logging.NewTestLogger(t)
+	require.True(t, HasNvidiaSmiCommand(logger))
+
+	monitor, err := NewNVIDIAGpuMonitor(logger)
+	require.NoError(t, err)
+	stats, err := monitor.GetGPUStats(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	for _, stat := range stats {
+		require.NotEmpty(t, stat)
+	}
+}
+
 // func TestNvidiaGpu_Readings(t *testing.T) {
 // 	skipIfNoNvidiaDriver(t)
 // 	ctx := context.Background()
@@ -1,21 +1,18 @@
 package sensors
 
 import (
-	"os/exec"
-
 	"go.viam.com/rdk/logging"
 
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 )
 
 func HasNvidiaSmiCommand(logger logging.Logger) bool {
-	cmd := exec.Command("where", "nvidia-smi")
-	stdOut, stdErr := cmd.CombinedOutput()
-	logger.Debugf("where nvidia-smi command output: %s", stdOut)
-	if stdErr != nil {
-		logger.Debugf("nvidia-smi command not found: %v", stdErr)
+	path, err := utils.LookPath(nvidiaSmi)
+	if err != nil {
+		logger.Debugf("nvidia-smi command not found: %v", err)
 		return false
 	}
+	logger.Debugf("found nvidia-smi at %s", path)
 	return true
 }
 
@@ -0,0 +1,432 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"go.viam.com/rdk/logging"
+)
+
+const hwmonGlob = "/sys/class/hwmon/hwmon*"
+
+// HwmonSensorType is the attribute prefix the hwmon ABI uses for each kind of channel.
+type HwmonSensorType string
+
+const (
+	HwmonTemperature HwmonSensorType = "temp"
+	HwmonVoltage     HwmonSensorType = "in"
+	HwmonCurrent     HwmonSensorType = "curr"
+	HwmonPower       HwmonSensorType = "power"
+	HwmonFan         HwmonSensorType = "fan"
+)
+
+var (
+	hwmonSensorTypes = []HwmonSensorType{HwmonTemperature, HwmonVoltage, HwmonCurrent, HwmonPower, HwmonFan}
+	// hwmon reports millidegrees, millivolts, milliamps, microwatts and RPM
+	hwmonScales = map[HwmonSensorType]float64{
+		HwmonTemperature: 1000,
+		HwmonVoltage:     1000,
+		HwmonCurrent:     1000,
+		HwmonPower:       1000000,
+		HwmonFan:         1,
+	}
+	hwmonUnits = map[HwmonSensorType]string{
+		HwmonTemperature: "C",
+		HwmonVoltage:     "V",
+		HwmonCurrent:     "A",
+		HwmonPower:       "W",
+		HwmonFan:         "RPM",
+	}
+	hwmonReadingNames = map[HwmonSensorType]string{
+		HwmonTemperature: "temperature",
+		HwmonVoltage:     "voltage",
+		HwmonCurrent:     "current",
+		HwmonPower:       "power",
+		HwmonFan:         "fan",
+	}
+	hwmonAlarms = []string{"alarm", "crit_alarm", "lcrit_alarm", "max_alarm", "min_alarm"}
+	// Well known chips, in order of preference, the first temperature channel of the chip is used
+	cpuHwmonChips = []string{"coretemp", "k10temp", "zenpower", "cpu_thermal"}
+	gpuHwmonChips = []string{"amdgpu", "nouveau", "gpu_thermal"}
+)
+
+// HwmonChannel is a single measurement exposed by a hwmon chip, ex: temp1 of coretemp.
+type HwmonChannel struct {
+	// Chip is the driver reported name of the chip, ex: coretemp
+	Chip string
+	// Dir is the hwmon directory of the chip, ex: /sys/class/hwmon/hwmon2
+	Dir   string
+	Type  HwmonSensorType
+	Index int
+	// Label is the driver supplied label, it is empty when the driver does not provide one
+	Label string
+	// input is the attribute holding the value, power channels only have an average on some drivers
+	input string
+}
+
+func (c HwmonChannel) attribute(name string) string {
+	return path.Join(c.Dir, fmt.Sprintf("%s%d_%s", c.Type, c.Index, name))
+}
+
+// Name is the label of the channel, or the attribute prefix if there is no label, ex: in1.
+func (c HwmonChannel) Name() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return fmt.Sprintf("%s%d", c.Type, c.Index)
+}
+
+func (c HwmonChannel) Unit() string {
+	return hwmonUnits[c.Type]
+}
+
+// Read returns the current value of the channel in Unit.
+func (c HwmonChannel) Read(ctx context.Context) (float64, error) {
+	raw, err := utils.ReadInt64FromFileWithContext(ctx, c.attribute(c.input))
+	if err != nil {
+		return 0, err
+	}
+	return float64(raw) / hwmonScales[c.Type], nil
+}
+
+// ReadAlarms returns the alarm flags the driver exposes for the channel, keyed by alarm name, ex: crit_alarm.
+func (c HwmonChannel) ReadAlarms(ctx context.Context) (map[string]bool, error) {
+	alarms := make(map[string]bool)
+	for _, alarm := range hwmonAlarms {
+		value, err := utils.ReadBoolFromFileWithContext(ctx, c.attribute(alarm))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		alarms[alarm] = value
+	}
+	return alarms, nil
+}
+
+// ScanHwmon finds every channel of every chip under /sys/class/hwmon, ordered by chip then type and index.
+func ScanHwmon() ([]HwmonChannel, error) {
+	dirs, err := utils.Glob(hwmonGlob)
+	if err != nil {
+		return nil, err
+	}
+	channels := make([]HwmonChannel, 0)
+	for _, dir := range dirs {
+		chip, err := utils.ReadFile(path.Join(dir, "name"))
+		if err != nil {
+			continue
+		}
+		for _, sensorType := range hwmonSensorTypes {
+			found, err := scanHwmonChannels(dir, strings.TrimSpace(string(chip)), sensorType)
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, found...)
+		}
+	}
+	return channels, nil
+}
+
+func scanHwmonChannels(dir, chip string, sensorType HwmonSensorType) ([]HwmonChannel, error) {
+	byIndex := make(map[int]HwmonChannel)
+	// Prefer the instantaneous value, fall back to the average for drivers like amdgpu that only report that
+	for _, input := range []string{"average", "input"} {
+		matches, err := utils.Glob(path.Join(dir, fmt.Sprintf("%s*_%s", sensorType, input)))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			var index int
+			if _, err := fmt.Sscanf(path.Base(match), string(sensorType)+"%d_"+input, &index); err != nil {
+				continue
+			}
+			byIndex[index] = HwmonChannel{Chip: chip, Dir: dir, Type: sensorType, Index: index, input: input}
+		}
+	}
+	channels := make([]HwmonChannel, 0, len(byIndex))
+	for _, channel := range byIndex {
+		if label, err := utils.ReadFile(channel.attribute("label")); err == nil {
+			channel.Label = strings.TrimSpace(string(label))
+		}
+		channels = append(channels, channel)
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].Index < channels[j].Index
+	})
+	return channels, nil
+}
+
+// hwmonNames names each key by name, falling back to qualified for names that are not unique.
+func hwmonNames[K comparable](keys []K, name func(K) string, qualified func(K) string) map[K]string {
+	counts := make(map[string]int)
+	for _, key := range keys {
+		counts[name(key)]++
+	}
+	names := make(map[K]string, len(keys))
+	for _, key := range keys {
+		n := name(key)
+		if counts[n] > 1 {
+			n = qualified(key)
+		}
+		names[key] = strings.ReplaceAll(n, " ", "_")
+	}
+	return names
+}
+
+type hwmonTemperatureSensor struct {
+	channel HwmonChannel
+	name    string
+}
+
+func (s *hwmonTemperatureSensor) Name() string {
+	return s.name
+}
+
+func (s *hwmonTemperatureSensor) Read(ctx context.Context) (float64, error) {
+	return s.channel.Read(ctx)
+}
+
+// GetHwmonTemperatureSensors returns a TemperatureReader for every hwmon temperature channel, named chip_label.
+func GetHwmonTemperatureSensors() ([]TemperatureReader, error) {
+	channels, err := ScanHwmon()
+	if err != nil {
+		return nil, err
+	}
+	temps, names := hwmonChannelsOfType(channels, HwmonTemperature)
+	readers := make([]TemperatureReader, 0, len(temps))
+	for _, channel := range temps {
+		readers = append(readers, &hwmonTemperatureSensor{channel: channel, name: names[channel]})
+	}
+	return readers, nil
+}
+
+// hwmonChannelsOfType returns the channels of sensorType, and their names, chip_label.
+func hwmonChannelsOfType(channels []HwmonChannel, sensorType HwmonSensorType) ([]HwmonChannel, map[HwmonChannel]string) {
+	ofType := make([]HwmonChannel, 0)
+	for _, channel := range channels {
+		if channel.Type == sensorType {
+			ofType = append(ofType, channel)
+		}
+	}
+	// Identical devices, ex: two NVMe drives, report the same chip name and labels so suffix the hwmon number
+	name := func(c HwmonChannel) string {
+		return c.Chip + "_" + c.Name()
+	}
+	names := hwmonNames(ofType, name, func(c HwmonChannel) string {
+		return name(c) + "_" + strings.TrimPrefix(path.Base(c.Dir), "hwmon")
+	})
+	return ofType, names
+}
+
+// AddHwmonFans adds the speed of every hwmon fan to temps, in RPM, named chip_label.
+func AddHwmonFans(ctx context.Context, temps *SystemTemperatures) error {
+	channels, err := ScanHwmon()
+	if err != nil {
+		return err
+	}
+	fans, names := hwmonChannelsOfType(channels, HwmonFan)
+	if len(fans) > 0 && temps.Fans == nil {
+		temps.Fans = make(map[string]float64)
+	}
+	for _, fan := range fans {
+		rpm, err := fan.Read(ctx)
+		if err != nil {
+			continue
+		}
+		temps.Fans[names[fan]] = rpm
+	}
+	return nil
+}
+
+// AddHwmonTemperatures adds every hwmon temperature to temps. Chips that mirror a thermal zone are skipped, and
+// well known CPU and GPU chips fill in the CPU and GPU temperatures if no thermal zone did.
+func AddHwmonTemperatures(ctx context.Context, temps *SystemTemperatures) error {
+	readers, err := GetHwmonTemperatureSensors()
+	if err != nil {
+		return err
+	}
+	zones, err := GetThermalZones()
+	if err != nil {
+		return err
+	}
+	mirrored := make(map[string]bool, len(zones))
+	for _, zone := range zones {
+		mirrored[strings.ReplaceAll(zone.Type, "-", "_")] = true
+	}
+	if temps.Extra == nil {
+		temps.Extra = make(map[string]float64)
+	}
+	cpuRank, gpuRank := len(cpuHwmonChips), len(gpuHwmonChips)
+	var cpuName, gpuName string
+	for _, reader := range readers {
+		channel := reader.(*hwmonTemperatureSensor).channel
+		if mirrored[channel.Chip] {
+			continue
+		}
+		temp, err := reader.Read(ctx)
+		if err != nil {
+			continue
+		}
+		temps.Extra[reader.Name()] = utils.RoundValue(temp, 2)
+		if rank := hwmonChipRank(cpuHwmonChips, channel.Chip); temps.CPU == nil && rank < cpuRank {
+			cpuRank, cpuName = rank, reader.Name()
+		}
+		if rank := hwmonChipRank(gpuHwmonChips, channel.Chip); temps.GPU == nil && rank < gpuRank {
+			gpuRank, gpuName = rank, reader.Name()
+		}
+	}
+	if cpuName != "" {
+		temp := temps.Extra[cpuName]
+		temps.CPU = &temp
+		delete(temps.Extra, cpuName)
+	}
+	if gpuName != "" {
+		temp := temps.Extra[gpuName]
+		temps.GPU = &temp
+		delete(temps.Extra, gpuName)
+	}
+	return nil
+}
+
+func hwmonChipRank(chips []string, chip string) int {
+	for i, c := range chips {
+		if c == chip {
+			return i
+		}
+	}
+	return len(chips)
+}
+
+type hwmonPowerKey struct {
+	dir   string
+	index int
+}
+
+// hwmonPowerSensor combines the voltage, current and power channels with the same index on a chip, which is how
+// power monitors like the INA2xx and INA3221 report a rail.
+type hwmonPowerSensor struct {
+	logger     logging.Logger
+	mu         sync.RWMutex
+	name       string
+	channels   map[HwmonSensorType]HwmonChannel
+	cancelCtx  context.Context
+	cancelFunc context.CancelFunc
+}
+
+func (s *hwmonPowerSensor) Close() error {
+	s.cancelFunc()
+	return nil
+}
+
+func (s *hwmonPowerSensor) GetName() string {
+	return s.name
+}
+
+func (s *hwmonPowerSensor) read(sensorType HwmonSensorType) (float64, bool, error) {
+	channel, ok := s.channels[sensorType]
+	if !ok {
+		return 0, false, nil
+	}
+	value, err := channel.Read(s.cancelCtx)
+	return value, err == nil, err
+}
+
+func (s *hwmonPowerSensor) GetReading() (voltage, current, power float64, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	voltage, hasVoltage, vErr := s.read(HwmonVoltage)
+	current, hasCurrent, cErr := s.read(HwmonCurrent)
+	power, hasPower, pErr := s.read(HwmonPower)
+	if err = errors.Join(vErr, cErr, pErr); err != nil {
+		return 0, 0, 0, err
+	}
+	if !hasPower && hasVoltage && hasCurrent {
+		power = voltage * current
+	}
+	return voltage, current, power, nil
+}
+
+func (s *hwmonPowerSensor) GetReadingMap() (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ret := make(map[string]interface{})
+	for _, sensorType := range []HwmonSensorType{HwmonVoltage, HwmonCurrent, HwmonPower} {
+		channel, ok := s.channels[sensorType]
+		if !ok {
+			continue
+		}
+		value, err := channel.Read(s.cancelCtx)
+		if err != nil {
+			return nil, err
+		}
+		ret[hwmonReadingNames[sensorType]] = value
+		alarms, err := channel.ReadAlarms(s.cancelCtx)
+		if err != nil {
+			return nil, err
+		}
+		for alarm, active := range alarms {
+			ret[hwmonReadingNames[sensorType]+"_"+alarm] = active
+		}
+	}
+	return ret, nil
+}
+
+// GetHwmonPowerSensors returns a PowerSensor for every rail reported through hwmon voltage, current and power
+// channels. Rails are named by their label, prefixed with the chip name if the label is not unique.
+func GetHwmonPowerSensors(ctx context.Context, logger logging.Logger) ([]PowerSensor, error) {
+	channels, err := ScanHwmon()
+	if err != nil {
+		return nil, err
+	}
+	rails := make(map[hwmonPowerKey]map[HwmonSensorType]HwmonChannel)
+	keys := make([]hwmonPowerKey, 0)
+	for _, channel := range channels {
+		if channel.Type != HwmonVoltage && channel.Type != HwmonCurrent && channel.Type != HwmonPower {
+			continue
+		}
+		key := hwmonPowerKey{dir: channel.Dir, index: channel.Index}
+		if _, ok := rails[key]; !ok {
+			rails[key] = make(map[HwmonSensorType]HwmonChannel)
+			keys = append(keys, key)
+		}
+		rails[key][channel.Type] = channel
+	}
+	railName := func(key hwmonPowerKey) string {
+		for _, sensorType := range []HwmonSensorType{HwmonVoltage, HwmonCurrent, HwmonPower} {
+			if channel, ok := rails[key][sensorType]; ok && channel.Label != "" {
+				return channel.Label
+			}
+		}
+		for _, channel := range rails[key] {
+			return fmt.Sprintf("%s_%d", channel.Chip, key.index)
+		}
+		return ""
+	}
+	names := hwmonNames(keys, railName, func(key hwmonPowerKey) string {
+		for _, channel := range rails[key] {
+			return channel.Chip + "_" + railName(key)
+		}
+		return railName(key)
+	})
+	ret := make([]PowerSensor, 0, len(keys))
+	for _, key := range keys {
+		logger.Debugf("Creating hwmon power sensor: %s", names[key])
+		cancelCtx, cancelFunc := context.WithCancel(ctx)
+		ret = append(ret, &hwmonPowerSensor{
+			logger:     logger.Sublogger(names[key]),
+			name:       names[key],
+			channels:   rails[key],
+			cancelCtx:  cancelCtx,
+			cancelFunc: cancelFunc,
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,146 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+)
+
+func useHwmonFixture(t *testing.T) {
+	t.Helper()
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+}
+
+func TestScanHwmon(t *testing.T) {
+	useHwmonFixture(t)
+
+	channels, err := ScanHwmon()
+	require.NoError(t, err)
+	require.Len(t, channels, 13)
+
+	byName := make(map[string]HwmonChannel)
+	for _, c := range channels {
+		byName[c.Dir+"/"+string(c.Type)+"/"+c.Name()] = c
+	}
+	pkg := byName["/sys/class/hwmon/hwmon0/temp/Package id 0"]
+	assert.Equal(t, "coretemp", pkg.Chip)
+	assert.Equal(t, "C", pkg.Unit())
+	temp, err := pkg.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 52.0, temp)
+	alarms, err := pkg.ReadAlarms(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"crit_alarm": false}, alarms)
+
+	power := byName["/sys/class/hwmon/hwmon4/power/power1"]
+	assert.Equal(t, "W", power.Unit())
+	watts, err := power.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 35.0, watts)
+
+	fan := byName["/sys/class/hwmon/hwmon4/fan/fan1"]
+	assert.Equal(t, "RPM", fan.Unit())
+	rpm, err := fan.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1200.0, rpm)
+}
+
+func TestGetHwmonTemperatureSensors(t *testing.T) {
+	useHwmonFixture(t)
+
+	readers, err := GetHwmonTemperatureSensors()
+	require.NoError(t, err)
+	names := make([]string, 0, len(readers))
+	for _, r := range readers {
+		names = append(names, r.Name())
+	}
+	assert.Equal(t, []string{
+		"coretemp_Package_id_0",
+		"coretemp_Core_0",
+		"nvme_Composite_1",
+		"nvme_Composite_2",
+		"amdgpu_edge",
+		"acpitz_temp1",
+	}, names)
+}
+
+func TestAddHwmonTemperatures(t *testing.T) {
+	useHwmonFixture(t)
+
+	temps, err := GetThermalZoneTemperatures(context.Background(), ThermalZoneName)
+	require.NoError(t, err)
+	require.NoError(t, AddHwmonTemperatures(context.Background(), temps))
+	require.NotNil(t, temps.CPU)
+	assert.Equal(t, 52.0, *temps.CPU)
+	require.NotNil(t, temps.GPU)
+	assert.Equal(t, 61.0, *temps.GPU)
+	// acpitz is already reported as a thermal zone
+	assert.Equal(t, map[string]float64{
+		"acpitz":           27.8,
+		"coretemp_Core_0":  50.0,
+		"nvme_Composite_1": 38.85,
+		"nvme_Composite_2": 40.85,
+	}, temps.Extra)
+}
+
+func TestAddHwmonTemperatures_KeepsThermalZoneCpu(t *testing.T) {
+	useHwmonFixture(t)
+	cpu := 45.0
+	temps := &SystemTemperatures{CPU: &cpu}
+
+	require.NoError(t, AddHwmonTemperatures(context.Background(), temps))
+	assert.Equal(t, 45.0, *temps.CPU)
+	assert.Equal(t, 52.0, temps.Extra["coretemp_Package_id_0"])
+}
+
+func TestAddHwmonFans(t *testing.T) {
+	useHwmonFixture(t)
+	temps := &SystemTemperatures{}
+
+	require.NoError(t, AddHwmonFans(context.Background(), temps))
+	assert.Equal(t, map[string]float64{"amdgpu_fan1": 1200}, temps.Fans)
+	assert.Empty(t, temps.Extra)
+}
+
+func TestGetHwmonPowerSensors(t *testing.T) {
+	useHwmonFixture(t)
+
+	powerSensors, err := GetHwmonPowerSensors(context.Background(), logging.NewTestLogger(t))
+	require.NoError(t, err)
+	byName := make(map[string]PowerSensor)
+	for _, s := range powerSensors {
+		byName[s.GetName()] = s
+		defer s.Close()
+	}
+	require.Len(t, byName, 4)
+	require.Contains(t, byName, "ina219_0")
+	require.Contains(t, byName, "amdgpu_1")
+
+	voltage, current, power, err := byName["ina219_1"].GetReading()
+	require.NoError(t, err)
+	assert.Equal(t, 5.12, voltage)
+	assert.Equal(t, 1.5, current)
+	assert.Equal(t, 7.68, power)
+
+	readings, err := byName["ina219_1"].GetReadingMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"voltage":            5.12,
+		"current":            1.5,
+		"current_crit_alarm": true,
+		"power":              7.68,
+	}, readings)
+
+	readings, err = byName["vddgfx"].GetReadingMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"voltage": 0.85}, readings)
+
+	_, _, power, err = byName["amdgpu_1"].GetReading()
+	require.NoError(t, err)
+	assert.Equal(t, 35.0, power)
+}
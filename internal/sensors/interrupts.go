@@ -0,0 +1,155 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+var (
+	ErrInvalidInterrupts = errors.New("invalid interrupts file")
+)
+
+// InterruptCounter is one row of /proc/interrupts or /proc/softirqs
+type InterruptCounter struct {
+	Name        string   // IRQ number, like 42, or name, like LOC or TIMER
+	Device      string   // Device the IRQ belongs to, empty for softirqs
+	Description string   // Everything after the counts, like "GICv3 27 Level arch_timer"
+	Counts      []uint64 // Per CPU count, in the same order as Interrupts.CPUs
+}
+
+// Interrupts is the contents of /proc/interrupts or /proc/softirqs
+type Interrupts struct {
+	Timestamp time.Time
+	CPUs      []string // Lower cased like cpu_monitor, cpu0, cpu1...
+	Counters  []InterruptCounter
+}
+
+func ReadInterrupts(ctx context.Context) (*Interrupts, error) {
+	return readInterruptsFile(ctx, "/proc/interrupts")
+}
+
+func ReadSoftIRQs(ctx context.Context) (*Interrupts, error) {
+	return readInterruptsFile(ctx, "/proc/softirqs")
+}
+
+func readInterruptsFile(ctx context.Context, path string) (*Interrupts, error) {
+	contents, err := utils.ReadFileWithContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInterrupts(contents)
+}
+
+// ParseInterrupts parses the /proc/interrupts and /proc/softirqs format, a header of CPUs followed by a row per counter.
+// Rows like ERR and MIS only have a single count and are padded with zeros.
+func ParseInterrupts(contents string) (*Interrupts, error) {
+	lines := strings.Split(strings.TrimSpace(contents), "\n")
+	if len(lines) == 0 || len(strings.Fields(lines[0])) == 0 {
+		return nil, fmt.Errorf("%w: missing CPU header", ErrInvalidInterrupts)
+	}
+	ret := &Interrupts{Timestamp: time.Now()}
+	for _, cpu := range strings.Fields(lines[0]) {
+		ret.CPUs = append(ret.CPUs, strings.ToLower(cpu))
+	}
+	for _, line := range lines[1:] {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		counter := InterruptCounter{Name: strings.TrimSpace(name), Counts: make([]uint64, len(ret.CPUs))}
+		i := 0
+		for ; i < len(fields) && i < len(ret.CPUs); i++ {
+			count, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				break
+			}
+			counter.Counts[i] = count
+		}
+		counter.Description = strings.Join(fields[i:], " ")
+		if _, err := strconv.Atoi(counter.Name); err == nil {
+			counter.Device = interruptDevice(fields[i:])
+		}
+		ret.Counters = append(ret.Counters, counter)
+	}
+	return ret, nil
+}
+
+// interruptDevice returns the action names at the end of a numbered IRQ's description. IRQs shared by several
+// devices list them comma separated, like "ehci_hcd:usb1, uhci_hcd:usb2".
+func interruptDevice(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	start := len(fields) - 1
+	for start > 0 && strings.HasSuffix(fields[start-1], ",") {
+		start--
+	}
+	return strings.Join(fields[start:], " ")
+}
+
+// InterruptRate is the rate of a counter between two samples
+type InterruptRate struct {
+	Name   string
+	Device string
+	Total  float64
+	PerCPU map[string]float64
+}
+
+func (r InterruptRate) Map() map[string]interface{} {
+	perCPU := make(map[string]interface{}, len(r.PerCPU))
+	for cpu, rate := range r.PerCPU {
+		perCPU[cpu] = rate
+	}
+	ret := map[string]interface{}{
+		"name":    r.Name,
+		"rate":    r.Total,
+		"per_cpu": perCPU,
+	}
+	if r.Device != "" {
+		ret["device"] = r.Device
+	}
+	return ret
+}
+
+// CalculateInterruptRates returns the per second rate of every counter in curr, sorted by total rate with the busiest
+// first. Counters that are not in prev, like a newly registered IRQ, are skipped.
+func CalculateInterruptRates(prev, curr *Interrupts) []InterruptRate {
+	seconds := curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	previous := make(map[string]InterruptCounter, len(prev.Counters))
+	for _, counter := range prev.Counters {
+		previous[counter.Name] = counter
+	}
+	ret := make([]InterruptRate, 0, len(curr.Counters))
+	for _, counter := range curr.Counters {
+		p, ok := previous[counter.Name]
+		if !ok || len(p.Counts) != len(counter.Counts) {
+			continue
+		}
+		rate := InterruptRate{Name: counter.Name, Device: counter.Device, PerCPU: make(map[string]float64, len(curr.CPUs))}
+		var total uint64
+		for i, cpu := range curr.CPUs {
+			d := counterDelta(p.Counts[i], counter.Counts[i])
+			total += d
+			rate.PerCPU[cpu] = perSecond(d, seconds)
+		}
+		rate.Total = perSecond(total, seconds)
+		ret = append(ret, rate)
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].Total > ret[j].Total })
+	return ret
+}
+
+func perSecond(count uint64, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0.0
+	}
+	return utils.RoundValue(float64(count)/seconds, 2)
+}
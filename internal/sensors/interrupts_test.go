@@ -0,0 +1,53 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadInterrupts(t *testing.T) {
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+
+	interrupts, err := ReadInterrupts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpu0", "cpu1", "cpu2", "cpu3"}, interrupts.CPUs)
+	require.Len(t, interrupts.Counters, 7)
+	assert.Equal(t, InterruptCounter{
+		Name:        "11",
+		Device:      "arch_timer",
+		Description: "GICv3 27 Level arch_timer",
+		Counts:      []uint64{2716502, 1843922, 1596312, 1657381},
+	}, interrupts.Counters[0])
+	assert.Equal(t, "mmc0, mmc1", interrupts.Counters[3].Device)
+	assert.Equal(t, InterruptCounter{Name: "IPI0", Description: "Rescheduling interrupts", Counts: []uint64{21876, 23591, 20124, 19983}}, interrupts.Counters[4])
+	assert.Equal(t, InterruptCounter{Name: "Err", Description: "", Counts: []uint64{0, 0, 0, 0}}, interrupts.Counters[6])
+
+	softirqs, err := ReadSoftIRQs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, softirqs.Counters, 10)
+	assert.Equal(t, InterruptCounter{Name: "NET_RX", Counts: []uint64{23419, 2210, 1853, 1765}}, softirqs.Counters[3])
+}
+
+func TestCalculateInterruptRates(t *testing.T) {
+	start := time.Now()
+	prev := &Interrupts{Timestamp: start, CPUs: []string{"cpu0", "cpu1"}, Counters: []InterruptCounter{
+		{Name: "11", Device: "arch_timer", Counts: []uint64{100, 100}},
+		{Name: "42", Device: "can0", Counts: []uint64{0, 0}},
+	}}
+	curr := &Interrupts{Timestamp: start.Add(2 * time.Second), CPUs: []string{"cpu0", "cpu1"}, Counters: []InterruptCounter{
+		{Name: "11", Device: "arch_timer", Counts: []uint64{300, 300}},
+		{Name: "42", Device: "can0", Counts: []uint64{0, 10000}},
+		{Name: "43", Device: "hotplugged", Counts: []uint64{5, 5}},
+	}}
+
+	assert.Equal(t, []InterruptRate{
+		{Name: "42", Device: "can0", Total: 5000, PerCPU: map[string]float64{"cpu0": 0, "cpu1": 5000}},
+		{Name: "11", Device: "arch_timer", Total: 200, PerCPU: map[string]float64{"cpu0": 100, "cpu1": 100}},
+	}, CalculateInterruptRates(prev, curr))
+}
@@ -0,0 +1,128 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+var (
+	ErrInvalidLoadAverage = errors.New("invalid /proc/loadavg")
+)
+
+// LoadAverage is the contents of /proc/loadavg
+type LoadAverage struct {
+	Load1    float64
+	Load5    float64
+	Load15   float64
+	Runnable int64 // Runnable threads
+	Threads  int64 // Threads in the system
+}
+
+// KernelStats holds the scheduler and interrupt counters from /proc/stat
+type KernelStats struct {
+	Timestamp       time.Time
+	Cores           int
+	ContextSwitches uint64
+	Forks           uint64
+	Interrupts      uint64
+	SoftIRQs        uint64
+	ProcsRunning    uint64
+	ProcsBlocked    uint64
+}
+
+func ReadLoadAverage(ctx context.Context) (*LoadAverage, error) {
+	contents, err := utils.ReadFileWithContext(ctx, "/proc/loadavg")
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(contents)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLoadAverage, contents)
+	}
+	load := &LoadAverage{}
+	for i, value := range []*float64{&load.Load1, &load.Load5, &load.Load15} {
+		if *value, err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLoadAverage, err)
+		}
+	}
+	runnable, threads, ok := strings.Cut(fields[3], "/")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLoadAverage, contents)
+	}
+	if load.Runnable, err = strconv.ParseInt(runnable, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLoadAverage, err)
+	}
+	if load.Threads, err = strconv.ParseInt(threads, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLoadAverage, err)
+	}
+	return load, nil
+}
+
+// ReadKernelStats parses ctxt, processes, procs_running, procs_blocked, intr and softirq from /proc/stat. Only the
+// totals of intr and softirq are kept.
+func ReadKernelStats(ctx context.Context) (*KernelStats, error) {
+	contents, err := utils.ReadFileWithContext(ctx, "/proc/stat")
+	if err != nil {
+		return nil, err
+	}
+	stats := &KernelStats{Timestamp: time.Now()}
+	for _, line := range strings.Split(contents, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		var counter *uint64
+		switch fields[0] {
+		case "ctxt":
+			counter = &stats.ContextSwitches
+		case "processes":
+			counter = &stats.Forks
+		case "procs_running":
+			counter = &stats.ProcsRunning
+		case "procs_blocked":
+			counter = &stats.ProcsBlocked
+		case "intr":
+			counter = &stats.Interrupts
+		case "softirq":
+			counter = &stats.SoftIRQs
+		default:
+			if strings.HasPrefix(fields[0], "cpu") && fields[0] != "cpu" {
+				stats.Cores++
+			}
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s in /proc/stat: %w", fields[0], err)
+		}
+		*counter = value
+	}
+	return stats, nil
+}
+
+// KernelRates holds the per second rates of the /proc/stat counters between two samples
+type KernelRates struct {
+	ContextSwitches float64
+	Forks           float64
+	Interrupts      float64
+	SoftIRQs        float64
+}
+
+func CalculateKernelRates(prev, curr *KernelStats) KernelRates {
+	seconds := curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	rate := func(prev, curr uint64) float64 {
+		return perSecond(counterDelta(prev, curr), seconds)
+	}
+	return KernelRates{
+		ContextSwitches: rate(prev.ContextSwitches, curr.ContextSwitches),
+		Forks:           rate(prev.Forks, curr.Forks),
+		Interrupts:      rate(prev.Interrupts, curr.Interrupts),
+		SoftIRQs:        rate(prev.SoftIRQs, curr.SoftIRQs),
+	}
+}
@@ -0,0 +1,43 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLoadAverage(t *testing.T) {
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+
+	load, err := ReadLoadAverage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &LoadAverage{Load1: 0.52, Load5: 0.38, Load15: 1.07, Runnable: 2, Threads: 431}, load)
+}
+
+func TestReadKernelStats(t *testing.T) {
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+
+	stats, err := ReadKernelStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Cores)
+	assert.Equal(t, uint64(1990473), stats.ContextSwitches)
+	assert.Equal(t, uint64(2915), stats.Forks)
+	assert.Equal(t, uint64(114930548), stats.Interrupts)
+	assert.Equal(t, uint64(183433), stats.SoftIRQs)
+	assert.Equal(t, uint64(3), stats.ProcsRunning)
+	assert.Equal(t, uint64(1), stats.ProcsBlocked)
+}
+
+func TestCalculateKernelRates(t *testing.T) {
+	start := time.Now()
+	prev := &KernelStats{Timestamp: start, ContextSwitches: 1000, Forks: 10, Interrupts: 500, SoftIRQs: 200}
+	curr := &KernelStats{Timestamp: start.Add(2 * time.Second), ContextSwitches: 3000, Forks: 13, Interrupts: 1500, SoftIRQs: 100}
+
+	assert.Equal(t, KernelRates{ContextSwitches: 1000, Forks: 1.5, Interrupts: 500, SoftIRQs: 0}, CalculateKernelRates(prev, curr))
+	assert.Equal(t, KernelRates{}, CalculateKernelRates(curr, curr))
+}
@@ -0,0 +1,317 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// limitNames maps the rows of /proc/<pid>/limits to the rlimit names ulimit and getrlimit use
+var limitNames = map[string]string{
+	"Max cpu time":          "cpu",
+	"Max file size":         "fsize",
+	"Max data size":         "data",
+	"Max stack size":        "stack",
+	"Max core file size":    "core",
+	"Max resident set":      "rss",
+	"Max processes":         "nproc",
+	"Max open files":        "nofile",
+	"Max locked memory":     "memlock",
+	"Max address space":     "as",
+	"Max file locks":        "locks",
+	"Max pending signals":   "sigpending",
+	"Max msgqueue size":     "msgqueue",
+	"Max nice priority":     "nice",
+	"Max realtime priority": "rtprio",
+	"Max realtime timeout":  "rttime",
+}
+
+// ProcessLimit is a row of /proc/<pid>/limits, unlimited values are Unlimited
+type ProcessLimit struct {
+	Soft  int64
+	Hard  int64
+	Units string
+}
+
+func (l ProcessLimit) Map() map[string]interface{} {
+	ret := map[string]interface{}{
+		"soft": l.Soft,
+		"hard": l.Hard,
+	}
+	if l.Units != "" {
+		ret["units"] = l.Units
+	}
+	return ret
+}
+
+func procPath(pid int32, name ...string) string {
+	return filepath.Join(append([]string{"/proc", strconv.Itoa(int(pid))}, name...)...)
+}
+
+// ReadProcessLimits reads /proc/<pid>/limits keyed by rlimit name, like nofile
+func ReadProcessLimits(ctx context.Context, pid int32) (map[string]ProcessLimit, error) {
+	contents, err := utils.ReadFileWithContext(ctx, procPath(pid, "limits"))
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]ProcessLimit)
+	lines := strings.Split(contents, "\n")
+	if len(lines) == 0 {
+		return ret, nil
+	}
+	// The columns are fixed width, the header says where each one starts
+	header := lines[0]
+	softStart := strings.Index(header, "Soft Limit")
+	hardStart := strings.Index(header, "Hard Limit")
+	unitsStart := strings.Index(header, "Units")
+	if softStart < 0 || hardStart < 0 {
+		return nil, fmt.Errorf("unexpected limits header %q", header)
+	}
+	for _, line := range lines[1:] {
+		if len(line) < hardStart {
+			continue
+		}
+		name, ok := limitNames[strings.TrimSpace(line[:softStart])]
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(line[softStart:])
+		if len(fields) < 2 {
+			continue
+		}
+		limit := ProcessLimit{}
+		if limit.Soft, err = parseRlimit(fields[0]); err != nil {
+			return nil, err
+		}
+		if limit.Hard, err = parseRlimit(fields[1]); err != nil {
+			return nil, err
+		}
+		if unitsStart > 0 && len(line) > unitsStart {
+			limit.Units = strings.TrimSpace(line[unitsStart:])
+		}
+		ret[name] = limit
+	}
+	return ret, nil
+}
+
+func parseRlimit(value string) (int64, error) {
+	if value == "unlimited" {
+		return Unlimited, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid limit %q: %w", value, err)
+	}
+	return limit, nil
+}
+
+// tcpStates are the states in /proc/net/tcp, from include/net/tcp_states.h
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+	"0C": "NEW_SYN_RECV",
+}
+
+// unixStates are the states in /proc/net/unix, from include/uapi/linux/net.h
+var unixStates = map[string]string{
+	"01": "UNCONNECTED",
+	"02": "CONNECTING",
+	"03": "CONNECTED",
+	"04": "DISCONNECTING",
+}
+
+// unixAcceptConnections is the __SO_ACCEPTCON flag, set on listening unix sockets
+const unixAcceptConnections = 0x10000
+
+// processSocketInodes returns the inodes of the sockets a process has open
+func processSocketInodes(pid int32) (map[string]bool, error) {
+	targets, err := readFdTargets(pid)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]bool)
+	for _, target := range targets {
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			ret[strings.TrimSuffix(inode, "]")] = true
+		}
+	}
+	return ret, nil
+}
+
+// ReadProcessSockets counts a process's sockets by protocol (tcp, tcp6, udp, udp6, unix) and state. The socket tables
+// are read from /proc/<pid>/net, so they are the ones of the process's network namespace.
+func ReadProcessSockets(ctx context.Context, pid int32) (map[string]map[string]int, error) {
+	inodes, err := processSocketInodes(pid)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]map[string]int)
+	count := func(protocol, state string) {
+		if ret[protocol] == nil {
+			ret[protocol] = make(map[string]int)
+		}
+		ret[protocol][state]++
+	}
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		contents, err := utils.ReadFileWithContext(ctx, procPath(pid, "net", protocol))
+		if err != nil {
+			continue
+		}
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		for _, line := range strings.Split(contents, "\n")[1:] {
+			fields := strings.Fields(line)
+			if len(fields) < 10 || !inodes[fields[9]] {
+				continue
+			}
+			state, ok := tcpStates[fields[3]]
+			if !ok {
+				state = fields[3]
+			}
+			// udp reuses the tcp states, an unconnected socket is CLOSE
+			if strings.HasPrefix(protocol, "udp") && state == "CLOSE" {
+				state = "UNCONNECTED"
+			}
+			count(protocol, state)
+		}
+	}
+	if contents, err := utils.ReadFileWithContext(ctx, procPath(pid, "net", "unix")); err == nil {
+		// Num RefCount Protocol Flags Type St Inode Path
+		for _, line := range strings.Split(contents, "\n")[1:] {
+			fields := strings.Fields(line)
+			if len(fields) < 7 || !inodes[fields[6]] {
+				continue
+			}
+			state, ok := unixStates[fields[5]]
+			if !ok {
+				state = fields[5]
+			}
+			if flags, err := strconv.ParseUint(fields[3], 16, 64); err == nil && flags&unixAcceptConnections != 0 {
+				state = "LISTEN"
+			}
+			count("unix", state)
+		}
+	}
+	return ret, nil
+}
+
+// NetDevStats is a row of /proc/net/dev
+type NetDevStats struct {
+	RxBytes   uint64
+	RxPackets uint64
+	RxErrors  uint64
+	RxDropped uint64
+	TxBytes   uint64
+	TxPackets uint64
+	TxErrors  uint64
+	TxDropped uint64
+}
+
+func (s NetDevStats) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"rx_bytes":   s.RxBytes,
+		"rx_packets": s.RxPackets,
+		"rx_errors":  s.RxErrors,
+		"rx_dropped": s.RxDropped,
+		"tx_bytes":   s.TxBytes,
+		"tx_packets": s.TxPackets,
+		"tx_errors":  s.TxErrors,
+		"tx_dropped": s.TxDropped,
+	}
+}
+
+// ReadProcessNetDev reads the interface counters of a process's network namespace, keyed by interface
+func ReadProcessNetDev(ctx context.Context, pid int32) (map[string]NetDevStats, error) {
+	contents, err := utils.ReadFileWithContext(ctx, procPath(pid, "net", "dev"))
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]NetDevStats)
+	for _, line := range strings.Split(contents, "\n") {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		// The first two lines are headers, interfaces have 8 receive and 8 transmit columns
+		if len(fields) < 16 {
+			continue
+		}
+		values := make([]uint64, 16)
+		for i := range values {
+			if values[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid net/dev line %q: %w", line, err)
+			}
+		}
+		ret[strings.TrimSpace(name)] = NetDevStats{
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		}
+	}
+	return ret, nil
+}
+
+// ReadProcessOpenFiles lists what a process's file descriptors point to, sorted by fd. Only targets matching filter
+// are returned, if it is set, and at most limit of them if limit is positive. truncated is set when some were left out
+// because of limit.
+func ReadProcessOpenFiles(pid int32, filter *regexp.Regexp, limit int) (files []string, truncated bool, err error) {
+	targets, err := readFdTargets(pid)
+	if err != nil {
+		return nil, false, err
+	}
+	files = make([]string, 0)
+	for _, target := range targets {
+		if filter != nil && !filter.MatchString(target) {
+			continue
+		}
+		if limit > 0 && len(files) == limit {
+			return files, true, nil
+		}
+		files = append(files, target)
+	}
+	return files, false, nil
+}
+
+// readFdTargets returns the link targets of /proc/<pid>/fd sorted by fd, fds closed while reading are skipped
+func readFdTargets(pid int32) ([]string, error) {
+	entries, err := utils.ReadDir(procPath(pid, "fd"))
+	if err != nil {
+		return nil, err
+	}
+	fds := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		if fd, err := strconv.Atoi(entry.Name()); err == nil {
+			fds = append(fds, fd)
+		}
+	}
+	sort.Ints(fds)
+	targets := make([]string, 0, len(fds))
+	for _, fd := range fds {
+		target, err := utils.Readlink(procPath(pid, "fd", strconv.Itoa(fd)))
+		if err != nil {
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
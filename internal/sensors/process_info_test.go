@@ -0,0 +1,140 @@
+package sensors
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const limitsFixture = `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max file size             unlimited            unlimited            bytes
+Max stack size            8388608              unlimited            bytes
+Max processes             7784                 7784                 processes
+Max open files            1024                 524288               files
+Max nice priority         0                    0
+`
+
+const tcpFixture = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:A2C4 01 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:1F90 0100007F:A2C6 01 00000000:00000000 00:00000000 00000000     0        0 9999 1 0000000000000000 20 4 30 10 -1
+`
+
+const udpFixture = `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  100: 00000000:14E9 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 1003 2 0000000000000000 0
+`
+
+const unixFixture = `Num       RefCount Protocol Flags    Type St Inode Path
+0000000000000000: 00000002 00000000 00010000 0001 01 1004 /tmp/viam-module-1/camera.sock
+0000000000000000: 00000003 00000000 00000000 0001 03 1005
+`
+
+const netDevFixture = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:    1200      12    0    0    0     0          0         0     1200      12    0    0    0     0       0          0
+  eth0: 5000000    4000    1    2    0     0          0        10  2000000    3000    3    4    0     0       0          0
+`
+
+// useProcInfoFixture writes /proc/42 with limits, a few fds and the socket tables of its network namespace
+func useProcInfoFixture(t *testing.T) {
+	t.Helper()
+	root := utilstest.UseFixtureFS(t, map[string]string{
+		"/proc/42/limits":   limitsFixture,
+		"/proc/42/net/tcp":  tcpFixture,
+		"/proc/42/net/udp":  udpFixture,
+		"/proc/42/net/unix": unixFixture,
+		"/proc/42/net/dev":  netDevFixture,
+	})
+	fds := map[string]string{
+		"0":  "/dev/null",
+		"1":  "/var/log/viam/module.log",
+		"2":  "/var/log/viam/module.log",
+		"3":  "socket:[1001]",
+		"4":  "socket:[1002]",
+		"5":  "socket:[1003]",
+		"6":  "socket:[1004]",
+		"7":  "socket:[1005]",
+		"10": "pipe:[2001]",
+		"11": "/var/lib/viam/data.db",
+	}
+	for fd, target := range fds {
+		utilstest.SymlinkFixture(t, root, "/proc/42/fd/"+fd, target)
+	}
+}
+
+func TestReadProcessLimits(t *testing.T) {
+	useProcInfoFixture(t)
+
+	limits, err := ReadProcessLimits(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Len(t, limits, 6)
+	assert.Equal(t, ProcessLimit{Soft: 1024, Hard: 524288, Units: "files"}, limits["nofile"])
+	assert.Equal(t, ProcessLimit{Soft: 8388608, Hard: Unlimited, Units: "bytes"}, limits["stack"])
+	assert.Equal(t, ProcessLimit{Soft: Unlimited, Hard: Unlimited, Units: "seconds"}, limits["cpu"])
+	assert.Equal(t, ProcessLimit{Soft: 0, Hard: 0}, limits["nice"])
+	assert.Equal(t, map[string]interface{}{"soft": int64(0), "hard": int64(0)}, limits["nice"].Map())
+}
+
+func TestReadProcessSockets(t *testing.T) {
+	useProcInfoFixture(t)
+
+	sockets, err := ReadProcessSockets(context.Background(), 42)
+	require.NoError(t, err)
+	// Inode 9999 belongs to another process in the same namespace
+	assert.Equal(t, map[string]map[string]int{
+		"tcp":  {"LISTEN": 1, "ESTABLISHED": 1},
+		"udp":  {"UNCONNECTED": 1},
+		"unix": {"LISTEN": 1, "CONNECTED": 1},
+	}, sockets)
+}
+
+func TestReadProcessNetDev(t *testing.T) {
+	useProcInfoFixture(t)
+
+	interfaces, err := ReadProcessNetDev(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Len(t, interfaces, 2)
+	assert.Equal(t, NetDevStats{
+		RxBytes:   5000000,
+		RxPackets: 4000,
+		RxErrors:  1,
+		RxDropped: 2,
+		TxBytes:   2000000,
+		TxPackets: 3000,
+		TxErrors:  3,
+		TxDropped: 4,
+	}, interfaces["eth0"])
+}
+
+func TestReadProcessOpenFiles(t *testing.T) {
+	useProcInfoFixture(t)
+
+	files, truncated, err := ReadProcessOpenFiles(42, nil, 0)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Len(t, files, 10)
+	assert.Equal(t, "/dev/null", files[0])
+	// fd 10 sorts after fd 7, not after fd 1
+	assert.Equal(t, "/var/lib/viam/data.db", files[9])
+
+	files, truncated, err = ReadProcessOpenFiles(42, regexp.MustCompile(`^/var/`), 0)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, []string{"/var/log/viam/module.log", "/var/log/viam/module.log", "/var/lib/viam/data.db"}, files)
+
+	files, truncated, err = ReadProcessOpenFiles(42, regexp.MustCompile(`^socket:`), 2)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, []string{"socket:[1001]", "socket:[1002]"}, files)
+
+	// Exactly limit matches is not truncated
+	files, truncated, err = ReadProcessOpenFiles(42, regexp.MustCompile(`^/var/`), 3)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Len(t, files, 3)
+}
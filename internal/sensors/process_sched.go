@@ -0,0 +1,251 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// Scheduling policies, by the name chrt uses for them
+const (
+	SchedOther    = "other"
+	SchedFIFO     = "fifo"
+	SchedRR       = "rr"
+	SchedBatch    = "batch"
+	SchedIdle     = "idle"
+	SchedDeadline = "deadline"
+)
+
+// schedPolicies are the SCHED_* values of the policy field of /proc/<pid>/stat
+var schedPolicies = map[int]string{0: SchedOther, 1: SchedFIFO, 2: SchedRR, 3: SchedBatch, 5: SchedIdle, 6: SchedDeadline}
+
+// IO scheduling classes, by the name ionice prints. A thread that never had its class set is none, the kernel derives
+// a best effort level from its nice.
+const (
+	IOClassNone       = "none"
+	IOClassRealtime   = "realtime"
+	IOClassBestEffort = "best-effort"
+	IOClassIdle       = "idle"
+)
+
+// ThreadSchedule is how the kernel schedules a thread, CPUs is its affinity
+type ThreadSchedule struct {
+	TID        int32
+	Name       string
+	CPUs       []int
+	Nice       int
+	Policy     string
+	RTPriority int
+}
+
+func (s *ThreadSchedule) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"tid":         s.TID,
+		"name":        s.Name,
+		"cpus":        FormatCPUList(s.CPUs),
+		"nice":        s.Nice,
+		"policy":      s.Policy,
+		"rt_priority": s.RTPriority,
+	}
+}
+
+// ReadProcessSchedules reads the schedule of every thread of a process, ordered by TID. Threads that exit while being
+// read are left out.
+func ReadProcessSchedules(ctx context.Context, pid int32) ([]ThreadSchedule, error) {
+	entries, err := utils.ReadDir(procPath(pid, "task"))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]ThreadSchedule, 0, len(entries))
+	for _, entry := range entries {
+		tid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		schedule, err := ReadThreadSchedule(ctx, pid, int32(tid))
+		if err != nil {
+			continue
+		}
+		ret = append(ret, schedule)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].TID < ret[j].TID })
+	return ret, nil
+}
+
+// ReadThreadSchedule reads the schedule of a thread of pid from its stat and the Cpus_allowed_list of its status
+func ReadThreadSchedule(ctx context.Context, pid, tid int32) (ThreadSchedule, error) {
+	dir := strconv.Itoa(int(tid))
+	contents, err := utils.ReadFileWithContext(ctx, procPath(pid, "task", dir, "stat"))
+	if err != nil {
+		return ThreadSchedule{}, err
+	}
+	schedule, err := parseThreadSchedule(contents)
+	if err != nil {
+		return ThreadSchedule{}, err
+	}
+	schedule.TID = tid
+	status, err := utils.ReadFileWithContext(ctx, procPath(pid, "task", dir, "status"))
+	if err != nil {
+		return ThreadSchedule{}, err
+	}
+	for _, line := range strings.Split(status, "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok && key == "Cpus_allowed_list" {
+			if schedule.CPUs, err = ParseCPUList(strings.TrimSpace(value)); err != nil {
+				return ThreadSchedule{}, err
+			}
+			break
+		}
+	}
+	return schedule, nil
+}
+
+// parseThreadSchedule parses the name, nice, rt_priority and policy of a stat line
+func parseThreadSchedule(contents string) (ThreadSchedule, error) {
+	open := strings.Index(contents, "(")
+	closing := strings.LastIndex(contents, ")")
+	if open < 0 || closing < open {
+		return ThreadSchedule{}, fmt.Errorf("invalid stat %q", contents)
+	}
+	// The fields after the name start at state, the 3rd field, nice is the 19th, rt_priority the 40th and policy the 41st
+	fields := strings.Fields(contents[closing+1:])
+	if len(fields) < 39 {
+		return ThreadSchedule{}, fmt.Errorf("invalid stat %q", contents)
+	}
+	schedule := ThreadSchedule{Name: readableThreadName(contents[open+1 : closing])}
+	var err error
+	if schedule.Nice, err = strconv.Atoi(fields[16]); err != nil {
+		return ThreadSchedule{}, fmt.Errorf("invalid nice in stat %q: %w", contents, err)
+	}
+	if schedule.RTPriority, err = strconv.Atoi(fields[37]); err != nil {
+		return ThreadSchedule{}, fmt.Errorf("invalid rt_priority in stat %q: %w", contents, err)
+	}
+	policy, err := strconv.Atoi(fields[38])
+	if err != nil {
+		return ThreadSchedule{}, fmt.Errorf("invalid policy in stat %q: %w", contents, err)
+	}
+	if schedule.Policy = schedPolicies[policy]; schedule.Policy == "" {
+		schedule.Policy = strconv.Itoa(policy)
+	}
+	return schedule, nil
+}
+
+// maxCPUs is CPU_SETSIZE, the number of CPUs sched_setaffinity takes
+const maxCPUs = 1024
+
+// ParseCPUList parses a CPU list like 0-3,6, the format of Cpus_allowed_list and taskset -c. The CPUs are sorted
+// and unique, and must be below CPU_SETSIZE.
+func ParseCPUList(list string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid CPU list %q", list)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("invalid CPU list %q", list)
+			}
+		}
+		if end >= maxCPUs {
+			return nil, fmt.Errorf("invalid CPU list %q, CPUs must be below %d", list, maxCPUs)
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			seen[cpu] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("invalid CPU list %q", list)
+	}
+	cpus := make([]int, 0, len(seen))
+	for cpu := range seen {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// FormatCPUList formats sorted CPUs as a CPU list, collapsing consecutive CPUs into ranges
+func FormatCPUList(cpus []int) string {
+	parts := make([]string, 0, len(cpus))
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// IOPriority is the IO scheduling class of a thread and its level within the class, from 0, the highest, to 7. Idle
+// has no levels.
+type IOPriority struct {
+	Class string
+	Level int
+}
+
+func (p IOPriority) String() string {
+	if p.Class == IOClassIdle {
+		return p.Class
+	}
+	return fmt.Sprintf("%s: prio %d", p.Class, p.Level)
+}
+
+// ioClasses are the IO scheduling classes by their IOPRIO_CLASS_* value
+var ioClasses = []string{IOClassNone, IOClassRealtime, IOClassBestEffort, IOClassIdle}
+
+// The class of an ioprio_get and ioprio_set value is in its top bits, the level in the 13 bits below
+const (
+	ioPrioClassShift = 13
+	ioPrioLevelMask  = 1<<ioPrioClassShift - 1
+)
+
+// parseIOPriority decodes a value returned by ioprio_get
+func parseIOPriority(value int) (IOPriority, error) {
+	class := value >> ioPrioClassShift
+	if class < 0 || class >= len(ioClasses) {
+		return IOPriority{}, fmt.Errorf("invalid IO priority %#x", value)
+	}
+	if ioClasses[class] == IOClassIdle {
+		return IOPriority{Class: IOClassIdle}, nil
+	}
+	return IOPriority{Class: ioClasses[class], Level: value & ioPrioLevelMask}, nil
+}
+
+// ioPriorityValue encodes p for ioprio_set
+func ioPriorityValue(p IOPriority) (int, error) {
+	class := slices.Index(ioClasses, p.Class)
+	if class < 0 {
+		return 0, fmt.Errorf("unknown IO class %s", p.Class)
+	}
+	if p.Level < 0 || p.Level > 7 {
+		return 0, fmt.Errorf("invalid IO level %d", p.Level)
+	}
+	return class<<ioPrioClassShift | p.Level, nil
+}
+
+// schedPolicyValue is the SCHED_* value of a policy
+func schedPolicyValue(policy string) (int, error) {
+	for value, name := range schedPolicies {
+		if name == policy {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown policy %s", policy)
+}
@@ -0,0 +1,59 @@
+package sensors
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ioprio_get and ioprio_set take a thread ID with IOPRIO_WHO_PROCESS
+const ioPrioWhoProcess = 1
+
+// ReadIOPriority reads the IO priority of a thread with ioprio_get, it is not in /proc
+func ReadIOPriority(tid int32) (IOPriority, error) {
+	value, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioPrioWhoProcess, uintptr(tid), 0)
+	if errno != 0 {
+		return IOPriority{}, errno
+	}
+	return parseIOPriority(int(value))
+}
+
+// SetIOPriority sets the IO priority of a thread with ioprio_set. The realtime class needs CAP_SYS_ADMIN.
+func SetIOPriority(tid int32, priority IOPriority) error {
+	value, err := ioPriorityValue(priority)
+	if err != nil {
+		return err
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioPrioWhoProcess, uintptr(tid), uintptr(value)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// SetThreadAffinity pins a thread to cpus
+func SetThreadAffinity(tid int32, cpus []int) error {
+	var set unix.CPUSet
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+	return unix.SchedSetaffinity(int(tid), &set)
+}
+
+// SetThreadNice sets the nice of a thread. Lowering it needs CAP_SYS_NICE.
+func SetThreadNice(tid int32, nice int) error {
+	return unix.Setpriority(unix.PRIO_PROCESS, int(tid), nice)
+}
+
+// SetThreadPolicy sets the scheduling policy of a thread with sched_setscheduler, which unlike sched_setattr leaves
+// its nice alone. The fifo and rr policies need CAP_SYS_NICE.
+func SetThreadPolicy(tid int32, policy string, rtPriority int) error {
+	value, err := schedPolicyValue(policy)
+	if err != nil {
+		return err
+	}
+	param := struct{ priority int32 }{priority: int32(rtPriority)}
+	if _, _, errno := unix.Syscall(unix.SYS_SCHED_SETSCHEDULER, uintptr(tid), uintptr(value), uintptr(unsafe.Pointer(&param))); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package sensors
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// TestSetThreadSchedule schedules a thread of the test with settings any user may set. The goroutine stays locked to
+// the thread, so the runtime discards the thread instead of running other goroutines on it.
+func TestSetThreadSchedule(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runtime.LockOSThread()
+		pid, tid := int32(os.Getpid()), int32(unix.Gettid())
+		before, err := ReadThreadSchedule(context.Background(), pid, tid)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.NoError(t, SetThreadAffinity(tid, before.CPUs[:1]))
+		assert.NoError(t, SetThreadNice(tid, 19))
+		assert.NoError(t, SetIOPriority(tid, IOPriority{Class: IOClassIdle}))
+		assert.NoError(t, SetThreadPolicy(tid, SchedBatch, 0))
+		// The kernel rejects an empty affinity
+		assert.Error(t, SetThreadAffinity(tid, nil))
+
+		after, err := ReadThreadSchedule(context.Background(), pid, tid)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, before.CPUs[:1], after.CPUs)
+		assert.Equal(t, 19, after.Nice)
+		assert.Equal(t, SchedBatch, after.Policy)
+		io, err := ReadIOPriority(tid)
+		assert.NoError(t, err)
+		assert.Equal(t, IOPriority{Class: IOClassIdle}, io)
+	}()
+	<-done
+}
+
+func TestMaxCPUsIsCPUSetSize(t *testing.T) {
+	var set unix.CPUSet
+	assert.Equal(t, int(unsafe.Sizeof(set))*8, maxCPUs)
+}
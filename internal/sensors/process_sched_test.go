@@ -0,0 +1,75 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := ParseCPUList("4-7,0, 2,5")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 4, 5, 6, 7}, cpus)
+	assert.Equal(t, "0,2,4-7", FormatCPUList(cpus))
+	assert.Equal(t, "3", FormatCPUList([]int{3}))
+	assert.Equal(t, "", FormatCPUList(nil))
+
+	cpus, err = ParseCPUList("1023")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1023}, cpus)
+
+	// ranges are checked before they are expanded, so a huge one fails straight away
+	for _, list := range []string{"", "a", "3-1", "-1", "1-", "1024", "0-2147483647", "0,4-9223372036854775807"} {
+		_, err := ParseCPUList(list)
+		assert.Error(t, err, list)
+	}
+}
+
+func TestReadProcessSchedules(t *testing.T) {
+	// nice is the 19th field, rt_priority the 40th and policy the 41st
+	stat := "%d (%s) S 1 42 42 0 -1 4194560 1000 0 0 0 10 5 0 0 %d %d 2 0 100 123456789 2000 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 0 %d %d 0 0 0 0 0 0 0 0 0 0 0\n"
+	utilstest.UseFixtureFS(t, map[string]string{
+		"/proc/42/task/42/stat":   fmt.Sprintf(stat, 42, "perception", 10, -10, 0, 0),
+		"/proc/42/task/42/status": "Name:\tperception\nCpus_allowed:\tf0\nCpus_allowed_list:\t4-7\n",
+		"/proc/42/task/43/stat":   fmt.Sprintf(stat, 43, "infer (gpu)", -51, 0, 50, 1),
+		"/proc/42/task/43/status": "Name:\tinfer\nCpus_allowed_list:\t0-3,6\n",
+	})
+
+	schedules, err := ReadProcessSchedules(context.Background(), 42)
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	assert.Equal(t, ThreadSchedule{TID: 42, Name: "perception", CPUs: []int{4, 5, 6, 7}, Nice: -10, Policy: SchedOther}, schedules[0])
+	assert.Equal(t, ThreadSchedule{TID: 43, Name: "infer (gpu)", CPUs: []int{0, 1, 2, 3, 6}, Policy: SchedFIFO, RTPriority: 50}, schedules[1])
+	assert.Equal(t, "0-3,6", schedules[1].Map()["cpus"])
+
+	_, err = ReadProcessSchedules(context.Background(), 7)
+	assert.Error(t, err)
+}
+
+func TestIOPriorityValue(t *testing.T) {
+	for value, expected := range map[int]IOPriority{
+		2<<13 | 4: {Class: IOClassBestEffort, Level: 4},
+		1 << 13:   {Class: IOClassRealtime, Level: 0},
+		4:         {Class: IOClassNone, Level: 4},
+		3 << 13:   {Class: IOClassIdle},
+	} {
+		priority, err := parseIOPriority(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, priority, value)
+		encoded, err := ioPriorityValue(priority)
+		require.NoError(t, err, value)
+		assert.Equal(t, value, encoded)
+	}
+	_, err := parseIOPriority(4 << 13)
+	assert.Error(t, err)
+	_, err = ioPriorityValue(IOPriority{Class: "fast"})
+	assert.Error(t, err)
+	_, err = ioPriorityValue(IOPriority{Class: IOClassBestEffort, Level: 8})
+	assert.Error(t, err)
+	assert.Equal(t, "best-effort: prio 2", IOPriority{Class: IOClassBestEffort, Level: 2}.String())
+	assert.Equal(t, "idle", IOPriority{Class: IOClassIdle}.String())
+}
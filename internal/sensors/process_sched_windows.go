@@ -0,0 +1,25 @@
+package sensors
+
+import (
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+func ReadIOPriority(tid int32) (IOPriority, error) {
+	return IOPriority{}, utils.ErrPlatformNotSupported
+}
+
+func SetIOPriority(tid int32, priority IOPriority) error {
+	return utils.ErrPlatformNotSupported
+}
+
+func SetThreadAffinity(tid int32, cpus []int) error {
+	return utils.ErrPlatformNotSupported
+}
+
+func SetThreadNice(tid int32, nice int) error {
+	return utils.ErrPlatformNotSupported
+}
+
+func SetThreadPolicy(tid int32, policy string, rtPriority int) error {
+	return utils.ErrPlatformNotSupported
+}
@@ -0,0 +1,375 @@
+package sensors
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+var (
+	ErrEmptyProcessSelector = errors.New("process selector must set at least one field")
+)
+
+// maxCommLength is how much of a process name the kernel keeps in /proc/<pid>/comm
+const maxCommLength = 15
+
+// ProcessSelector picks the processes to monitor. Every field that is set must match, All requires every nested
+// selector to match and Any requires at least one to, so selectors can be combined into AND/OR expressions.
+type ProcessSelector struct {
+	// Name matches /proc/<pid>/comm, or the basename of argv[0] for names longer than the kernel's 15 characters
+	Name string `json:"name,omitempty"`
+	// CmdlineRegex matches the full command line, with the arguments separated by spaces
+	CmdlineRegex string `json:"cmdline_regex,omitempty"`
+	// ExecutablePath matches /proc/<pid>/exe or argv[0]. A path that is a symlink, ex: /usr/local/bin/viam-server to a
+	// versioned binary, also matches the binary it points to.
+	ExecutablePath string `json:"executable_path,omitempty"`
+	// User matches the name or uid of the real user
+	User string `json:"user,omitempty"`
+	// ParentPID matches the parent's PID
+	ParentPID int32 `json:"parent_pid,omitempty"`
+	// ParentName matches the parent's name, like Name
+	ParentName string `json:"parent_name,omitempty"`
+	// AncestorName matches the name of any ancestor, like Name, ex: every process started by viam-server
+	AncestorName string `json:"ancestor_name,omitempty"`
+	// Cgroup matches processes in a cgroup v2 group or its children, either a path relative to /sys/fs/cgroup or a
+	// systemd unit name like viam-server.service
+	Cgroup string            `json:"cgroup,omitempty"`
+	All    []ProcessSelector `json:"all,omitempty"`
+	Any    []ProcessSelector `json:"any,omitempty"`
+}
+
+func (s *ProcessSelector) isEmpty() bool {
+	return s.Name == "" && s.CmdlineRegex == "" && s.ExecutablePath == "" && s.User == "" && s.ParentPID == 0 &&
+		s.ParentName == "" && s.AncestorName == "" && s.Cgroup == "" && len(s.All) == 0 && len(s.Any) == 0
+}
+
+// Validate checks the selector and every nested selector can be compiled
+func (s *ProcessSelector) Validate() error {
+	_, err := s.compile()
+	return err
+}
+
+// String describes the selector for logs
+func (s *ProcessSelector) String() string {
+	parts := make([]string, 0)
+	add := func(key, value string) {
+		if value != "" {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	add("name", s.Name)
+	add("cmdline_regex", s.CmdlineRegex)
+	add("executable_path", s.ExecutablePath)
+	add("user", s.User)
+	if s.ParentPID != 0 {
+		add("parent_pid", strconv.Itoa(int(s.ParentPID)))
+	}
+	add("parent_name", s.ParentName)
+	add("ancestor_name", s.AncestorName)
+	add("cgroup", s.Cgroup)
+	for _, nested := range s.All {
+		parts = append(parts, "all("+nested.String()+")")
+	}
+	if len(s.Any) > 0 {
+		alternatives := make([]string, 0, len(s.Any))
+		for _, nested := range s.Any {
+			alternatives = append(alternatives, nested.String())
+		}
+		parts = append(parts, "any("+strings.Join(alternatives, " | ")+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// processMatcher is a compiled ProcessSelector
+type processMatcher struct {
+	selector *ProcessSelector
+	cmdline  *regexp.Regexp
+	uid      string   // User resolved to a uid, empty if it could not be looked up
+	exes     []string // ExecutablePath and, if it is a symlink, what it points to
+	all      []*processMatcher
+	any      []*processMatcher
+}
+
+func (s *ProcessSelector) compile() (*processMatcher, error) {
+	if s.isEmpty() {
+		return nil, ErrEmptyProcessSelector
+	}
+	m := &processMatcher{selector: s}
+	if s.CmdlineRegex != "" {
+		regex, err := regexp.Compile(s.CmdlineRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cmdline_regex %q: %w", s.CmdlineRegex, err)
+		}
+		m.cmdline = regex
+	}
+	if s.ExecutablePath != "" {
+		m.exes = []string{s.ExecutablePath}
+		if resolved, err := utils.EvalSymlinks(s.ExecutablePath); err == nil && resolved != s.ExecutablePath {
+			m.exes = append(m.exes, resolved)
+		}
+	}
+	if s.User != "" {
+		if _, err := strconv.Atoi(s.User); err == nil {
+			m.uid = s.User
+		} else if u, err := user.Lookup(s.User); err == nil {
+			m.uid = u.Uid
+		}
+	}
+	for i := range s.All {
+		nested, err := s.All[i].compile()
+		if err != nil {
+			return nil, err
+		}
+		m.all = append(m.all, nested)
+	}
+	for i := range s.Any {
+		nested, err := s.Any[i].compile()
+		if err != nil {
+			return nil, err
+		}
+		m.any = append(m.any, nested)
+	}
+	return m, nil
+}
+
+func (m *processMatcher) matches(proc *procAttributes) bool {
+	s := m.selector
+	if s.Name != "" && !proc.hasName(s.Name) {
+		return false
+	}
+	if m.cmdline != nil && !m.cmdline.MatchString(strings.Join(proc.cmdline(), " ")) {
+		return false
+	}
+	if s.ExecutablePath != "" && !proc.hasExecutable(m.exes) {
+		return false
+	}
+	if s.User != "" && !proc.hasUser(m.uid, s.User) {
+		return false
+	}
+	if s.ParentPID != 0 && proc.ppid() != s.ParentPID {
+		return false
+	}
+	if s.ParentName != "" {
+		parent := proc.parent()
+		if parent == nil || !parent.hasName(s.ParentName) {
+			return false
+		}
+	}
+	if s.AncestorName != "" && !proc.hasAncestor(s.AncestorName) {
+		return false
+	}
+	if s.Cgroup != "" && !proc.inCgroup(s.Cgroup) {
+		return false
+	}
+	for _, nested := range m.all {
+		if !nested.matches(proc) {
+			return false
+		}
+	}
+	if len(m.any) > 0 {
+		for _, nested := range m.any {
+			if nested.matches(proc) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// procTable lazily reads /proc/<pid> for the processes a sync looks at, so walking ancestors of many processes only
+// reads each parent once
+type procTable map[int32]*procAttributes
+
+func (t procTable) get(pid int32) *procAttributes {
+	if proc, ok := t[pid]; ok {
+		return proc
+	}
+	proc := &procAttributes{pid: pid, table: t}
+	t[pid] = proc
+	return proc
+}
+
+// procAttributes reads the parts of /proc/<pid> a selector needs, each file is read at most once
+type procAttributes struct {
+	pid   int32
+	table procTable
+
+	commRead    bool
+	commValue   string
+	argsRead    bool
+	argsValue   []string
+	statusRead  bool
+	uid         string
+	ppidValue   int32
+	cgroupRead  bool
+	cgroupValue string
+}
+
+func (p *procAttributes) path(name string) string {
+	return procPath(p.pid, name)
+}
+
+func (p *procAttributes) comm() string {
+	if !p.commRead {
+		p.commRead = true
+		if data, err := utils.ReadFile(p.path("comm")); err == nil {
+			p.commValue = strings.TrimSuffix(string(data), "\n")
+		}
+	}
+	return p.commValue
+}
+
+func (p *procAttributes) cmdline() []string {
+	if !p.argsRead {
+		p.argsRead = true
+		if data, err := utils.ReadFile(p.path("cmdline")); err == nil {
+			p.argsValue = strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+		}
+	}
+	return p.argsValue
+}
+
+func (p *procAttributes) argv0() string {
+	args := p.cmdline()
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// name is what the process is reported as, comm, or the basename of argv[0] if comm was truncated
+func (p *procAttributes) name() string {
+	comm := p.comm()
+	if len(comm) < maxCommLength {
+		return comm
+	}
+	if argv0 := filepath.Base(p.argv0()); strings.HasPrefix(argv0, comm) {
+		return argv0
+	}
+	return comm
+}
+
+func (p *procAttributes) hasName(name string) bool {
+	if len(name) <= maxCommLength {
+		return p.comm() == name
+	}
+	argv0 := p.argv0()
+	return argv0 != "" && (filepath.Base(argv0) == name || argv0 == name)
+}
+
+// deletedExeSuffix is appended to /proc/<pid>/exe when the executable was unlinked
+const deletedExeSuffix = " (deleted)"
+
+// hasExecutable matches exe against paths. argv[0] is matched too, exe is only readable by the owner and root, and a
+// process started through a symlink that was pointed at a new version since still has the old binary as its exe.
+func (p *procAttributes) hasExecutable(paths []string) bool {
+	if exe, err := utils.Readlink(p.path("exe")); err == nil {
+		// The link of a binary that was replaced while running, ex: a module upgraded in place, ends in (deleted)
+		if slices.Contains(paths, strings.TrimSuffix(exe, deletedExeSuffix)) {
+			return true
+		}
+	}
+	return p.argv0() == paths[0]
+}
+
+func (p *procAttributes) readStatus() {
+	if p.statusRead {
+		return
+	}
+	p.statusRead = true
+	data, err := utils.ReadFile(p.path("status"))
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Uid":
+			p.uid = fields[0]
+		case "PPid":
+			if ppid, err := strconv.ParseInt(fields[0], 10, 32); err == nil {
+				p.ppidValue = int32(ppid)
+			}
+		}
+	}
+}
+
+func (p *procAttributes) ppid() int32 {
+	p.readStatus()
+	return p.ppidValue
+}
+
+func (p *procAttributes) parent() *procAttributes {
+	ppid := p.ppid()
+	if ppid <= 0 {
+		return nil
+	}
+	return p.table.get(ppid)
+}
+
+func (p *procAttributes) hasAncestor(name string) bool {
+	seen := map[int32]bool{p.pid: true}
+	for parent := p.parent(); parent != nil && !seen[parent.pid]; parent = parent.parent() {
+		if parent.hasName(name) {
+			return true
+		}
+		seen[parent.pid] = true
+	}
+	return false
+}
+
+// hasUser matches uid, or name if it could not be resolved to a uid when the selector was compiled
+func (p *procAttributes) hasUser(uid, name string) bool {
+	p.readStatus()
+	if p.uid == "" {
+		return false
+	}
+	if uid != "" {
+		return p.uid == uid
+	}
+	u, err := user.LookupId(p.uid)
+	return err == nil && u.Username == name
+}
+
+func (p *procAttributes) inCgroup(cgroup string) bool {
+	if !p.cgroupRead {
+		p.cgroupRead = true
+		data, err := utils.ReadFile(p.path("cgroup"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if path, ok := strings.CutPrefix(strings.TrimSpace(line), "0::"); ok {
+					p.cgroupValue = path
+				}
+			}
+		}
+	}
+	if p.cgroupValue == "" {
+		return false
+	}
+	if strings.Contains(cgroup, "/") {
+		cgroup = "/" + strings.Trim(cgroup, "/")
+		return p.cgroupValue == cgroup || strings.HasPrefix(p.cgroupValue, cgroup+"/")
+	}
+	// A unit name matches the unit's group and everything below it
+	for _, part := range strings.Split(p.cgroupValue, "/") {
+		if part == cgroup {
+			return true
+		}
+	}
+	return false
+}
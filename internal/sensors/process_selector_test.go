@@ -0,0 +1,117 @@
+package sensors
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProc struct {
+	pid     int32
+	ppid    int32
+	uid     int
+	comm    string
+	exe     string
+	cmdline []string
+	cgroup  string
+}
+
+// useProcFixture writes a /proc tree with systemd, viam-server, two of its modules and an unrelated python process.
+// The hwmonitor module was upgraded in place, so its exe link is marked deleted. viam-server was started through a
+// symlink that has since been pointed at a newer version, and python3 links to python3.11.
+func useProcFixture(t *testing.T) {
+	t.Helper()
+	procs := []fakeProc{
+		{pid: 1, ppid: 0, uid: 0, comm: "systemd", exe: "/usr/lib/systemd/systemd", cmdline: []string{"/sbin/init"}, cgroup: "/init.scope"},
+		{pid: 100, ppid: 1, uid: 0, comm: "viam-server", exe: "/opt/viam/bin/viam-server-0.50.0", cmdline: []string{"/usr/local/bin/viam-server", "-config", "/etc/viam.json"}, cgroup: "/system.slice/viam-server.service"},
+		{pid: 200, ppid: 100, uid: 1000, comm: "python3", exe: "/usr/bin/python3.11", cmdline: []string{"python3", "main.py", "/tmp/viam-module-1/camera.sock"}, cgroup: "/system.slice/viam-server.service"},
+		{pid: 300, ppid: 100, uid: 0, comm: "rinzlerlabs-sbc", exe: "/opt/module/bin/rinzlerlabs-sbc-hwmonitor (deleted)", cmdline: []string{"/opt/module/bin/rinzlerlabs-sbc-hwmonitor", "/tmp/viam-module-1/hwmonitor.sock"}, cgroup: "/system.slice/viam-server.service"},
+		{pid: 400, ppid: 1, uid: 1000, comm: "python3", exe: "/usr/bin/python3.11", cmdline: []string{"python3", "-m", "http.server"}, cgroup: "/user.slice/user-1000.slice/session-1.scope"},
+	}
+	files := make(map[string]string)
+	for _, proc := range procs {
+		dir := "/proc/" + strconv.Itoa(int(proc.pid)) + "/"
+		files[dir+"comm"] = proc.comm + "\n"
+		files[dir+"cmdline"] = strings.Join(proc.cmdline, "\x00") + "\x00"
+		files[dir+"status"] = "Name:\t" + proc.comm + "\nPPid:\t" + strconv.Itoa(int(proc.ppid)) + "\nUid:\t" + strings.Repeat(strconv.Itoa(proc.uid)+"\t", 4) + "\n"
+		files[dir+"cgroup"] = "0::" + proc.cgroup + "\n"
+	}
+	files["/opt/viam/bin/viam-server-0.51.0"] = ""
+	files["/usr/bin/python3.11"] = ""
+	root := utilstest.UseFixtureFS(t, files)
+	utilstest.SymlinkFixture(t, root, "/usr/local/bin/viam-server", "/opt/viam/bin/viam-server-0.51.0")
+	utilstest.SymlinkFixture(t, root, "/usr/bin/python3", "python3.11")
+	for _, proc := range procs {
+		utilstest.SymlinkFixture(t, root, "/proc/"+strconv.Itoa(int(proc.pid))+"/exe", proc.exe)
+	}
+}
+
+func selectPids(t *testing.T, selector ProcessSelector) []int32 {
+	t.Helper()
+	matcher, err := selector.compile()
+	require.NoError(t, err)
+	table := make(procTable)
+	pids := make([]int32, 0)
+	for _, pid := range []int32{1, 100, 200, 300, 400} {
+		if matcher.matches(table.get(pid)) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+func TestProcessSelector(t *testing.T) {
+	useProcFixture(t)
+
+	tests := []struct {
+		name     string
+		selector ProcessSelector
+		expected []int32
+	}{
+		{"name", ProcessSelector{Name: "python3"}, []int32{200, 400}},
+		{"long name uses argv[0]", ProcessSelector{Name: "rinzlerlabs-sbc-hwmonitor"}, []int32{300}},
+		{"cmdline regex", ProcessSelector{CmdlineRegex: `hwmonitor\.sock$`}, []int32{300}},
+		{"exe", ProcessSelector{ExecutablePath: "/usr/bin/python3.11"}, []int32{200, 400}},
+		{"exe replaced while running", ProcessSelector{ExecutablePath: "/opt/module/bin/rinzlerlabs-sbc-hwmonitor"}, []int32{300}},
+		{"exe through a symlink", ProcessSelector{ExecutablePath: "/usr/bin/python3"}, []int32{200, 400}},
+		{"symlink pointed at a new version since", ProcessSelector{ExecutablePath: "/usr/local/bin/viam-server"}, []int32{100}},
+		{"exe of a versioned binary", ProcessSelector{ExecutablePath: "/opt/viam/bin/viam-server-0.50.0"}, []int32{100}},
+		{"uid", ProcessSelector{User: "1000"}, []int32{200, 400}},
+		{"user name", ProcessSelector{User: "root"}, []int32{1, 100, 300}},
+		{"parent pid", ProcessSelector{ParentPID: 1}, []int32{100, 400}},
+		{"parent name", ProcessSelector{ParentName: "viam-server"}, []int32{200, 300}},
+		{"ancestor name", ProcessSelector{AncestorName: "systemd"}, []int32{100, 200, 300, 400}},
+		{"cgroup unit", ProcessSelector{Cgroup: "viam-server.service"}, []int32{100, 200, 300}},
+		{"cgroup path", ProcessSelector{Cgroup: "/user.slice"}, []int32{400}},
+		{"python under viam-server", ProcessSelector{Name: "python3", AncestorName: "viam-server"}, []int32{200}},
+		{"any", ProcessSelector{Any: []ProcessSelector{{Name: "viam-server"}, {CmdlineRegex: "camera"}}}, []int32{100, 200}},
+		{"all with any", ProcessSelector{
+			All: []ProcessSelector{{Cgroup: "viam-server.service"}},
+			Any: []ProcessSelector{{User: "1000"}, {ParentPID: 100}},
+		}, []int32{200, 300}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, selectPids(t, tt.selector))
+		})
+	}
+}
+
+func TestProcessSelectorValidate(t *testing.T) {
+	assert.NoError(t, (&ProcessSelector{Name: "viam-server"}).Validate())
+	assert.ErrorIs(t, (&ProcessSelector{}).Validate(), ErrEmptyProcessSelector)
+	assert.ErrorIs(t, (&ProcessSelector{Any: []ProcessSelector{{}}}).Validate(), ErrEmptyProcessSelector)
+	assert.Error(t, (&ProcessSelector{CmdlineRegex: "("}).Validate())
+}
+
+func TestProcessName(t *testing.T) {
+	useProcFixture(t)
+	table := make(procTable)
+
+	assert.Equal(t, "python3", table.get(200).name())
+	assert.Equal(t, "rinzlerlabs-sbc-hwmonitor", table.get(300).name())
+}
@@ -0,0 +1,151 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// userHZ is the unit of utime and stime in /proc/<pid>/stat, sysconf(_SC_CLK_TCK) is 100 on every architecture Linux
+// runs on
+const userHZ = 100
+
+// ThreadStats is a line of /proc/<pid>/task/<tid>/stat. UTime and STime are in clock ticks, Processor is the CPU the
+// thread last ran on.
+type ThreadStats struct {
+	TID       int32
+	Name      string
+	UTime     uint64
+	STime     uint64
+	Processor int
+}
+
+// ThreadSample is every thread of a process at a point in time, keyed by TID
+type ThreadSample struct {
+	Timestamp time.Time
+	PID       int32
+	Threads   map[int32]ThreadStats
+}
+
+// ReadProcessThreads reads the stat of every thread of a process. Threads that exit while being read are left out.
+func ReadProcessThreads(ctx context.Context, pid int32) (*ThreadSample, error) {
+	entries, err := utils.ReadDir(procPath(pid, "task"))
+	if err != nil {
+		return nil, err
+	}
+	sample := &ThreadSample{Timestamp: time.Now(), PID: pid, Threads: make(map[int32]ThreadStats, len(entries))}
+	for _, entry := range entries {
+		tid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		contents, err := utils.ReadFileWithContext(ctx, procPath(pid, "task", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		stats, err := parseThreadStat(contents)
+		if err != nil {
+			return nil, err
+		}
+		stats.TID = int32(tid)
+		sample.Threads[stats.TID] = stats
+	}
+	return sample, nil
+}
+
+// parseThreadStat parses a stat line. The name is between the first ( and the last ), it can contain spaces and
+// parentheses of its own.
+func parseThreadStat(contents string) (ThreadStats, error) {
+	open := strings.Index(contents, "(")
+	closing := strings.LastIndex(contents, ")")
+	if open < 0 || closing < open {
+		return ThreadStats{}, fmt.Errorf("invalid stat %q", contents)
+	}
+	// The fields after the name start at state, the 3rd field, utime is the 14th, stime the 15th and processor the 39th
+	fields := strings.Fields(contents[closing+1:])
+	if len(fields) < 37 {
+		return ThreadStats{}, fmt.Errorf("invalid stat %q", contents)
+	}
+	stats := ThreadStats{Name: readableThreadName(contents[open+1 : closing])}
+	var err error
+	if stats.UTime, err = strconv.ParseUint(fields[11], 10, 64); err != nil {
+		return ThreadStats{}, fmt.Errorf("invalid utime in stat %q: %w", contents, err)
+	}
+	if stats.STime, err = strconv.ParseUint(fields[12], 10, 64); err != nil {
+		return ThreadStats{}, fmt.Errorf("invalid stime in stat %q: %w", contents, err)
+	}
+	if stats.Processor, err = strconv.Atoi(fields[36]); err != nil {
+		return ThreadStats{}, fmt.Errorf("invalid processor in stat %q: %w", contents, err)
+	}
+	return stats, nil
+}
+
+// readableThreadName replaces control characters, names are set by the program and can contain anything but NUL
+func readableThreadName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return '?'
+		}
+		return r
+	}, strings.TrimSpace(name))
+}
+
+// ThreadUsage is the CPU usage of a thread between two samples, in percent of one core. Named is false for threads
+// that kept the name of the process, like the threads of the Go runtime and Python threads that were not named with
+// pthread_setname_np.
+type ThreadUsage struct {
+	TID        int32
+	Name       string
+	Named      bool
+	Processor  int
+	CPUPercent float64
+}
+
+func (u *ThreadUsage) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"tid":         u.TID,
+		"name":        u.Name,
+		"named":       u.Named,
+		"last_cpu":    u.Processor,
+		"cpu_percent": u.CPUPercent,
+	}
+}
+
+// CalculateTopThreads returns the n threads that used the most CPU between prev and curr, busiest first. prev may be
+// nil on the first sample, threads that started since prev have no usage yet.
+func CalculateTopThreads(prev, curr *ThreadSample, n int) []*ThreadUsage {
+	seconds := 0.0
+	if prev != nil {
+		seconds = curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	}
+	processName := curr.Threads[curr.PID].Name
+	all := make([]*ThreadUsage, 0, len(curr.Threads))
+	for tid, stats := range curr.Threads {
+		usage := &ThreadUsage{
+			TID:       tid,
+			Name:      stats.Name,
+			Named:     tid == curr.PID || stats.Name != processName,
+			Processor: stats.Processor,
+		}
+		if prev != nil && seconds > 0 {
+			if last, ok := prev.Threads[tid]; ok {
+				ticks := counterDelta(last.UTime+last.STime, stats.UTime+stats.STime)
+				usage.CPUPercent = utils.RoundValue(float64(ticks)/userHZ/seconds*100, 2)
+			}
+		}
+		all = append(all, usage)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].CPUPercent != all[j].CPUPercent {
+			return all[i].CPUPercent > all[j].CPUPercent
+		}
+		return all[i].TID < all[j].TID
+	})
+	return all[:min(n, len(all))]
+}
@@ -0,0 +1,97 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeThread struct {
+	tid       int32
+	name      string
+	utime     uint64
+	stime     uint64
+	processor int
+}
+
+func threadStat(thread fakeThread) string {
+	return fmt.Sprintf("%d (%s) S 1 42 42 0 -1 4194560 1000 0 0 0 %d %d 0 0 20 0 12 0 100 123456789 2000 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 %d 0 0 0 0 0 0 0 0 0 0 0 0\n",
+		thread.tid, thread.name, thread.utime, thread.stime, thread.processor)
+}
+
+// useThreadFixture writes /proc/42/task with viam-server's main thread, two unnamed Go runtime threads and a thread
+// named by a library
+func useThreadFixture(t *testing.T) {
+	t.Helper()
+	threads := []fakeThread{
+		{tid: 42, name: "viam-server", utime: 1000, stime: 200, processor: 0},
+		{tid: 43, name: "viam-server", utime: 5000, stime: 1000, processor: 3},
+		{tid: 44, name: "viam-server", utime: 10, stime: 5, processor: 1},
+		{tid: 45, name: "grpc (worker)\x1b", utime: 300, stime: 100, processor: 2},
+	}
+	files := make(map[string]string, len(threads))
+	for _, thread := range threads {
+		files["/proc/42/task/"+strconv.Itoa(int(thread.tid))+"/stat"] = threadStat(thread)
+	}
+	utilstest.UseFixtureFS(t, files)
+}
+
+func TestReadProcessThreads(t *testing.T) {
+	useThreadFixture(t)
+
+	sample, err := ReadProcessThreads(context.Background(), 42)
+	require.NoError(t, err)
+	require.Len(t, sample.Threads, 4)
+	assert.Equal(t, ThreadStats{TID: 43, Name: "viam-server", UTime: 5000, STime: 1000, Processor: 3}, sample.Threads[43])
+	// Parentheses in the name don't confuse the parser, control characters are replaced
+	assert.Equal(t, "grpc (worker)?", sample.Threads[45].Name)
+	assert.Equal(t, 2, sample.Threads[45].Processor)
+
+	_, err = ReadProcessThreads(context.Background(), 43)
+	assert.Error(t, err)
+}
+
+func TestCalculateTopThreads(t *testing.T) {
+	useThreadFixture(t)
+
+	curr, err := ReadProcessThreads(context.Background(), 42)
+	require.NoError(t, err)
+
+	// Without a previous sample nothing has used any CPU yet
+	top := CalculateTopThreads(nil, curr, 2)
+	require.Len(t, top, 2)
+	assert.Equal(t, int32(42), top[0].TID)
+	assert.Equal(t, 0.0, top[0].CPUPercent)
+
+	prev := &ThreadSample{
+		Timestamp: curr.Timestamp.Add(-2 * time.Second),
+		PID:       42,
+		Threads: map[int32]ThreadStats{
+			42: {TID: 42, Name: "viam-server", UTime: 990, STime: 200},
+			43: {TID: 43, Name: "viam-server", UTime: 4850, STime: 990},
+			45: {TID: 45, Name: "grpc (worker)?", UTime: 250, STime: 100},
+		},
+	}
+	top = CalculateTopThreads(prev, curr, 3)
+	require.Len(t, top, 3)
+	// 160 ticks in 2 seconds is 80% of a core
+	assert.Equal(t, &ThreadUsage{TID: 43, Name: "viam-server", Named: false, Processor: 3, CPUPercent: 80}, top[0])
+	assert.Equal(t, &ThreadUsage{TID: 45, Name: "grpc (worker)?", Named: true, Processor: 2, CPUPercent: 25}, top[1])
+	assert.Equal(t, &ThreadUsage{TID: 42, Name: "viam-server", Named: true, Processor: 0, CPUPercent: 5}, top[2])
+	assert.Equal(t, map[string]interface{}{"tid": int32(43), "name": "viam-server", "named": false, "last_cpu": 3, "cpu_percent": 80.0}, top[0].Map())
+
+	assert.Len(t, CalculateTopThreads(prev, curr, 10), 4)
+}
+
+func TestParseThreadStat_Invalid(t *testing.T) {
+	_, err := parseThreadStat("42 viam-server S 1")
+	assert.Error(t, err)
+	_, err = parseThreadStat("42 (viam-server) S 1 2 3")
+	assert.Error(t, err)
+}
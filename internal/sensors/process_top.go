@@ -0,0 +1,168 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// ProcessUsage holds the cumulative counters of a process, CPUSeconds is user plus system time. StartTime, in clock
+// ticks since boot, tells the process apart from a later one that reused its PID.
+type ProcessUsage struct {
+	PID        int32
+	StartTime  uint64
+	CPUSeconds float64
+	RSS        uint64
+	ReadBytes  uint64
+	WriteBytes uint64
+	Threads    int32
+	proc       *process.Process
+}
+
+// ProcessTable is a sample of every process, keyed by PID
+type ProcessTable struct {
+	Timestamp time.Time
+	Processes map[int32]*ProcessUsage
+}
+
+// ReadProcessTable samples the counters of every process. Processes that exit while being read are left out, IO
+// counters of processes owned by other users are 0 unless the module runs as root.
+func ReadProcessTable(ctx context.Context) (*ProcessTable, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to get processes"), err)
+	}
+	table := &ProcessTable{Timestamp: time.Now(), Processes: make(map[int32]*ProcessUsage, len(procs))}
+	for _, proc := range procs {
+		times, err := proc.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		startTime, err := ReadProcessStartTime(proc.Pid)
+		if err != nil {
+			continue
+		}
+		usage := &ProcessUsage{PID: proc.Pid, StartTime: startTime, CPUSeconds: times.User + times.System, proc: proc}
+		if mem, err := proc.MemoryInfoWithContext(ctx); err == nil {
+			usage.RSS = mem.RSS
+		}
+		if io, err := proc.IOCountersWithContext(ctx); err == nil {
+			usage.ReadBytes = io.ReadBytes
+			usage.WriteBytes = io.WriteBytes
+		}
+		if threads, err := proc.NumThreadsWithContext(ctx); err == nil {
+			usage.Threads = threads
+		}
+		table.Processes[proc.Pid] = usage
+	}
+	return table, nil
+}
+
+// TopProcess is the usage of a process between two samples. CPUPercent is a percentage of one core, like top.
+type TopProcess struct {
+	PID                 int32
+	Name                string
+	User                string
+	Cmdline             string
+	CPUPercent          float64
+	RSS                 uint64
+	ReadBytesPerSecond  float64
+	WriteBytesPerSecond float64
+	Threads             int32
+	proc                *process.Process
+}
+
+func (p *TopProcess) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"pid":                    p.PID,
+		"name":                   p.Name,
+		"user":                   p.User,
+		"cmdline":                p.Cmdline,
+		"cpu_percent":            p.CPUPercent,
+		"rss":                    p.RSS,
+		"io_read_bytes_per_sec":  p.ReadBytesPerSecond,
+		"io_write_bytes_per_sec": p.WriteBytesPerSecond,
+		"threads":                p.Threads,
+	}
+}
+
+// TopProcesses are the busiest processes by each measure, busiest first
+type TopProcesses struct {
+	Count   int
+	CPU     []*TopProcess
+	RSS     []*TopProcess
+	IO      []*TopProcess
+	Threads []*TopProcess
+}
+
+// CalculateTopProcesses ranks the processes in curr by CPU, RSS, IO and thread count and keeps the top n of each.
+// Processes that were not in prev, or reused the PID of one that was, have no CPU or IO rates yet. Name, user and
+// cmdline are only looked up for the processes that made it into a list.
+func CalculateTopProcesses(ctx context.Context, prev, curr *ProcessTable, n int) TopProcesses {
+	seconds := curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	all := make([]*TopProcess, 0, len(curr.Processes))
+	for pid, usage := range curr.Processes {
+		top := &TopProcess{PID: pid, RSS: usage.RSS, Threads: usage.Threads, proc: usage.proc}
+		if last, ok := prev.Processes[pid]; ok && last.StartTime == usage.StartTime && seconds > 0 {
+			if cpu := usage.CPUSeconds - last.CPUSeconds; cpu > 0 {
+				top.CPUPercent = utils.RoundValue(cpu/seconds*100, 2)
+			}
+			top.ReadBytesPerSecond = perSecond(counterDelta(last.ReadBytes, usage.ReadBytes), seconds)
+			top.WriteBytesPerSecond = perSecond(counterDelta(last.WriteBytes, usage.WriteBytes), seconds)
+		}
+		all = append(all, top)
+	}
+
+	ret := TopProcesses{
+		Count:   len(all),
+		CPU:     topBy(all, n, func(p *TopProcess) float64 { return p.CPUPercent }),
+		RSS:     topBy(all, n, func(p *TopProcess) float64 { return float64(p.RSS) }),
+		IO:      topBy(all, n, func(p *TopProcess) float64 { return p.ReadBytesPerSecond + p.WriteBytesPerSecond }),
+		Threads: topBy(all, n, func(p *TopProcess) float64 { return float64(p.Threads) }),
+	}
+	described := make(map[int32]bool)
+	for _, list := range [][]*TopProcess{ret.CPU, ret.RSS, ret.IO, ret.Threads} {
+		for _, top := range list {
+			if !described[top.PID] {
+				described[top.PID] = true
+				top.describe(ctx)
+			}
+		}
+	}
+	return ret
+}
+
+// topBy returns the n processes with the highest value, ties go to the lowest PID so the lists are stable
+func topBy(all []*TopProcess, n int, value func(*TopProcess) float64) []*TopProcess {
+	sorted := append([]*TopProcess{}, all...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if vi, vj := value(sorted[i]), value(sorted[j]); vi != vj {
+			return vi > vj
+		}
+		return sorted[i].PID < sorted[j].PID
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// describe looks up the name, user and cmdline, they are left empty if the process exited
+func (p *TopProcess) describe(ctx context.Context) {
+	if p.proc == nil {
+		return
+	}
+	if name, err := p.proc.NameWithContext(ctx); err == nil {
+		p.Name = name
+	}
+	if user, err := p.proc.UsernameWithContext(ctx); err == nil {
+		p.User = user
+	}
+	if args, err := p.proc.CmdlineSliceWithContext(ctx); err == nil {
+		// Readings are synced to the cloud, keep API keys passed to modules out of them
+		p.Cmdline = strings.Join(defaultRedactor.RedactCmdline(args), " ")
+	}
+}
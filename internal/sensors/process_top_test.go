@@ -0,0 +1,87 @@
+package sensors
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func topPids(procs []*TopProcess) []int32 {
+	pids := make([]int32, 0, len(procs))
+	for _, proc := range procs {
+		pids = append(pids, proc.PID)
+	}
+	return pids
+}
+
+func TestCalculateTopProcesses(t *testing.T) {
+	now := time.Now()
+	prev := &ProcessTable{
+		Timestamp: now.Add(-2 * time.Second),
+		Processes: map[int32]*ProcessUsage{
+			1:   {PID: 1, CPUSeconds: 10, RSS: 10 << 20, ReadBytes: 1000, WriteBytes: 1000, Threads: 1},
+			100: {PID: 100, CPUSeconds: 50, RSS: 200 << 20, ReadBytes: 0, WriteBytes: 0, Threads: 30},
+			200: {PID: 200, CPUSeconds: 5, RSS: 50 << 20, ReadBytes: 5000, WriteBytes: 0, Threads: 4},
+			// Exited before curr
+			250: {PID: 250, CPUSeconds: 100, RSS: 500 << 20, Threads: 100},
+			400: {PID: 400, StartTime: 1000, CPUSeconds: 2, WriteBytes: 100, Threads: 1},
+		},
+	}
+	curr := &ProcessTable{
+		Timestamp: now,
+		Processes: map[int32]*ProcessUsage{
+			1:   {PID: 1, CPUSeconds: 10, RSS: 10 << 20, ReadBytes: 1000, WriteBytes: 1000, Threads: 1},
+			100: {PID: 100, CPUSeconds: 51, RSS: 210 << 20, ReadBytes: 0, WriteBytes: 4096, Threads: 30},
+			200: {PID: 200, CPUSeconds: 8, RSS: 50 << 20, ReadBytes: 2_005_000, WriteBytes: 0, Threads: 4},
+			// Started since prev, no rates yet
+			300: {PID: 300, CPUSeconds: 20, RSS: 1 << 20, ReadBytes: 1 << 30, Threads: 4},
+			// Reused the PID of a process that exited, it is new too
+			400: {PID: 400, StartTime: 5000, CPUSeconds: 30, WriteBytes: 1 << 30, Threads: 1},
+		},
+	}
+
+	top := CalculateTopProcesses(context.Background(), prev, curr, 2)
+	assert.Equal(t, 5, top.Count)
+	assert.Equal(t, []int32{200, 100}, topPids(top.CPU))
+	assert.Equal(t, 150.0, top.CPU[0].CPUPercent)
+	assert.Equal(t, 50.0, top.CPU[1].CPUPercent)
+	assert.Equal(t, []int32{100, 200}, topPids(top.RSS))
+	assert.Equal(t, []int32{200, 100}, topPids(top.IO))
+	assert.Equal(t, 1_000_000.0, top.IO[0].ReadBytesPerSecond)
+	assert.Equal(t, 2048.0, top.IO[1].WriteBytesPerSecond)
+	// Ties go to the lowest PID
+	assert.Equal(t, []int32{100, 200}, topPids(top.Threads))
+
+	all := CalculateTopProcesses(context.Background(), prev, curr, 10)
+	assert.Len(t, all.CPU, 5)
+	for _, proc := range all.CPU {
+		if proc.PID == 400 {
+			assert.Equal(t, 0.0, proc.CPUPercent)
+			assert.Equal(t, 0.0, proc.WriteBytesPerSecond)
+		}
+	}
+}
+
+func TestReadProcessTable(t *testing.T) {
+	table, err := ReadProcessTable(context.Background())
+	require.NoError(t, err)
+	self, ok := table.Processes[int32(os.Getpid())]
+	require.True(t, ok)
+	assert.Greater(t, self.RSS, uint64(0))
+	assert.Greater(t, self.Threads, int32(0))
+	assert.Greater(t, self.StartTime, uint64(0))
+
+	top := CalculateTopProcesses(context.Background(), table, table, len(table.Processes))
+	for _, proc := range top.RSS {
+		if proc.PID == int32(os.Getpid()) {
+			assert.NotEmpty(t, proc.Name)
+			assert.NotEmpty(t, proc.Cmdline)
+			return
+		}
+	}
+	t.Fatal("test process not in the top list")
+}
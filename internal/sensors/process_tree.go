@@ -0,0 +1,96 @@
+package sensors
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/shirou/gopsutil/v4/process"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// ProcessTree maps every process to its children, from the PPid in /proc/<pid>/status
+type ProcessTree struct {
+	children map[int32][]int32
+}
+
+// ReadProcessTree reads the parent of every process once, so the descendants of several processes can be walked
+// without rereading /proc for each of them
+func ReadProcessTree() (*ProcessTree, error) {
+	entries, err := utils.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	tree := &ProcessTree{children: make(map[int32][]int32)}
+	table := make(procTable)
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		proc := table.get(int32(pid))
+		if ppid := proc.ppid(); ppid > 0 {
+			tree.children[ppid] = append(tree.children[ppid], proc.pid)
+		}
+	}
+	for _, children := range tree.children {
+		sort.Slice(children, func(i, j int) bool { return children[i] < children[j] })
+	}
+	return tree, nil
+}
+
+// Descendants returns the children of pid, their children and so on, closest first
+func (t *ProcessTree) Descendants(pid int32) []int32 {
+	ret := make([]int32, 0)
+	seen := map[int32]bool{pid: true}
+	queue := []int32{pid}
+	for len(queue) > 0 {
+		for _, child := range t.children[queue[0]] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			ret = append(ret, child)
+			queue = append(queue, child)
+		}
+		queue = queue[1:]
+	}
+	return ret
+}
+
+// TreeProcessUsage is a sample of a process in a tree, CPUSeconds is user plus system time
+type TreeProcessUsage struct {
+	PID        int32
+	Name       string
+	CPUSeconds float64
+	RSS        uint64
+	Threads    int32
+	Fds        int
+}
+
+// ReadTreeProcessUsage samples a process for its tree's totals. Fds is 0 if the fds of the process can't be read.
+func ReadTreeProcessUsage(ctx context.Context, pid int32) (*TreeProcessUsage, error) {
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	times, err := proc.TimesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage := &TreeProcessUsage{PID: pid, CPUSeconds: times.User + times.System}
+	if name, err := proc.NameWithContext(ctx); err == nil {
+		usage.Name = name
+	}
+	if mem, err := proc.MemoryInfoWithContext(ctx); err == nil {
+		usage.RSS = mem.RSS
+	}
+	if threads, err := proc.NumThreadsWithContext(ctx); err == nil {
+		usage.Threads = threads
+	}
+	if fds, err := ReadProcessFdCount(pid); err == nil {
+		usage.Fds = fds
+	}
+	return usage, nil
+}
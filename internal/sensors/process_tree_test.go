@@ -0,0 +1,30 @@
+package sensors
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessTree(t *testing.T) {
+	useProcFixture(t)
+
+	tree, err := ReadProcessTree()
+	require.NoError(t, err)
+	assert.Equal(t, []int32{100, 400, 200, 300}, tree.Descendants(1))
+	assert.Equal(t, []int32{200, 300}, tree.Descendants(100))
+	assert.Empty(t, tree.Descendants(200))
+	assert.Empty(t, tree.Descendants(12345))
+}
+
+func TestReadTreeProcessUsage(t *testing.T) {
+	usage, err := ReadTreeProcessUsage(context.Background(), int32(os.Getpid()))
+	require.NoError(t, err)
+	assert.NotEmpty(t, usage.Name)
+	assert.Greater(t, usage.RSS, uint64(0))
+	assert.Greater(t, usage.Threads, int32(0))
+	assert.Greater(t, usage.Fds, 0)
+}
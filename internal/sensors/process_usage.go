@@ -0,0 +1,187 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// readProcessKeyedFile parses the "key: value" per line format of /proc/<pid>/io, status and smaps_rollup, and of
+// task/<tid>/status. The first field of each value is kept, so units like kB are dropped. Lines that are not numbers,
+// like Name in status, are skipped.
+func readProcessKeyedFile(ctx context.Context, pid int32, elem ...string) (map[string]uint64, error) {
+	contents, err := utils.ReadFileWithContext(ctx, procPath(pid, elem...))
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]uint64)
+	for _, line := range strings.Split(contents, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+			ret[strings.TrimSpace(key)] = v
+		}
+	}
+	return ret, nil
+}
+
+// SmapsRollup is /proc/<pid>/smaps_rollup in bytes. PSS splits shared pages between the processes mapping them, so
+// unlike RSS the PSS of every process adds up to the memory in use.
+type SmapsRollup struct {
+	RSS          uint64
+	PSS          uint64
+	SharedClean  uint64
+	SharedDirty  uint64
+	PrivateClean uint64
+	PrivateDirty uint64
+	SwapPSS      uint64
+}
+
+// USS is the unique set size, the memory that would be freed if the process exited
+func (s *SmapsRollup) USS() uint64 {
+	return s.PrivateClean + s.PrivateDirty
+}
+
+func (s *SmapsRollup) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"mem_pss":           s.PSS,
+		"mem_uss":           s.USS(),
+		"mem_shared_clean":  s.SharedClean,
+		"mem_shared_dirty":  s.SharedDirty,
+		"mem_private_clean": s.PrivateClean,
+		"mem_private_dirty": s.PrivateDirty,
+		"mem_swap_pss":      s.SwapPSS,
+	}
+}
+
+// ReadProcessSmapsRollup reads /proc/<pid>/smaps_rollup, which needs Linux 4.14 or later
+func ReadProcessSmapsRollup(ctx context.Context, pid int32) (*SmapsRollup, error) {
+	values, err := readProcessKeyedFile(ctx, pid, "smaps_rollup")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := values["Pss"]; !ok {
+		return nil, fmt.Errorf("no Pss in smaps_rollup of process %d", pid)
+	}
+	return &SmapsRollup{
+		RSS:          values["Rss"] * 1024,
+		PSS:          values["Pss"] * 1024,
+		SharedClean:  values["Shared_Clean"] * 1024,
+		SharedDirty:  values["Shared_Dirty"] * 1024,
+		PrivateClean: values["Private_Clean"] * 1024,
+		PrivateDirty: values["Private_Dirty"] * 1024,
+		SwapPSS:      values["SwapPss"] * 1024,
+	}, nil
+}
+
+// ProcessIO is /proc/<pid>/io. ReadChars and WriteChars count every read and write call, including ones served from
+// the page cache or to pipes and sockets, ReadBytes and WriteBytes only what reached the block device.
+type ProcessIO struct {
+	Timestamp  time.Time
+	ReadChars  uint64
+	WriteChars uint64
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+// ReadProcessIO reads /proc/<pid>/io, only the process's user and root can read it
+func ReadProcessIO(ctx context.Context, pid int32) (*ProcessIO, error) {
+	values, err := readProcessKeyedFile(ctx, pid, "io")
+	if err != nil {
+		return nil, err
+	}
+	return &ProcessIO{
+		Timestamp:  time.Now(),
+		ReadChars:  values["rchar"],
+		WriteChars: values["wchar"],
+		ReadBytes:  values["read_bytes"],
+		WriteBytes: values["write_bytes"],
+	}, nil
+}
+
+// CalculateProcessIORates reports the IO of a process between prev and curr, prev may be nil on the first sample
+func CalculateProcessIORates(prev, curr *ProcessIO) map[string]interface{} {
+	if prev == nil {
+		prev = curr
+	}
+	seconds := curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	return map[string]interface{}{
+		"io_read_bytes_per_sec":  perSecond(counterDelta(prev.ReadBytes, curr.ReadBytes), seconds),
+		"io_write_bytes_per_sec": perSecond(counterDelta(prev.WriteBytes, curr.WriteBytes), seconds),
+		"io_rchar_per_sec":       perSecond(counterDelta(prev.ReadChars, curr.ReadChars), seconds),
+		"io_wchar_per_sec":       perSecond(counterDelta(prev.WriteChars, curr.WriteChars), seconds),
+	}
+}
+
+// ContextSwitches are the voluntary_ctxt_switches and nonvoluntary_ctxt_switches of /proc/<pid>/task/<tid>/status,
+// summed over the process's threads. Voluntary switches are waits for IO or locks, involuntary ones are the scheduler
+// taking the CPU away. The switches of threads that exited are no longer counted, so the totals can go down.
+type ContextSwitches struct {
+	Timestamp   time.Time
+	Voluntary   uint64
+	Involuntary uint64
+}
+
+// ReadProcessContextSwitches sums the context switches of every thread of a process, /proc/<pid>/status only has the
+// ones of the main thread. Threads that exit while being read are left out.
+func ReadProcessContextSwitches(ctx context.Context, pid int32) (*ContextSwitches, error) {
+	entries, err := utils.ReadDir(procPath(pid, "task"))
+	if err != nil {
+		return nil, err
+	}
+	ret := &ContextSwitches{Timestamp: time.Now()}
+	threads := 0
+	for _, entry := range entries {
+		if _, err := strconv.ParseInt(entry.Name(), 10, 32); err != nil {
+			continue
+		}
+		values, err := readProcessKeyedFile(ctx, pid, "task", entry.Name(), "status")
+		if err != nil {
+			continue
+		}
+		voluntary, ok := values["voluntary_ctxt_switches"]
+		if !ok {
+			return nil, fmt.Errorf("no voluntary_ctxt_switches in status of thread %s of process %d", entry.Name(), pid)
+		}
+		ret.Voluntary += voluntary
+		ret.Involuntary += values["nonvoluntary_ctxt_switches"]
+		threads++
+	}
+	if threads == 0 {
+		return nil, fmt.Errorf("no threads of process %d could be read", pid)
+	}
+	return ret, nil
+}
+
+// CalculateContextSwitchRates reports the totals of curr and the rates since prev, prev may be nil on the first sample
+func CalculateContextSwitchRates(prev, curr *ContextSwitches) map[string]interface{} {
+	if prev == nil {
+		prev = curr
+	}
+	seconds := curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	return map[string]interface{}{
+		"ctx_switches_voluntary":           curr.Voluntary,
+		"ctx_switches_involuntary":         curr.Involuntary,
+		"ctx_switches_voluntary_per_sec":   perSecond(counterDelta(prev.Voluntary, curr.Voluntary), seconds),
+		"ctx_switches_involuntary_per_sec": perSecond(counterDelta(prev.Involuntary, curr.Involuntary), seconds),
+	}
+}
+
+// ReadProcessFdCount counts the open file descriptors of a process without resolving them
+func ReadProcessFdCount(pid int32) (int, error) {
+	entries, err := utils.ReadDir(procPath(pid, "fd"))
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,128 @@
+package sensors
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const smapsRollupFixture = `00400000-7ffd1b5ff000 ---p 00000000 00:00 0                          [rollup]
+Rss:               45000 kB
+Pss:               20500 kB
+Pss_Anon:          15000 kB
+Pss_File:           5500 kB
+Pss_Shmem:             0 kB
+Shared_Clean:      24000 kB
+Shared_Dirty:        500 kB
+Private_Clean:       500 kB
+Private_Dirty:     20000 kB
+Referenced:        45000 kB
+Anonymous:         15000 kB
+Swap:                 64 kB
+SwapPss:              32 kB
+Locked:                0 kB
+`
+
+const ioFixture = `rchar: 1000000
+wchar: 500000
+syscr: 100
+syscw: 50
+read_bytes: 4096
+write_bytes: 8192
+cancelled_write_bytes: 0
+`
+
+const statusFixture = `Name:	python3
+Umask:	0022
+State:	S (sleeping)
+Pid:	42
+PPid:	1
+Threads:	4
+voluntary_ctxt_switches:	1500
+nonvoluntary_ctxt_switches:	25
+`
+
+// threadStatusFixture is the part of /proc/42/task/<tid>/status that is read
+func threadStatusFixture(voluntary, involuntary int) string {
+	return "Name:\tpython3\nvoluntary_ctxt_switches:\t" + strconv.Itoa(voluntary) + "\nnonvoluntary_ctxt_switches:\t" + strconv.Itoa(involuntary) + "\n"
+}
+
+// useProcUsageFixture writes /proc/42 with smaps_rollup, io, status, the status of its 3 threads and 5 fds
+func useProcUsageFixture(t *testing.T) {
+	t.Helper()
+	root := utilstest.UseFixtureFS(t, map[string]string{
+		"/proc/42/smaps_rollup": smapsRollupFixture,
+		"/proc/42/io":           ioFixture,
+		"/proc/42/status":       statusFixture,
+		// The process status only counts the main thread
+		"/proc/42/task/42/status": threadStatusFixture(1500, 25),
+		"/proc/42/task/43/status": threadStatusFixture(400, 10),
+		"/proc/42/task/44/status": threadStatusFixture(100, 5),
+	})
+	for fd := 0; fd < 5; fd++ {
+		utilstest.SymlinkFixture(t, root, "/proc/42/fd/"+strconv.Itoa(fd), "/dev/null")
+	}
+}
+
+func TestReadProcessSmapsRollup(t *testing.T) {
+	useProcUsageFixture(t)
+
+	rollup, err := ReadProcessSmapsRollup(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(45000*1024), rollup.RSS)
+	assert.Equal(t, uint64(20500*1024), rollup.PSS)
+	assert.Equal(t, uint64(20500*1024), rollup.USS())
+	assert.Equal(t, uint64(24000*1024), rollup.SharedClean)
+	assert.Equal(t, uint64(32*1024), rollup.SwapPSS)
+	assert.Equal(t, uint64(20000*1024), rollup.Map()["mem_private_dirty"])
+
+	_, err = ReadProcessSmapsRollup(context.Background(), 43)
+	assert.Error(t, err)
+}
+
+func TestProcessIORates(t *testing.T) {
+	useProcUsageFixture(t)
+
+	curr, err := ReadProcessIO(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000000), curr.ReadChars)
+	assert.Equal(t, uint64(8192), curr.WriteBytes)
+
+	// The first sample has nothing to compare against
+	assert.Equal(t, 0.0, CalculateProcessIORates(nil, curr)["io_read_bytes_per_sec"])
+
+	prev := &ProcessIO{Timestamp: curr.Timestamp.Add(-2 * time.Second), ReadChars: 800000, WriteChars: 500000, ReadBytes: 0, WriteBytes: 8192}
+	rates := CalculateProcessIORates(prev, curr)
+	assert.Equal(t, 2048.0, rates["io_read_bytes_per_sec"])
+	assert.Equal(t, 0.0, rates["io_write_bytes_per_sec"])
+	assert.Equal(t, 100000.0, rates["io_rchar_per_sec"])
+	assert.Equal(t, 0.0, rates["io_wchar_per_sec"])
+}
+
+func TestProcessContextSwitchRates(t *testing.T) {
+	useProcUsageFixture(t)
+
+	curr, err := ReadProcessContextSwitches(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2000), curr.Voluntary)
+	assert.Equal(t, uint64(40), curr.Involuntary)
+
+	prev := &ContextSwitches{Timestamp: curr.Timestamp.Add(-time.Second), Voluntary: 1500, Involuntary: 35}
+	rates := CalculateContextSwitchRates(prev, curr)
+	assert.Equal(t, uint64(2000), rates["ctx_switches_voluntary"])
+	assert.Equal(t, 500.0, rates["ctx_switches_voluntary_per_sec"])
+	assert.Equal(t, 5.0, rates["ctx_switches_involuntary_per_sec"])
+}
+
+func TestReadProcessFdCount(t *testing.T) {
+	useProcUsageFixture(t)
+
+	count, err := ReadProcessFdCount(42)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
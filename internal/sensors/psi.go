@@ -0,0 +1,143 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+var (
+	ErrPSINotSupported = errors.New("pressure stall information is not available, the kernel needs CONFIG_PSI and must not be booted with psi=0")
+	ErrInvalidPressure = errors.New("invalid pressure file")
+)
+
+// PressureResources are the resources the kernel reports pressure for. irq is only available on 6.1 and later.
+var PressureResources = []string{"cpu", "memory", "io", "irq"}
+
+// PressureStats is a single line of a pressure file. Total is the cumulative stall time in microseconds.
+type PressureStats struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
+// Pressure is the contents of a pressure file. Some or Full are nil when the kernel does not report them, like some
+// for irq.
+type Pressure struct {
+	Timestamp time.Time
+	Some      *PressureStats
+	Full      *PressureStats
+}
+
+func ParsePressure(contents string) (*Pressure, error) {
+	pressure := &Pressure{Timestamp: time.Now()}
+	for _, line := range strings.Split(strings.TrimSpace(contents), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		stats := &PressureStats{}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidPressure, line)
+			}
+			var err error
+			switch key {
+			case "avg10":
+				stats.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stats.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stats.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				stats.Total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPressure, err)
+			}
+		}
+		switch fields[0] {
+		case "some":
+			pressure.Some = stats
+		case "full":
+			pressure.Full = stats
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPressure, line)
+		}
+	}
+	return pressure, nil
+}
+
+func ReadPressure(ctx context.Context, path string) (*Pressure, error) {
+	contents, err := utils.ReadFileWithContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePressure(contents)
+}
+
+// ReadSystemPressure reads /proc/pressure, keyed by resource. Resources the kernel does not have are left out.
+func ReadSystemPressure(ctx context.Context) (map[string]*Pressure, error) {
+	return readPressureFiles(ctx, func(resource string) string {
+		return filepath.Join("/proc/pressure", resource)
+	})
+}
+
+// ReadCgroupPressure reads the <resource>.pressure files of a cgroup v2 group, relative to /sys/fs/cgroup
+func ReadCgroupPressure(ctx context.Context, cgroup string) (map[string]*Pressure, error) {
+	return readPressureFiles(ctx, func(resource string) string {
+		return filepath.Join("/sys/fs/cgroup", cgroup, resource+".pressure")
+	})
+}
+
+func readPressureFiles(ctx context.Context, path func(resource string) string) (map[string]*Pressure, error) {
+	ret := make(map[string]*Pressure)
+	for _, resource := range PressureResources {
+		pressure, err := ReadPressure(ctx, path(resource))
+		if err != nil {
+			if errors.Is(err, ErrInvalidPressure) {
+				return nil, err
+			}
+			continue
+		}
+		ret[resource] = pressure
+	}
+	if len(ret) == 0 {
+		return nil, ErrPSINotSupported
+	}
+	return ret, nil
+}
+
+// CalculatePressure flattens prev and curr into readings prefixed with name. stall_rate is the percentage of the
+// interval that was stalled, computed from the total counters.
+func CalculatePressure(name string, prev, curr *Pressure) map[string]interface{} {
+	ret := make(map[string]interface{})
+	seconds := curr.Timestamp.Sub(prev.Timestamp).Seconds()
+	add := func(kind string, prev, curr *PressureStats) {
+		if curr == nil {
+			return
+		}
+		prefix := name + "_" + kind + "_"
+		ret[prefix+"avg10"] = curr.Avg10
+		ret[prefix+"avg60"] = curr.Avg60
+		ret[prefix+"avg300"] = curr.Avg300
+		ret[prefix+"total"] = curr.Total
+		if prev == nil || seconds <= 0 {
+			ret[prefix+"stall_rate"] = 0.0
+			return
+		}
+		stalled := float64(counterDelta(prev.Total, curr.Total)) / 1e6
+		ret[prefix+"stall_rate"] = utils.RoundValue(stalled/seconds*100, 2)
+	}
+	add("some", prev.Some, curr.Some)
+	add("full", prev.Full, curr.Full)
+	return ret
+}
@@ -0,0 +1,63 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePressure(t *testing.T) {
+	// irq only has a full line
+	pressure, err := ParsePressure("full avg10=0.25 avg60=0.10 avg300=0.02 total=39815\n")
+	require.NoError(t, err)
+	assert.Nil(t, pressure.Some)
+	assert.Equal(t, &PressureStats{Avg10: 0.25, Avg60: 0.10, Avg300: 0.02, Total: 39815}, pressure.Full)
+
+	_, err = ParsePressure("some avg10=abc")
+	assert.ErrorIs(t, err, ErrInvalidPressure)
+	_, err = ParsePressure("partial avg10=0.00")
+	assert.ErrorIs(t, err, ErrInvalidPressure)
+}
+
+func TestReadSystemPressure(t *testing.T) {
+	utilstest.UseFileSystem(t, utils.NewFileSystem("testdata"))
+
+	pressure, err := ReadSystemPressure(context.Background())
+	require.NoError(t, err)
+	// The fixture kernel has no irq pressure
+	require.Len(t, pressure, 3)
+	assert.Equal(t, &PressureStats{Avg10: 12.40, Avg60: 8.01, Avg300: 3.26, Total: 95618420}, pressure["io"].Some)
+	assert.Equal(t, &PressureStats{Avg10: 0.05, Avg60: 0.14, Avg300: 0.04, Total: 845871}, pressure["memory"].Full)
+
+	utilstest.UseFixtureFS(t, nil)
+	_, err = ReadSystemPressure(context.Background())
+	assert.ErrorIs(t, err, ErrPSINotSupported)
+}
+
+func TestCalculatePressure(t *testing.T) {
+	start := time.Now()
+	prev := &Pressure{Timestamp: start, Some: &PressureStats{Total: 1000000}, Full: &PressureStats{Total: 500000}}
+	curr := &Pressure{
+		Timestamp: start.Add(2 * time.Second),
+		Some:      &PressureStats{Avg10: 25.0, Avg60: 10.0, Avg300: 2.5, Total: 1500000},
+		Full:      &PressureStats{Avg10: 5.0, Avg60: 2.0, Avg300: 0.5, Total: 600000},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"io_some_avg10":      25.0,
+		"io_some_avg60":      10.0,
+		"io_some_avg300":     2.5,
+		"io_some_total":      uint64(1500000),
+		"io_some_stall_rate": 25.0,
+		"io_full_avg10":      5.0,
+		"io_full_avg60":      2.0,
+		"io_full_avg300":     0.5,
+		"io_full_total":      uint64(600000),
+		"io_full_stall_rate": 5.0,
+	}, CalculatePressure("io", prev, curr))
+}
@@ -13,6 +13,8 @@ type SystemTemperatures struct {
 	CPU   *float64
 	GPU   *float64
 	Extra map[string]float64
+	// Fans are the fan speeds in RPM, keyed by name
+	Fans map[string]float64
 }
 
 type TemperatureReader interface {
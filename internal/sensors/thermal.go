@@ -0,0 +1,111 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+const thermalZoneGlob = "/sys/class/thermal/thermal_zone*"
+
+// Well known thermal zone types, in order of preference when a board reports more than one
+var (
+	cpuThermalZoneTypes = []string{"cpu-thermal", "cpu-therm", "x86-pkg-temp", "soc-thermal", "cpu"}
+	gpuThermalZoneTypes = []string{"gpu-thermal", "gpu-therm", "gpu"}
+)
+
+type ThermalZone struct {
+	// Zone is the sysfs directory name, ex: thermal_zone0
+	Zone string
+	// Type is the driver reported type, ex: cpu-thermal
+	Type string
+	// Name is the unique name used in readings
+	Name string
+	Path string
+}
+
+// GetThermalZones finds every thermal zone under /sys/class/thermal. Zones are named by their type,
+// types reported by more than one zone are suffixed with the zone number.
+func GetThermalZones() ([]ThermalZone, error) {
+	dirs, err := utils.Glob(thermalZoneGlob)
+	if err != nil {
+		return nil, err
+	}
+	zones := make([]ThermalZone, 0, len(dirs))
+	typeCounts := make(map[string]int)
+	for _, dir := range dirs {
+		data, err := utils.ReadFile(path.Join(dir, "type"))
+		if err != nil {
+			continue
+		}
+		zone := ThermalZone{
+			Zone: path.Base(dir),
+			Type: strings.TrimSpace(string(data)),
+			Path: path.Join(dir, "temp"),
+		}
+		typeCounts[zone.Type]++
+		zones = append(zones, zone)
+	}
+	for i := range zones {
+		zones[i].Name = zones[i].Type
+		if typeCounts[zones[i].Type] > 1 {
+			zones[i].Name = fmt.Sprintf("%s_%s", zones[i].Type, strings.TrimPrefix(zones[i].Zone, "thermal_zone"))
+		}
+	}
+	return zones, nil
+}
+
+// GetThermalZoneTemperatures reads every thermal zone, the best match for the CPU and GPU are reported as such
+// and everything else goes in Extra under the name returned by name. Zones that cannot be read are skipped.
+func GetThermalZoneTemperatures(ctx context.Context, name func(ThermalZone) string) (*SystemTemperatures, error) {
+	zones, err := GetThermalZones()
+	if err != nil {
+		return nil, err
+	}
+	systemTemps := &SystemTemperatures{Extra: make(map[string]float64)}
+	cpuRank, gpuRank := len(cpuThermalZoneTypes), len(gpuThermalZoneTypes)
+	var cpuZone, gpuZone string
+	for _, zone := range zones {
+		temp, err := NewFileTemperatureSensor(zone.Name, zone.Path).Read(ctx)
+		if err != nil {
+			continue
+		}
+		temp = float64(int((temp/1000)*100)) / 100
+		systemTemps.Extra[name(zone)] = temp
+		if rank := thermalZoneRank(cpuThermalZoneTypes, zone.Type); rank < cpuRank {
+			cpuRank, cpuZone = rank, name(zone)
+		}
+		if rank := thermalZoneRank(gpuThermalZoneTypes, zone.Type); rank < gpuRank {
+			gpuRank, gpuZone = rank, name(zone)
+		}
+	}
+	if cpuZone != "" {
+		temp := systemTemps.Extra[cpuZone]
+		systemTemps.CPU = &temp
+		delete(systemTemps.Extra, cpuZone)
+	}
+	if gpuZone != "" {
+		temp := systemTemps.Extra[gpuZone]
+		systemTemps.GPU = &temp
+		delete(systemTemps.Extra, gpuZone)
+	}
+	return systemTemps, nil
+}
+
+func thermalZoneRank(types []string, zoneType string) int {
+	normalized := strings.ReplaceAll(strings.ToLower(zoneType), "_", "-")
+	for i, t := range types {
+		if normalized == t {
+			return i
+		}
+	}
+	return len(types)
+}
+
+// ThermalZoneName names a zone in readings by its type.
+func ThermalZoneName(zone ThermalZone) string {
+	return zone.Name
+}
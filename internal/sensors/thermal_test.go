@@ -0,0 +1,55 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetThermalZoneTemperatures_X86(t *testing.T) {
+	utilstest.UseFixtureFS(t, map[string]string{
+		"/sys/class/thermal/thermal_zone0/type": "acpitz\n",
+		"/sys/class/thermal/thermal_zone0/temp": "27800\n",
+		"/sys/class/thermal/thermal_zone1/type": "acpitz\n",
+		"/sys/class/thermal/thermal_zone1/temp": "29800\n",
+		"/sys/class/thermal/thermal_zone2/type": "x86_pkg_temp\n",
+		"/sys/class/thermal/thermal_zone2/temp": "52000\n",
+		"/sys/class/thermal/thermal_zone3/type": "iwlwifi_1\n",
+	})
+
+	temps, err := GetThermalZoneTemperatures(context.Background(), ThermalZoneName)
+	require.NoError(t, err)
+	require.NotNil(t, temps.CPU)
+	assert.Equal(t, 52.0, *temps.CPU)
+	assert.Nil(t, temps.GPU)
+	assert.Equal(t, map[string]float64{"acpitz_0": 27.8, "acpitz_1": 29.8}, temps.Extra)
+}
+
+func TestGetThermalZoneTemperatures_PrefersCpuZone(t *testing.T) {
+	utilstest.UseFixtureFS(t, map[string]string{
+		"/sys/class/thermal/thermal_zone0/type": "soc-thermal\n",
+		"/sys/class/thermal/thermal_zone0/temp": "45000\n",
+		"/sys/class/thermal/thermal_zone1/type": "cpu_thermal\n",
+		"/sys/class/thermal/thermal_zone1/temp": "50000\n",
+		"/sys/class/thermal/thermal_zone2/type": "gpu_thermal\n",
+		"/sys/class/thermal/thermal_zone2/temp": "44000\n",
+	})
+
+	temps, err := GetThermalZoneTemperatures(context.Background(), ThermalZoneName)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, *temps.CPU)
+	assert.Equal(t, 44.0, *temps.GPU)
+	assert.Equal(t, map[string]float64{"soc-thermal": 45.0}, temps.Extra)
+}
+
+func TestGetThermalZoneTemperatures_NoZones(t *testing.T) {
+	utilstest.UseFixtureFS(t, nil)
+
+	temps, err := GetThermalZoneTemperatures(context.Background(), ThermalZoneName)
+	require.NoError(t, err)
+	assert.Nil(t, temps.CPU)
+	assert.Empty(t, temps.Extra)
+}
@@ -0,0 +1,212 @@
+package interruptmonitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	viamutils "go.viam.com/utils"
+)
+
+var (
+	Model       = resource.NewModel(utils.Namespace, "hwmonitor", "interrupt_monitor")
+	API         = sensor.API
+	PrettyName  = "SBC Interrupt Monitor Sensor"
+	Description = "A sensor that reports the per CPU interrupt and softirq rates of an SBC"
+	Version     = utils.Version
+)
+
+const defaultTopN = 5
+
+type Config struct {
+	resource.Named
+	readingsLock sync.RWMutex
+	configLock   sync.Mutex
+	logger       logging.Logger
+	sleepTime    time.Duration
+	topN         int
+	workers      *viamutils.StoppableWorkers
+	reading      map[string]interface{}
+	config       *ComponentConfig
+	history      *utils.ReadingsHistory
+	commands     *utils.CommandDispatcher
+	refresh      *utils.Refresher
+}
+
+func init() {
+	resource.RegisterComponent(
+		API,
+		Model,
+		resource.Registration[sensor.Sensor, *ComponentConfig]{Constructor: NewSensor})
+}
+
+func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+	logger.Infof("Starting %s %s", PrettyName, Version)
+	b := Config{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		readingsLock: sync.RWMutex{},
+		configLock:   sync.Mutex{},
+		history:      utils.NewReadingsHistory(utils.DefaultHistorySize),
+		refresh:      utils.NewRefresher(),
+	}
+	b.commands = b.newCommandDispatcher()
+
+	if err := b.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Started %s %s", PrettyName, Version)
+	return &b, nil
+}
+
+func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawConf resource.Config) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Reconfiguring %s", PrettyName)
+
+	if c.workers != nil {
+		c.logger.Debug("Stopping background worker")
+		c.workers.Stop()
+		c.logger.Debugf("Background worker stopped")
+	}
+
+	conf, err := resource.NativeConfig[*ComponentConfig](rawConf)
+	if err != nil {
+		return err
+	}
+
+	// In case the component has changed name
+	c.Named = rawConf.ResourceName().AsNamed()
+	if conf.SleepTimeMs <= 0 {
+		// Default to 1000ms if no sleep time is provided
+		c.logger.Warnf("Invalid sleep time %d, defaulting to 1000ms", conf.SleepTimeMs)
+		conf.SleepTimeMs = 1000 // Default to 1 second
+	}
+	c.sleepTime = time.Duration(conf.SleepTimeMs * int(time.Millisecond))
+	if conf.TopN <= 0 {
+		conf.TopN = defaultTopN
+	}
+	c.topN = conf.TopN
+	c.config = conf
+	c.workers = viamutils.NewBackgroundStoppableWorkers(c.startUpdating)
+
+	c.logger.Debugf("Reconfigure complete %s", PrettyName)
+	return nil
+}
+
+func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	c.readingsLock.RLock()
+	defer c.readingsLock.RUnlock()
+	return c.reading, nil
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.configLock.Lock()
+			defer c.configLock.Unlock()
+			return c.config
+		},
+		Refresh: c.refresh.Refresh(c.Readings),
+		History: c.history,
+	})
+}
+
+func (c *Config) Close(ctx context.Context) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Shutting down %v", PrettyName)
+	c.workers.Stop()
+	c.logger.Infof("%v Shutdown complete", PrettyName)
+	return nil
+}
+
+// interruptSample is what the background worker diffs the next sample against
+type interruptSample struct {
+	interrupts *sensors.Interrupts
+	softirqs   *sensors.Interrupts
+}
+
+// startUpdating is a goroutine that samples /proc/interrupts and /proc/softirqs every sleepTime so they can be
+// reported as rates
+func (c *Config) startUpdating(ctx context.Context) {
+	var last *interruptSample
+	for {
+		if last == nil {
+			last = c.readSample(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case done := <-c.refresh.C():
+			last = c.updateReadings(ctx, last)
+			close(done)
+		case <-time.After(c.sleepTime):
+			last = c.updateReadings(ctx, last)
+		}
+	}
+}
+
+func (c *Config) readSample(ctx context.Context) *interruptSample {
+	interrupts, err := sensors.ReadInterrupts(ctx)
+	if err != nil {
+		c.logger.Warnf("Failed to read interrupts, skipping iteration: %v", err)
+		return nil
+	}
+	softirqs, err := sensors.ReadSoftIRQs(ctx)
+	if err != nil {
+		c.logger.Warnf("Failed to read softirqs, skipping iteration: %v", err)
+		return nil
+	}
+	return &interruptSample{interrupts: interrupts, softirqs: softirqs}
+}
+
+// updateReadings computes rates since last and returns the sample to diff against next time
+func (c *Config) updateReadings(ctx context.Context, last *interruptSample) *interruptSample {
+	curr := c.readSample(ctx)
+	if curr == nil {
+		return last
+	}
+	if last == nil {
+		return curr
+	}
+
+	ret := make(map[string]interface{})
+	interruptRates := sensors.CalculateInterruptRates(last.interrupts, curr.interrupts)
+	total := 0.0
+	top := make([]interface{}, 0, c.topN)
+	for i, rate := range interruptRates {
+		total += rate.Total
+		if i < c.topN {
+			top = append(top, rate.Map())
+		}
+	}
+	ret["interrupts_per_sec"] = utils.RoundValue(total, 2)
+	ret["top_interrupts"] = top
+
+	total = 0.0
+	softirqs := make(map[string]interface{})
+	for _, rate := range sensors.CalculateInterruptRates(last.softirqs, curr.softirqs) {
+		total += rate.Total
+		softirqs[rate.Name] = rate.Map()
+	}
+	ret["softirqs_per_sec"] = utils.RoundValue(total, 2)
+	ret["softirqs"] = softirqs
+
+	c.readingsLock.Lock()
+	c.reading = ret
+	c.readingsLock.Unlock()
+	c.history.Record(ret)
+	return curr
+}
@@ -0,0 +1,49 @@
+package interruptmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+)
+
+func TestUpdateReadings(t *testing.T) {
+	utilstest.UseSensorsFixtures(t)
+	ctx := context.Background()
+	sensor := &Config{
+		logger:  logging.NewTestLogger(t),
+		topN:    2,
+		history: utils.NewReadingsHistory(10),
+	}
+
+	last := sensor.updateReadings(ctx, nil)
+	require.NotNil(t, last)
+	assert.Nil(t, sensor.reading)
+
+	// Pretend the previous sample was taken a second ago, before the USB controller fired 5000 times on cpu0
+	last.interrupts.Timestamp = time.Now().Add(-time.Second)
+	last.interrupts.Counters[2].Counts[0] -= 5000
+	last.softirqs.Timestamp = last.interrupts.Timestamp
+	sensor.updateReadings(ctx, last)
+	require.NotNil(t, sensor.reading)
+
+	top := sensor.reading["top_interrupts"].([]interface{})
+	require.Len(t, top, 2)
+	usb := top[0].(map[string]interface{})
+	assert.Equal(t, "42", usb["name"])
+	assert.Equal(t, "xhci-hcd:usb1", usb["device"])
+	assert.InDelta(t, 5000, usb["rate"], 100)
+	assert.InDelta(t, 5000, usb["per_cpu"].(map[string]interface{})["cpu0"], 100)
+	assert.Equal(t, 0.0, usb["per_cpu"].(map[string]interface{})["cpu1"])
+	assert.InDelta(t, 5000, sensor.reading["interrupts_per_sec"], 100)
+
+	softirqs := sensor.reading["softirqs"].(map[string]interface{})
+	assert.Len(t, softirqs, 10)
+	assert.Equal(t, 0.0, sensor.reading["softirqs_per_sec"])
+}
@@ -0,0 +1,10 @@
+package loadmonitor
+
+type ComponentConfig struct {
+	SleepTimeMs int  `json:"sleep_time_ms"`
+	PerCore     bool `json:"per_core,omitempty"`
+}
+
+func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
+	return nil, nil, nil
+}
@@ -0,0 +1,195 @@
+package loadmonitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	viamutils "go.viam.com/utils"
+)
+
+var (
+	Model       = resource.NewModel(utils.Namespace, "hwmonitor", "load_monitor")
+	API         = sensor.API
+	PrettyName  = "SBC Load Monitor Sensor"
+	Description = "A sensor that reports the load average, scheduler and interrupt activity of an SBC"
+	Version     = utils.Version
+)
+
+type Config struct {
+	resource.Named
+	readingsLock sync.RWMutex
+	configLock   sync.Mutex
+	logger       logging.Logger
+	sleepTime    time.Duration
+	perCore      bool
+	workers      *viamutils.StoppableWorkers
+	reading      map[string]interface{}
+	config       *ComponentConfig
+	history      *utils.ReadingsHistory
+	commands     *utils.CommandDispatcher
+	refresh      *utils.Refresher
+}
+
+func init() {
+	resource.RegisterComponent(
+		API,
+		Model,
+		resource.Registration[sensor.Sensor, *ComponentConfig]{Constructor: NewSensor})
+}
+
+func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+	logger.Infof("Starting %s %s", PrettyName, Version)
+	b := Config{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		readingsLock: sync.RWMutex{},
+		configLock:   sync.Mutex{},
+		history:      utils.NewReadingsHistory(utils.DefaultHistorySize),
+		refresh:      utils.NewRefresher(),
+	}
+	b.commands = b.newCommandDispatcher()
+
+	if err := b.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Started %s %s", PrettyName, Version)
+	return &b, nil
+}
+
+func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawConf resource.Config) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Reconfiguring %s", PrettyName)
+
+	if c.workers != nil {
+		c.logger.Debug("Stopping background worker")
+		c.workers.Stop()
+		c.logger.Debugf("Background worker stopped")
+	}
+
+	conf, err := resource.NativeConfig[*ComponentConfig](rawConf)
+	if err != nil {
+		return err
+	}
+
+	// In case the component has changed name
+	c.Named = rawConf.ResourceName().AsNamed()
+	if conf.SleepTimeMs <= 0 {
+		// Default to 1000ms if no sleep time is provided
+		c.logger.Warnf("Invalid sleep time %d, defaulting to 1000ms", conf.SleepTimeMs)
+		conf.SleepTimeMs = 1000 // Default to 1 second
+	}
+	c.sleepTime = time.Duration(conf.SleepTimeMs * int(time.Millisecond))
+	c.perCore = conf.PerCore
+	c.config = conf
+	c.workers = viamutils.NewBackgroundStoppableWorkers(c.startUpdating)
+
+	c.logger.Debugf("Reconfigure complete %s", PrettyName)
+	return nil
+}
+
+func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	c.readingsLock.RLock()
+	defer c.readingsLock.RUnlock()
+	return c.reading, nil
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.configLock.Lock()
+			defer c.configLock.Unlock()
+			return c.config
+		},
+		Refresh: c.refresh.Refresh(c.Readings),
+		History: c.history,
+	})
+}
+
+func (c *Config) Close(ctx context.Context) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Shutting down %v", PrettyName)
+	c.workers.Stop()
+	c.logger.Infof("%v Shutdown complete", PrettyName)
+	return nil
+}
+
+// startUpdating is a goroutine that samples /proc/stat every sleepTime so the counters can be reported as rates
+func (c *Config) startUpdating(ctx context.Context) {
+	var err error
+	var lastStats *sensors.KernelStats
+	for {
+		if lastStats == nil {
+			lastStats, err = sensors.ReadKernelStats(ctx)
+			if err != nil {
+				c.logger.Warnf("Failed to read kernel stats: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case done := <-c.refresh.C():
+			lastStats = c.updateReadings(ctx, lastStats)
+			close(done)
+		case <-time.After(c.sleepTime):
+			lastStats = c.updateReadings(ctx, lastStats)
+		}
+	}
+}
+
+// updateReadings computes rates since lastStats and returns the stats to diff against next time
+func (c *Config) updateReadings(ctx context.Context, lastStats *sensors.KernelStats) *sensors.KernelStats {
+	currStats, err := sensors.ReadKernelStats(ctx)
+	if err != nil {
+		c.logger.Warnf("Failed to read kernel stats, skipping iteration: %v", err)
+		return lastStats
+	}
+	if lastStats == nil {
+		return currStats
+	}
+	load, err := sensors.ReadLoadAverage(ctx)
+	if err != nil {
+		c.logger.Warnf("Failed to read load average, skipping iteration: %v", err)
+		return currStats
+	}
+	rates := sensors.CalculateKernelRates(lastStats, currStats)
+
+	ret := map[string]interface{}{
+		"load_1":                   load.Load1,
+		"load_5":                   load.Load5,
+		"load_15":                  load.Load15,
+		"threads":                  load.Threads,
+		"procs_running":            currStats.ProcsRunning,
+		"procs_blocked":            currStats.ProcsBlocked,
+		"context_switches_per_sec": rates.ContextSwitches,
+		"forks_per_sec":            rates.Forks,
+		"interrupts_per_sec":       rates.Interrupts,
+		"softirqs_per_sec":         rates.SoftIRQs,
+		"cores":                    currStats.Cores,
+	}
+	if c.perCore && currStats.Cores > 0 {
+		cores := float64(currStats.Cores)
+		for _, key := range []string{"load_1", "load_5", "load_15", "context_switches_per_sec", "forks_per_sec", "interrupts_per_sec", "softirqs_per_sec"} {
+			ret[key+"_per_core"] = utils.RoundValue(ret[key].(float64)/cores, 2)
+		}
+	}
+
+	c.readingsLock.Lock()
+	c.reading = ret
+	c.readingsLock.Unlock()
+	c.history.Record(ret)
+	return currStats
+}
@@ -0,0 +1,55 @@
+package loadmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+	viamutils "go.viam.com/utils"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+)
+
+func TestUpdateReadings(t *testing.T) {
+	utilstest.UseSensorsFixtures(t)
+	ctx := context.Background()
+	sensor := &Config{
+		logger:  logging.NewTestLogger(t),
+		perCore: true,
+		history: utils.NewReadingsHistory(10),
+	}
+
+	last := sensor.updateReadings(ctx, nil)
+	require.NotNil(t, last)
+	assert.Nil(t, sensor.reading)
+
+	// Pretend the previous sample was taken a second ago with fewer context switches
+	last.Timestamp = time.Now().Add(-time.Second)
+	last.ContextSwitches -= 4000
+	sensor.updateReadings(ctx, last)
+	require.NotNil(t, sensor.reading)
+	assert.Equal(t, 0.52, sensor.reading["load_1"])
+	assert.Equal(t, 0.13, sensor.reading["load_1_per_core"])
+	assert.Equal(t, int64(431), sensor.reading["threads"])
+	assert.Equal(t, uint64(1), sensor.reading["procs_blocked"])
+	assert.Equal(t, 4, sensor.reading["cores"])
+	assert.InDelta(t, 4000, sensor.reading["context_switches_per_sec"], 50)
+	assert.Equal(t, 0.0, sensor.reading["forks_per_sec"])
+}
+
+func TestCaptureLoadStatsExitsImmediately(t *testing.T) {
+	sensor := &Config{
+		logger:    logging.NewTestLogger(t),
+		sleepTime: time.Second,
+		history:   utils.NewReadingsHistory(10),
+		refresh:   utils.NewRefresher(),
+	}
+	sensor.workers = viamutils.NewBackgroundStoppableWorkers(sensor.startUpdating)
+	start := time.Now()
+	sensor.Close(context.Background())
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
@@ -31,6 +31,9 @@ type Config struct {
 	Frequency  int
 	Minimum    int
 	Maximum    int
+	config     *ComponentConfig
+	history    *utils.ReadingsHistory
+	commands   *utils.CommandDispatcher
 }
 
 func init() {
@@ -50,7 +53,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -63,6 +68,12 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 	defer c.mu.Unlock()
 	c.logger.Debugf("Reconfiguring %s", PrettyName)
 
+	newConf, err := resource.NativeConfig[*ComponentConfig](conf)
+	if err != nil {
+		return err
+	}
+	c.config = newConf
+
 	// In case the module has changed name
 	c.Named = conf.ResourceName().AsNamed()
 
@@ -143,9 +154,28 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 		ret["swap_device_"+device.Name+"_used_percent"] = math.Round((float64(device.UsedBytes)/float64(total_swap))*100) / 100
 	}
 
+	c.history.Record(ret)
 	return ret, nil
 }
 
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
+}
+
 func (c *Config) Close(ctx context.Context) error {
 	c.logger.Infof("Shutting down %s", PrettyName)
 	return nil
@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 )
 
 func TestGetMemory(t *testing.T) {
@@ -21,3 +24,22 @@ func TestGetMemory(t *testing.T) {
 	assert.NotNil(t, readings)
 	logger.Infof("Memory readings: %v", readings)
 }
+
+func TestDoCommandRefreshRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{
+		config:  &ComponentConfig{},
+		history: utils.NewReadingsHistory(2),
+	}
+	sensor.commands = sensor.newCommandDispatcher()
+
+	for i := 0; i < 3; i++ {
+		res, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.RefreshCommand})
+		require.NoError(t, err)
+		require.Contains(t, res["readings"], "total_memory")
+	}
+
+	res, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.HistoryCommand})
+	require.NoError(t, err)
+	assert.Len(t, res["samples"], 2)
+}
@@ -8,17 +8,23 @@ import (
 	"go.viam.com/rdk/module"
 	viamutils "go.viam.com/utils"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/cgroupmonitor"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/clocks"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/cpumanager"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/cpumonitor"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/diskmonitor"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/gpumonitor"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/interruptmonitor"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/loadmonitor"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/memorymonitor"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/powermanager"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/processmonitor"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/processscheduler"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/psimonitor"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/pwmfan"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/temperatures"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/throttling"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/topmonitor"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/voltages"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/wifimonitor"
@@ -39,6 +45,12 @@ func main() {
 	moduleutils.AddModularResource(processmonitor.API, processmonitor.Model)
 	moduleutils.AddModularResource(diskmonitor.API, diskmonitor.Model)
 	moduleutils.AddModularResource(wifimonitor.API, wifimonitor.Model)
+	moduleutils.AddModularResource(loadmonitor.API, loadmonitor.Model)
+	moduleutils.AddModularResource(psimonitor.API, psimonitor.Model)
+	moduleutils.AddModularResource(interruptmonitor.API, interruptmonitor.Model)
+	moduleutils.AddModularResource(cgroupmonitor.API, cgroupmonitor.Model)
+	moduleutils.AddModularResource(topmonitor.API, topmonitor.Model)
+	moduleutils.AddModularResource(processscheduler.API, processscheduler.Model)
 	moduleutils.AddModularResource(powermanager.API, powermanager.Model)
 	viamutils.ContextualMain(moduleutils.RunModule, logger)
 }
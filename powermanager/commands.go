@@ -0,0 +1,54 @@
+package powermanager
+
+import (
+	"context"
+	"errors"
+)
+
+const SetPowerModeCommand = "set_power_mode"
+
+// SetPowerModeRequest takes the same shape as the component config
+type SetPowerModeRequest struct {
+	ComponentConfig
+}
+
+type SetPowerModeResponse struct {
+	RebootRequired bool        `json:"reboot_required"`
+	PowerMode      interface{} `json:"power_mode,omitempty"`
+}
+
+// setPowerMode applies a new power profile without a reconfigure. The next reconfigure
+// restores whatever is in the component config.
+func (c *Config) setPowerMode(ctx context.Context, req SetPowerModeRequest) (SetPowerModeResponse, error) {
+	if _, _, err := req.Validate(""); err != nil {
+		return SetPowerModeResponse{}, err
+	}
+	if req.Jetson == nil && req.Raspi == nil && req.Linux == nil {
+		return SetPowerModeResponse{}, errors.New("a power mode configuration is required")
+	}
+	pm, err := newPowerManager(ctx, &req.ComponentConfig, c.logger)
+	if err != nil {
+		return SetPowerModeResponse{}, err
+	}
+	return c.applyPowerManager(ctx, pm, &req.ComponentConfig)
+}
+
+func (c *Config) applyPowerManager(ctx context.Context, pm PowerManager, conf *ComponentConfig) (SetPowerModeResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rebootRequired, err := pm.ApplyPowerMode(ctx)
+	if err != nil {
+		c.logger.Errorf("Failed to apply power mode: %v", err)
+		return SetPowerModeResponse{}, err
+	}
+	if rebootRequired {
+		c.logger.Info("Reboot required, rebooting soon")
+	}
+	c.pm = pm
+	c.config = conf
+	powerMode, err := pm.GetCurrentPowerMode(ctx)
+	if err != nil {
+		return SetPowerModeResponse{}, err
+	}
+	return SetPowerModeResponse{RebootRequired: rebootRequired, PowerMode: powerMode}, nil
+}
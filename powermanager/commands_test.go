@@ -0,0 +1,85 @@
+package powermanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/jetson"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+type mockPowerManager struct {
+	powerMode      interface{}
+	rebootRequired bool
+	err            error
+	applied        int
+}
+
+func (m *mockPowerManager) ApplyPowerMode(ctx context.Context) (bool, error) {
+	m.applied++
+	return m.rebootRequired, m.err
+}
+
+func (m *mockPowerManager) GetCurrentPowerMode(ctx context.Context) (interface{}, error) {
+	return m.powerMode, nil
+}
+
+func TestApplyPowerManager(t *testing.T) {
+	sensor := &Config{logger: logging.NewTestLogger(t)}
+	pm := &mockPowerManager{powerMode: 2, rebootRequired: true}
+	conf := &ComponentConfig{Jetson: &jetson.PowerManagerConfig{PowerMode: 2}}
+
+	res, err := sensor.applyPowerManager(context.Background(), pm, conf)
+	require.NoError(t, err)
+	assert.True(t, res.RebootRequired)
+	assert.Equal(t, 2, res.PowerMode)
+	assert.Equal(t, 1, pm.applied)
+	assert.Same(t, pm, sensor.pm)
+	assert.Same(t, conf, sensor.config)
+}
+
+func TestApplyPowerManagerKeepsPreviousOnError(t *testing.T) {
+	previous := &mockPowerManager{}
+	previousConf := &ComponentConfig{}
+	sensor := &Config{logger: logging.NewTestLogger(t), pm: previous, config: previousConf}
+
+	_, err := sensor.applyPowerManager(context.Background(), &mockPowerManager{err: errors.New("nvpmodel failed")}, &ComponentConfig{})
+	require.Error(t, err)
+	assert.Same(t, previous, sensor.pm)
+	assert.Same(t, previousConf, sensor.config)
+}
+
+func TestSetPowerModeCommandRequiresConfig(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{logger: logging.NewTestLogger(t)}
+	sensor.commands = sensor.newCommandDispatcher()
+
+	_, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: SetPowerModeCommand})
+	require.Error(t, err)
+}
+
+func TestGetConfigCommand(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{
+		logger: logging.NewTestLogger(t),
+		config: &ComponentConfig{Jetson: &jetson.PowerManagerConfig{PowerMode: 1, LinuxConfig: linux.LinuxConfig{Governor: "schedutil"}}},
+	}
+	sensor.commands = sensor.newCommandDispatcher()
+
+	res, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.CapabilitiesCommand})
+	require.NoError(t, err)
+	assert.Contains(t, res["commands"], SetPowerModeCommand)
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.GetConfigCommand})
+	require.NoError(t, err)
+	config := res["config"].(map[string]interface{})
+	assert.Nil(t, config["raspi"])
+	assert.Equal(t, 1.0, config["jetson"].(map[string]interface{})["power_mode"])
+	assert.Equal(t, "schedutil", config["jetson"].(map[string]interface{})["governor"])
+}
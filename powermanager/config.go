@@ -1,13 +1,15 @@
 package powermanager
 
 import (
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/jetson"
-	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/raspberrypi"
 )
 
 type ComponentConfig struct {
-	Jetson *jetson.PowerManagerConfig      `json:"jetson"`
-	Raspi  *raspberrypi.PowerManagerConfig `json:"raspi"`
+	Jetson *jetson.PowerManagerConfig `json:"jetson"`
+	// Raspi has no power modes, so it takes the same cpufreq settings as Linux
+	Raspi *linux.LinuxConfig `json:"raspi"`
+	Linux *linux.LinuxConfig `json:"linux"`
 }
 
 func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
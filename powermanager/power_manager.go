@@ -1,6 +1,8 @@
 package powermanager
 
+import "context"
+
 type PowerManager interface {
-	ApplyPowerMode() (rebootRequired bool, err error)
-	GetCurrentPowerMode() (powerMode interface{}, err error)
+	ApplyPowerMode(ctx context.Context) (rebootRequired bool, err error)
+	GetCurrentPowerMode(ctx context.Context) (powerMode interface{}, err error)
 }
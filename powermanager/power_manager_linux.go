@@ -1,12 +1,12 @@
 package powermanager
 
 import (
+	"context"
 	"errors"
 
 	"github.com/rinzlerlabs/sbcidentify"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/jetson"
-	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/raspberrypi"
-	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"go.viam.com/rdk/logging"
 )
 
@@ -15,12 +15,7 @@ var (
 	ErrNoConfigForBoard = errors.New("no configuration for board")
 )
 
-func newPowerManager(config *ComponentConfig, logger logging.Logger) (PowerManager, error) {
-	err := utils.InstallPackage("cpufrequtils")
-	if err != nil {
-		return nil, errors.Join(err, errors.New("error installing cpufrequtils"))
-	}
-
+func newPowerManager(_ context.Context, config *ComponentConfig, logger logging.Logger) (PowerManager, error) {
 	if sbcidentify.IsJetson() {
 		if config.Jetson == nil {
 			return nil, ErrNoConfigForBoard
@@ -30,8 +25,10 @@ func newPowerManager(config *ComponentConfig, logger logging.Logger) (PowerManag
 		if config.Raspi == nil {
 			return nil, ErrNoConfigForBoard
 		}
-		return raspberrypi.NewPowerManager(config.Raspi, logger)
+		return linux.NewPowerManager(config.Raspi, logger)
 	}
-
-	return nil, errors.New("unknown power mode")
+	if config.Linux == nil {
+		return nil, ErrNoConfigForBoard
+	}
+	return linux.NewPowerManager(config.Linux, logger)
 }
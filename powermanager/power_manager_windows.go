@@ -1,12 +1,13 @@
 package powermanager
 
 import (
+	"context"
 	"errors"
 
 	"go.viam.com/rdk/logging"
 )
 
-func newPowerManager(_ *ComponentConfig, logger logging.Logger) (powerManager PowerManager, err error) {
+func newPowerManager(_ context.Context, _ *ComponentConfig, logger logging.Logger) (powerManager PowerManager, err error) {
 	logger.Errorf("Power manager not implemented on windows")
 	return nil, errors.New("not implemented on windows")
 }
@@ -4,7 +4,7 @@ import (
 	"context"
 	"sync"
 
-	"github.com/rinzlerlabs/viam-sbc-hwmonitor/powermanager/cpufrequtils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/cpufreq"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"go.viam.com/rdk/components/sensor"
 	"go.viam.com/rdk/logging"
@@ -26,6 +26,9 @@ type Config struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 	pm         PowerManager
+	config     *ComponentConfig
+	history    *utils.ReadingsHistory
+	commands   *utils.CommandDispatcher
 }
 
 func init() {
@@ -45,7 +48,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -66,11 +71,11 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 	// In case the module has changed name
 	c.Named = conf.ResourceName().AsNamed()
 
-	pm, err := newPowerManager(newConfig, c.logger)
+	pm, err := newPowerManager(ctx, newConfig, c.logger)
 	if err != nil {
 		return err
 	}
-	requiresReboot, err := pm.ApplyPowerMode()
+	requiresReboot, err := pm.ApplyPowerMode(ctx)
 	if err != nil {
 		c.logger.Errorf("Failed to apply power mode: %v", err)
 		return err
@@ -80,6 +85,7 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 		c.logger.Info("Reboot required, rebooting soon")
 	}
 	c.pm = pm
+	c.config = newConfig
 	return nil
 }
 
@@ -87,26 +93,60 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	minFreq, maxFreq, err := cpufrequtils.GetFrequencyLimits()
+	minFreq, maxFreq, err := cpufreq.GetFrequencyLimits(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	currentFreq, _, governor, err := cpufrequtils.GetCurrentPolicy()
+	_, _, governor, err := cpufreq.GetCurrentPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currentFreq, err := cpufreq.GetCurrentFrequency(ctx)
 	if err != nil {
 		return nil, err
 	}
 	ret := map[string]interface{}{"MinimumFrequency": minFreq, "MaximumFrequency": maxFreq, "CurrentFrequency": currentFreq, "Governor": governor}
-	powerMode, err := c.pm.GetCurrentPowerMode()
+	infos, err := cpufreq.GetPolicyInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string]interface{}, len(infos))
+	for _, info := range infos {
+		policies[info.Name] = info.Map()
+	}
+	ret["Policies"] = policies
+	powerMode, err := c.pm.GetCurrentPowerMode(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if powerMode != nil {
 		ret["PowerMode"] = powerMode
 	}
+	c.history.Record(ret)
 	return ret, nil
 }
 
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	d := utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
+	d.Register(SetPowerModeCommand, utils.NewCommandHandler(c.setPowerMode))
+	return d
+}
+
 func (c *Config) Close(ctx context.Context) error {
 	c.logger.Infof("Shutting down %s", PrettyName)
 	return nil
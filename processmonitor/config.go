@@ -4,29 +4,102 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
 )
 
 type ComponentConfig struct {
-	Name                 string `json:"name"`
-	ExecutablePath       string `json:"executable_path"`
-	IncludeEnv           bool   `json:"include_env"`
-	IncludeCmdline       bool   `json:"include_cmdline"`
-	IncludeCwd           bool   `json:"include_cwd"`
-	IncludeOpenFileCount bool   `json:"include_open_file_count"`
-	IncludeMemInfo       bool   `json:"include_mem_info"`
-	IncludeOpenFiles     bool   `json:"include_open_files"`
-	IncludeUlimits       bool   `json:"include_ulimits"`
-	IncludeNetStats      bool   `json:"include_net_stats"`
-	SleepTimeMs          int    `json:"sleep_time_ms"`       // Sleep time in milliseconds between process checks
-	DisablePIDCaching    bool   `json:"disable_pid_caching"` // Enable caching of PID to avoid repeated lookups
+	Name                 string                   `json:"name"`
+	ExecutablePath       string                   `json:"executable_path"`
+	Selector             *sensors.ProcessSelector `json:"selector,omitempty"`
+	IncludeEnv           bool                     `json:"include_env"`
+	IncludeCmdline       bool                     `json:"include_cmdline"`
+	IncludeCwd           bool                     `json:"include_cwd"`
+	IncludeOpenFileCount bool                     `json:"include_open_file_count"`
+	IncludeMemInfo       bool                     `json:"include_mem_info"`
+	IncludeOpenFiles     bool                     `json:"include_open_files"`
+	IncludeUlimits       bool                     `json:"include_ulimits"`
+	IncludeNetStats      bool                     `json:"include_net_stats"`
+	IncludeSmapsRollup   bool                     `json:"include_smaps_rollup"`
+	IncludeIO            bool                     `json:"include_io"`
+	IncludeCtxSwitches   bool                     `json:"include_ctx_switches"`
+	IncludeFdTrend       bool                     `json:"include_fd_trend"`
+	IncludeTree          bool                     `json:"include_tree"`
+	IncludeTreeBreakdown bool                     `json:"include_tree_breakdown"`
+	IncludeThreadCPU     bool                     `json:"include_thread_cpu"`
+	ThreadTopN           int                      `json:"thread_top_n"`          // Threads include_thread_cpu reports, defaults to 5
+	FdTrendWindowSec     int                      `json:"fd_trend_window_sec"`   // Window include_fd_trend fits the fd growth over, defaults to 600
+	RedactKeys           []string                 `json:"redact_keys,omitempty"` // Patterns of env and cmdline names to redact, on top of the defaults
+	AllowKeys            []string                 `json:"allow_keys,omitempty"`  // Patterns of env and cmdline names to never redact
+	MaxOpenFiles         int                      `json:"max_open_files"`        // Cap on include_open_files, defaults to 100
+	OpenFilesFilter      string                   `json:"open_files_filter"`     // Regex include_open_files paths must match
+	Rules                []WatchdogRule           `json:"rules,omitempty"`
+	DryRun               bool                     `json:"dry_run"`             // Log and record what the rules would do without doing it
+	SleepTimeMs          int                      `json:"sleep_time_ms"`       // Sleep time in milliseconds between process checks
+	DisablePIDCaching    bool                     `json:"disable_pid_caching"` // Enable caching of PID to avoid repeated lookups
+}
+
+// selector returns the configured selector, name and executable_path are shorthands for a selector with just that field
+func (conf *ComponentConfig) selector() *sensors.ProcessSelector {
+	if conf.Selector != nil {
+		return conf.Selector
+	}
+	return &sensors.ProcessSelector{Name: conf.Name, ExecutablePath: conf.ExecutablePath}
 }
 
 func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
-	if conf.ExecutablePath == "" && conf.Name == "" {
-		return nil, nil, errors.New("executable_path or name is required")
+	set := 0
+	for _, ok := range []bool{conf.Name != "", conf.ExecutablePath != "", conf.Selector != nil} {
+		if ok {
+			set++
+		}
 	}
-	if conf.ExecutablePath != "" && conf.Name != "" {
-		return nil, nil, errors.New("only one of executable_path or name is allowed")
+	if set == 0 {
+		return nil, nil, errors.New("executable_path, name or selector is required")
+	}
+	if set > 1 {
+		return nil, nil, errors.New("only one of executable_path, name or selector is allowed")
+	}
+	if conf.IncludeTreeBreakdown && !conf.IncludeTree {
+		return nil, nil, errors.New("include_tree_breakdown requires include_tree")
+	}
+	if conf.OpenFilesFilter != "" {
+		if _, err := regexp.Compile(conf.OpenFilesFilter); err != nil {
+			return nil, nil, fmt.Errorf("invalid open_files_filter: %w", err)
+		}
+	}
+	if _, err := sensors.NewRedactor(conf.RedactKeys, conf.AllowKeys); err != nil {
+		return nil, nil, fmt.Errorf("invalid redact_keys or allow_keys: %w", err)
+	}
+	if conf.Selector != nil {
+		if err := conf.Selector.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+	// The include_* options that add the metrics rules can watch to the readings
+	included := map[string]bool{
+		"":                        true,
+		"include_open_file_count": conf.IncludeOpenFileCount,
+		"include_mem_info":        conf.IncludeMemInfo,
+		"include_smaps_rollup":    conf.IncludeSmapsRollup,
+		"include_io":              conf.IncludeIO,
+		"include_ctx_switches":    conf.IncludeCtxSwitches,
+		"include_fd_trend":        conf.IncludeFdTrend,
+		"include_tree":            conf.IncludeTree,
+	}
+	names := make(map[string]bool, len(conf.Rules))
+	for i := range conf.Rules {
+		if err := conf.Rules[i].Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid rule: %w", err)
+		}
+		if option := watchdogMetrics[conf.Rules[i].Metric]; !included[option] {
+			return nil, nil, fmt.Errorf("invalid rule: rule %s: metric %s requires %s", conf.Rules[i].Name, conf.Rules[i].Metric, option)
+		}
+		if names[conf.Rules[i].Name] {
+			return nil, nil, fmt.Errorf("duplicate rule name %s", conf.Rules[i].Name)
+		}
+		names[conf.Rules[i].Name] = true
 	}
 	if conf.ExecutablePath != "" {
 		if _, err := os.Stat(conf.ExecutablePath); os.IsNotExist(err) {
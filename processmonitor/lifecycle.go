@@ -0,0 +1,161 @@
+package processmonitor
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+const (
+	// LifecycleEventsCommand returns the most recent starts and exits of the monitored processes
+	LifecycleEventsCommand = "lifecycle_events"
+
+	ProcessStarted = "started"
+	ProcessExited  = "exited"
+
+	defaultLifecycleEventsSize = 100
+)
+
+// LifecycleEvent is a matching process starting or exiting. Starts are timestamped with the process's create time,
+// exits with the time the exit was noticed, which is at most sleep_time_ms late.
+type LifecycleEvent struct {
+	Event string    `json:"event"`
+	PID   int32     `json:"pid"`
+	Name  string    `json:"name"`
+	Time  time.Time `json:"time"`
+}
+
+type LifecycleEventsRequest struct {
+	Limit int `json:"limit"`
+}
+
+type LifecycleEventsResponse struct {
+	Events []LifecycleEvent `json:"events"`
+}
+
+// trackedProcess identifies a process, the create time tells a restarted process apart from one that reused the PID
+type trackedProcess struct {
+	name       string
+	createTime time.Time
+}
+
+// lifecycleTracker diffs the matching processes of consecutive samples to find starts, exits and restarts. A nil
+// lifecycleTracker ignores everything.
+type lifecycleTracker struct {
+	mu          sync.Mutex
+	initialized bool
+	running     map[int32]trackedProcess
+	restarts    int
+	unpaired    int // Exits that have not been followed by a start yet
+	lastSeen    time.Time
+	lastExit    time.Time
+	events      utils.CappedCollection[LifecycleEvent]
+}
+
+func newLifecycleTracker(size int) *lifecycleTracker {
+	return &lifecycleTracker{
+		running: make(map[int32]trackedProcess),
+		events:  utils.NewCappedCollection[LifecycleEvent](size),
+	}
+}
+
+// reset forgets the running processes and counters, the events are kept
+func (t *lifecycleTracker) reset() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.initialized = false
+	t.running = make(map[int32]trackedProcess)
+	t.restarts = 0
+	t.unpaired = 0
+	t.lastSeen = time.Time{}
+	t.lastExit = time.Time{}
+}
+
+// observe records the processes matching at now. The processes running at the first observation are taken as they
+// are, after that a process that is gone has exited and a new one has started. A start that follows an exit is a
+// restart, a start while everything else keeps running is not.
+func (t *lifecycleTracker) observe(now time.Time, procs map[int32]trackedProcess) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(procs) > 0 {
+		t.lastSeen = now
+	}
+	if !t.initialized {
+		t.initialized = true
+		t.running = procs
+		return
+	}
+	for _, pid := range slices.Sorted(maps.Keys(t.running)) {
+		proc := t.running[pid]
+		if curr, ok := procs[pid]; ok && curr.createTime.Equal(proc.createTime) {
+			continue
+		}
+		t.events.Push(LifecycleEvent{Event: ProcessExited, PID: pid, Name: proc.name, Time: now})
+		t.lastExit = now
+		t.unpaired++
+	}
+	for _, pid := range slices.Sorted(maps.Keys(procs)) {
+		proc := procs[pid]
+		if prev, ok := t.running[pid]; ok && prev.createTime.Equal(proc.createTime) {
+			continue
+		}
+		started := proc.createTime
+		if started.IsZero() {
+			started = now
+		}
+		t.events.Push(LifecycleEvent{Event: ProcessStarted, PID: pid, Name: proc.name, Time: started})
+		if t.unpaired > 0 {
+			t.unpaired--
+			t.restarts++
+		}
+	}
+	t.running = procs
+}
+
+// readings returns restart_count, and last_seen and last_exit_time once there is something to report
+func (t *lifecycleTracker) readings() map[string]interface{} {
+	ret := make(map[string]interface{})
+	if t == nil {
+		return ret
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ret["restart_count"] = t.restarts
+	if !t.lastSeen.IsZero() {
+		ret["last_seen"] = t.lastSeen.Format(time.RFC3339)
+	}
+	if !t.lastExit.IsZero() {
+		ret["last_exit_time"] = t.lastExit.Format(time.RFC3339)
+	}
+	return ret
+}
+
+// recentEvents returns up to limit of the most recent events, oldest first. A limit of 0 returns everything.
+func (t *lifecycleTracker) recentEvents(limit int) []LifecycleEvent {
+	if t == nil {
+		return []LifecycleEvent{}
+	}
+	events := t.events.Items()
+	if limit > 0 && limit < len(events) {
+		events = events[len(events)-limit:]
+	}
+	return events
+}
+
+func (c *Config) lifecycleEvents(ctx context.Context, req LifecycleEventsRequest) (LifecycleEventsResponse, error) {
+	if req.Limit < 0 {
+		return LifecycleEventsResponse{}, errors.New("limit must not be negative")
+	}
+	return LifecycleEventsResponse{Events: c.lifecycle.recentEvents(req.Limit)}, nil
+}
@@ -0,0 +1,132 @@
+package processmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestLifecycleTracker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	agent := trackedProcess{name: "viam-agent", createTime: start.Add(-time.Hour)}
+	tracker := newLifecycleTracker(10)
+
+	// What is running when monitoring starts is not an event
+	tracker.observe(start, map[int32]trackedProcess{100: agent})
+	assert.Empty(t, tracker.recentEvents(0))
+	assert.Equal(t, map[string]interface{}{"restart_count": 0, "last_seen": "2024-01-01T12:00:00Z"}, tracker.readings())
+
+	// Still running
+	tracker.observe(start.Add(time.Second), map[int32]trackedProcess{100: agent})
+	assert.Empty(t, tracker.recentEvents(0))
+
+	// Crashed, nothing running
+	crashed := start.Add(2 * time.Second)
+	tracker.observe(crashed, map[int32]trackedProcess{})
+	readings := tracker.readings()
+	assert.Equal(t, 0, readings["restart_count"])
+	assert.Equal(t, "2024-01-01T12:00:01Z", readings["last_seen"])
+	assert.Equal(t, "2024-01-01T12:00:02Z", readings["last_exit_time"])
+
+	// Restarted with a new PID
+	restarted := trackedProcess{name: "viam-agent", createTime: start.Add(2500 * time.Millisecond)}
+	tracker.observe(start.Add(3*time.Second), map[int32]trackedProcess{200: restarted})
+	assert.Equal(t, 1, tracker.readings()["restart_count"])
+
+	// Restarted between samples, reusing the PID
+	reused := trackedProcess{name: "viam-agent", createTime: start.Add(3500 * time.Millisecond)}
+	tracker.observe(start.Add(4*time.Second), map[int32]trackedProcess{200: reused})
+	assert.Equal(t, 2, tracker.readings()["restart_count"])
+
+	// A second process that did not replace anything is not a restart
+	tracker.observe(start.Add(5*time.Second), map[int32]trackedProcess{200: reused, 300: {name: "viam-agent", createTime: start.Add(4500 * time.Millisecond)}})
+	assert.Equal(t, 2, tracker.readings()["restart_count"])
+
+	events := tracker.recentEvents(0)
+	require.Len(t, events, 5)
+	assert.Equal(t, LifecycleEvent{Event: ProcessExited, PID: 100, Name: "viam-agent", Time: crashed}, events[0])
+	assert.Equal(t, LifecycleEvent{Event: ProcessStarted, PID: 200, Name: "viam-agent", Time: restarted.createTime}, events[1])
+	assert.Equal(t, ProcessExited, events[2].Event)
+	assert.Equal(t, ProcessStarted, events[3].Event)
+	assert.Equal(t, int32(300), events[4].PID)
+	assert.Len(t, tracker.recentEvents(2), 2)
+	assert.Equal(t, int32(300), tracker.recentEvents(2)[1].PID)
+
+	// Changing the selector forgets the counters but not the events
+	tracker.reset()
+	assert.Equal(t, map[string]interface{}{"restart_count": 0}, tracker.readings())
+	assert.Len(t, tracker.recentEvents(0), 5)
+}
+
+func TestLifecycleTracker_EventsAreCapped(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newLifecycleTracker(3)
+	tracker.observe(start, map[int32]trackedProcess{})
+	for i := int32(1); i <= 5; i++ {
+		tracker.observe(start.Add(time.Duration(i)*time.Second), map[int32]trackedProcess{i: {name: "python3"}})
+	}
+	events := tracker.recentEvents(0)
+	require.Len(t, events, 3)
+	// Oldest first, even after the collection wrapped
+	assert.Equal(t, LifecycleEvent{Event: ProcessStarted, PID: 4, Name: "python3", Time: start.Add(4 * time.Second)}, events[0])
+	assert.Equal(t, LifecycleEvent{Event: ProcessExited, PID: 4, Name: "python3", Time: start.Add(5 * time.Second)}, events[1])
+	assert.Equal(t, ProcessStarted, events[2].Event)
+	assert.Equal(t, int32(5), events[2].PID)
+	// Processes without a create time started when they were first seen
+	assert.Equal(t, start.Add(5*time.Second), events[2].Time)
+}
+
+func TestLifecycleEventsCommand(t *testing.T) {
+	c := &Config{lifecycle: newLifecycleTracker(10)}
+	c.commands = c.newCommandDispatcher()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.lifecycle.observe(start, map[int32]trackedProcess{100: {name: "viam-server"}})
+	c.lifecycle.observe(start.Add(time.Second), map[int32]trackedProcess{})
+
+	ctx := context.Background()
+	res, err := c.DoCommand(ctx, map[string]interface{}{utils.CommandKey: LifecycleEventsCommand})
+	require.NoError(t, err)
+	events, ok := res["events"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, events, 1)
+	event := events[0].(map[string]interface{})
+	assert.Equal(t, "exited", event["event"])
+	assert.Equal(t, float64(100), event["pid"])
+	assert.Equal(t, "viam-server", event["name"])
+
+	_, err = c.DoCommand(ctx, map[string]interface{}{utils.CommandKey: LifecycleEventsCommand, "limit": -1})
+	assert.Error(t, err)
+}
+
+func TestReconfigure_KeepsRestartsForTheSameSelector(t *testing.T) {
+	ctx := context.Background()
+	conf := func(attrs *ComponentConfig) resource.Config {
+		return resource.Config{Name: "process", API: API, Model: Model, ConvertedAttributes: attrs}
+	}
+	s, err := NewSensor(ctx, nil, conf(&ComponentConfig{Name: "no-such-process", SleepTimeMs: 60000}), logging.NewTestLogger(t))
+	require.NoError(t, err)
+	defer s.Close(ctx)
+	c := s.(*Config)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.lifecycle.observe(start, map[int32]trackedProcess{100: {name: "no-such-process"}})
+	c.lifecycle.observe(start.Add(time.Second), map[int32]trackedProcess{200: {name: "no-such-process"}})
+	require.Equal(t, 1, c.lifecycle.readings()["restart_count"])
+
+	// Only the sleep time and rules changed
+	rules := []WatchdogRule{{Name: "cpu", Metric: "cpu", Above: 90, Action: ActionSignal, Signal: "TERM"}}
+	require.NoError(t, c.Reconfigure(ctx, nil, conf(&ComponentConfig{Name: "no-such-process", SleepTimeMs: 30000, Rules: rules})))
+	readings := c.lifecycle.readings()
+	assert.Equal(t, 1, readings["restart_count"])
+	assert.Equal(t, "2024-01-01T12:00:01Z", readings["last_exit_time"])
+
+	// A different selector matches different processes
+	require.NoError(t, c.Reconfigure(ctx, nil, conf(&ComponentConfig{Selector: &sensors.ProcessSelector{Name: "no-such-process", User: "root"}, SleepTimeMs: 30000})))
+	assert.Equal(t, map[string]interface{}{"restart_count": 0}, c.lifecycle.readings())
+}
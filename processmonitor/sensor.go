@@ -2,8 +2,10 @@ package processmonitor
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,6 +26,8 @@ var (
 	Version     = utils.Version
 )
 
+const defaultMaxOpenFiles = 100
+
 type Config struct {
 	resource.Named
 	configLock        sync.Mutex
@@ -34,11 +38,19 @@ type Config struct {
 	workers           *viamutils.StoppableWorkers
 	sleepTime         time.Duration
 	disablePIDCaching bool
+	config            *ComponentConfig
+	history           *utils.ReadingsHistory
+	lifecycle         *lifecycleTracker
+	watchdog          *watchdog
+	usage             map[int32]*processUsage
+	commands          *utils.CommandDispatcher
+	refresh           *utils.Refresher
 }
 
 type procInfo struct {
 	Name                 string
 	ExecutablePath       string
+	Selector             *sensors.ProcessSelector
 	IncludeEnv           bool
 	IncludeCmdline       bool
 	IncludeCwd           bool
@@ -47,6 +59,18 @@ type procInfo struct {
 	IncludeNetStats      bool
 	IncludeMemInfo       bool
 	IncludeOpenFileCount bool
+	IncludeSmapsRollup   bool
+	IncludeIO            bool
+	IncludeCtxSwitches   bool
+	IncludeFdTrend       bool
+	IncludeTree          bool
+	IncludeTreeBreakdown bool
+	IncludeThreadCPU     bool
+	ThreadTopN           int
+	FdTrendWindow        time.Duration
+	MaxOpenFiles         int
+	OpenFilesFilter      *regexp.Regexp
+	Redactor             *sensors.Redactor
 }
 
 func init() {
@@ -60,9 +84,14 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 	logger.Infof("Starting %s %s", PrettyName, Version)
 
 	b := Config{
-		Named:  conf.ResourceName().AsNamed(),
-		logger: logger,
+		Named:     conf.ResourceName().AsNamed(),
+		logger:    logger,
+		history:   utils.NewReadingsHistory(utils.DefaultHistorySize),
+		lifecycle: newLifecycleTracker(defaultLifecycleEventsSize),
+		watchdog:  newWatchdog(logger, defaultWatchdogHistorySize),
+		refresh:   utils.NewRefresher(),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -85,13 +114,35 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawCo
 		return err
 	}
 
-	if conf.Name == "" && conf.ExecutablePath == "" {
-		return errors.New("either name or executable path must be set")
+	if err := conf.selector().Validate(); err != nil {
+		return err
 	}
 
+	var openFilesFilter *regexp.Regexp
+	if conf.OpenFilesFilter != "" {
+		if openFilesFilter, err = regexp.Compile(conf.OpenFilesFilter); err != nil {
+			return err
+		}
+	}
+	redactor, err := sensors.NewRedactor(conf.RedactKeys, conf.AllowKeys)
+	if err != nil {
+		return err
+	}
+	if conf.MaxOpenFiles <= 0 {
+		conf.MaxOpenFiles = defaultMaxOpenFiles
+	}
+	if conf.ThreadTopN <= 0 {
+		conf.ThreadTopN = defaultThreadTopN
+	}
+	if conf.FdTrendWindowSec <= 0 {
+		conf.FdTrendWindowSec = int(defaultFdTrendWindow.Seconds())
+	}
+
+	previous := c.info
 	c.info = &procInfo{
 		Name:                 conf.Name,
 		ExecutablePath:       conf.ExecutablePath,
+		Selector:             conf.selector(),
 		IncludeEnv:           conf.IncludeEnv,
 		IncludeCmdline:       conf.IncludeCmdline,
 		IncludeCwd:           conf.IncludeCwd,
@@ -100,7 +151,21 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawCo
 		IncludeUlimits:       conf.IncludeUlimits,
 		IncludeOpenFiles:     conf.IncludeOpenFiles,
 		IncludeNetStats:      conf.IncludeNetStats,
+		IncludeSmapsRollup:   conf.IncludeSmapsRollup,
+		IncludeIO:            conf.IncludeIO,
+		IncludeCtxSwitches:   conf.IncludeCtxSwitches,
+		IncludeFdTrend:       conf.IncludeFdTrend,
+		IncludeTree:          conf.IncludeTree,
+		IncludeTreeBreakdown: conf.IncludeTreeBreakdown,
+		IncludeThreadCPU:     conf.IncludeThreadCPU,
+		ThreadTopN:           conf.ThreadTopN,
+		FdTrendWindow:        time.Duration(conf.FdTrendWindowSec) * time.Second,
+		MaxOpenFiles:         conf.MaxOpenFiles,
+		OpenFilesFilter:      openFilesFilter,
+		Redactor:             redactor,
 	}
+	// The rates and trends start over with the new config
+	c.usage = nil
 
 	// In case the module has changed name
 	c.Named = rawConf.ResourceName().AsNamed()
@@ -110,6 +175,12 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawCo
 		conf.SleepTimeMs = 1000 // Default to 1 second
 	}
 	c.disablePIDCaching = conf.DisablePIDCaching
+	// Processes that no longer match a new selector did not exit, so the restarts only start over when it changed
+	if previous == nil || !reflect.DeepEqual(previous.Selector, c.info.Selector) {
+		c.lifecycle.reset()
+	}
+	c.watchdog.configure(conf.Rules, conf.DryRun)
+	c.config = conf
 	c.sleepTime = time.Duration(conf.SleepTimeMs * int(time.Millisecond))
 	c.workers = viamutils.NewBackgroundStoppableWorkers(c.startUpdating)
 
@@ -128,16 +199,10 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 }
 
 func (c *Config) startUpdating(ctx context.Context) {
-	var procMon *sensors.ProcessMonitor
-	if c.info.Name != "" {
-		c.logger.Debugf("Creating process monitor for name: %s", c.info.Name)
-		procMon = sensors.NewProcessMonitor(c.logger, c.info.Name, c.disablePIDCaching)
-	} else if c.info.ExecutablePath != "" {
-		c.logger.Debugf("Creating process monitor for exe: %s", c.info.ExecutablePath)
-		procMon = sensors.NewProcessMonitor(c.logger, c.info.ExecutablePath, c.disablePIDCaching)
-	} else {
-		// fallback to a default process monitor if no name or executable path is provided
-		c.logger.Errorf("No process monitor could be created, neither name nor executable path provided")
+	c.logger.Debugf("Creating process monitor for: %s", c.info.Selector)
+	procMon, err := sensors.NewProcessMonitorWithSelector(c.logger, c.info.Selector, c.disablePIDCaching)
+	if err != nil {
+		c.logger.Errorf("No process monitor could be created: %v", err)
 		return
 	}
 	for {
@@ -146,26 +211,54 @@ func (c *Config) startUpdating(ctx context.Context) {
 			// exit the loop if the context is done
 			c.logger.Infof("Stopping %s update loop: %v", PrettyName, ctx.Err())
 			return
+		case done := <-c.refresh.C():
+			c.sample(ctx, procMon)
+			close(done)
 		case <-time.After(c.sleepTime):
-			readings, err := c.getCPUStats(ctx, procMon)
-			if err != nil {
-				// log the error but continue the loop
-				c.logger.Warnf("Failed to get readings: %v", err)
-				c.updateCurrentReadings(make(map[string]interface{}))
-				continue
-			}
-			// Update the readings in the sensor
-			c.updateCurrentReadings(readings)
-			// log the successful update
-			c.logger.Debugf("Successfully updated readings for %s: %v", PrettyName, readings)
+			c.sample(ctx, procMon)
 		}
 	}
 }
 
+func (c *Config) sample(ctx context.Context, procMon *sensors.ProcessMonitor) {
+	readings, err := c.getCPUStats(ctx, procMon)
+	if err != nil {
+		// log the error but keep sampling
+		c.logger.Warnf("Failed to get readings: %v", err)
+		c.updateCurrentReadings(make(map[string]interface{}))
+		return
+	}
+	// Update the readings in the sensor
+	c.updateCurrentReadings(readings)
+	c.watchdog.evaluate(time.Now(), readings)
+	// log the successful update
+	c.logger.Debugf("Successfully updated readings for %s: %v", PrettyName, readings)
+}
+
 func (c *Config) updateCurrentReadings(newReadings map[string]interface{}) {
 	c.readingsLock.Lock()
 	defer c.readingsLock.Unlock()
 	c.currentReadings = newReadings
+	c.history.Record(newReadings)
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	d := utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.configLock.Lock()
+			defer c.configLock.Unlock()
+			return c.config
+		},
+		Refresh: c.refresh.Refresh(c.Readings),
+		History: c.history,
+	})
+	d.Register(LifecycleEventsCommand, utils.NewCommandHandler(c.lifecycleEvents))
+	d.Register(WatchdogHistoryCommand, utils.NewCommandHandler(c.watchdogHistory))
+	return d
 }
 
 func (c *Config) getCPUStats(ctx context.Context, procMon *sensors.ProcessMonitor) (map[string]interface{}, error) {
@@ -177,12 +270,19 @@ func (c *Config) getCPUStats(ctx context.Context, procMon *sensors.ProcessMonito
 	}
 	c.logger.Debugf("Found %d processes for %s", procs.Len(), c.info.Name)
 
+	now := time.Now()
+	seen := make(map[int32]trackedProcess, procs.Len())
+	var tree *sensors.ProcessTree
+	if c.info.IncludeTree {
+		if tree, err = sensors.ReadProcessTree(); err != nil {
+			c.logger.Debugf("Failed to read the process tree: %v", err)
+		}
+	}
 	for _, proc := range procs.AllFromFront() {
 		ret := make(map[string]interface{})
-		if c.info.Name != "" {
+		if c.info.ExecutablePath == "" {
 			ret["name"] = proc.Name
-		}
-		if c.info.ExecutablePath != "" {
+		} else {
 			exe, err := proc.Exe()
 			if err != nil {
 				c.logger.Warnf("Error getting executable path for process %d: %v", proc.PID, err)
@@ -192,6 +292,15 @@ func (c *Config) getCPUStats(ctx context.Context, procMon *sensors.ProcessMonito
 		}
 
 		ret["pid"] = proc.PID
+		tracked := trackedProcess{name: proc.Name}
+		if createTime, err := proc.CreateTimeWithContext(ctx); err == nil {
+			tracked.createTime = time.UnixMilli(createTime)
+			ret["create_time"] = tracked.createTime.Format(time.RFC3339)
+			ret["uptime_seconds"] = utils.RoundValue(now.Sub(tracked.createTime).Seconds(), 2)
+		} else {
+			c.logger.Debugf("Failed to get create time for process %d: %v", proc.PID, err)
+		}
+		seen[proc.PID] = tracked
 		if cpu, err := proc.CPUPercentWithContext(ctx); err == nil {
 			ret["cpu"] = cpu
 		} else {
@@ -213,8 +322,8 @@ func (c *Config) getCPUStats(ctx context.Context, procMon *sensors.ProcessMonito
 			}
 		}
 		if c.info.IncludeCmdline {
-			if cmdline, err := proc.CmdlineWithContext(ctx); err == nil {
-				ret["cmdline"] = cmdline
+			if args, err := proc.CmdlineSliceWithContext(ctx); err == nil {
+				ret["cmdline"] = strings.Join(c.info.Redactor.RedactCmdline(args), " ")
 			} else {
 				c.logger.Debugf("Failed to get command line for process %d: %v", proc.PID, err)
 			}
@@ -228,7 +337,7 @@ func (c *Config) getCPUStats(ctx context.Context, procMon *sensors.ProcessMonito
 		}
 		if c.info.IncludeEnv {
 			if env, err := proc.EnvironWithContext(ctx); err == nil {
-				ret["env"] = env
+				ret["env"] = c.info.Redactor.RedactEnv(env)
 			} else {
 				c.logger.Debugf("Failed to get environment variables for process %d: %v", proc.PID, err)
 			}
@@ -245,53 +354,128 @@ func (c *Config) getCPUStats(ctx context.Context, procMon *sensors.ProcessMonito
 				c.logger.Debugf("Failed to get memory info for process %d: %v", proc.PID, err)
 			}
 		}
+		if c.info.IncludeOpenFiles {
+			if files, truncated, err := sensors.ReadProcessOpenFiles(proc.PID, c.info.OpenFilesFilter, c.info.MaxOpenFiles); err == nil {
+				paths := make([]interface{}, 0, len(files))
+				for _, file := range files {
+					paths = append(paths, file)
+				}
+				ret["open_file_paths"] = paths
+				ret["open_file_paths_truncated"] = truncated
+			} else {
+				c.logger.Debugf("Failed to list open files for process %d: %v", proc.PID, err)
+			}
+		}
+		if c.info.IncludeUlimits {
+			if limits, err := sensors.ReadProcessLimits(ctx, proc.PID); err == nil {
+				ulimits := make(map[string]interface{}, len(limits))
+				for name, limit := range limits {
+					ulimits[name] = limit.Map()
+				}
+				ret["ulimits"] = ulimits
+			} else {
+				c.logger.Debugf("Failed to get ulimits for process %d: %v", proc.PID, err)
+			}
+		}
+		if c.info.IncludeNetStats {
+			if sockets, err := sensors.ReadProcessSockets(ctx, proc.PID); err == nil {
+				byProtocol := make(map[string]interface{}, len(sockets))
+				for protocol, states := range sockets {
+					byState := make(map[string]interface{}, len(states))
+					for state, count := range states {
+						byState[state] = count
+					}
+					byProtocol[protocol] = byState
+				}
+				ret["sockets"] = byProtocol
+			} else {
+				c.logger.Debugf("Failed to get sockets for process %d: %v", proc.PID, err)
+			}
+			if interfaces, err := sensors.ReadProcessNetDev(ctx, proc.PID); err == nil {
+				netDev := make(map[string]interface{}, len(interfaces))
+				for name, stats := range interfaces {
+					netDev[name] = stats.Map()
+				}
+				ret["interfaces"] = netDev
+			} else {
+				c.logger.Debugf("Failed to get interface counters for process %d: %v", proc.PID, err)
+			}
+		}
+		usage := c.usageFor(proc.PID, tracked.createTime)
+		if c.info.IncludeSmapsRollup {
+			if rollup, err := sensors.ReadProcessSmapsRollup(ctx, proc.PID); err == nil {
+				for key, value := range rollup.Map() {
+					ret[key] = value
+				}
+			} else {
+				c.logger.Debugf("Failed to get smaps_rollup for process %d: %v", proc.PID, err)
+			}
+		}
+		if c.info.IncludeIO {
+			if io, err := sensors.ReadProcessIO(ctx, proc.PID); err == nil {
+				for key, value := range sensors.CalculateProcessIORates(usage.io, io) {
+					ret[key] = value
+				}
+				usage.io = io
+			} else {
+				c.logger.Debugf("Failed to get IO counters for process %d: %v", proc.PID, err)
+			}
+		}
+		if c.info.IncludeCtxSwitches {
+			if switches, err := sensors.ReadProcessContextSwitches(ctx, proc.PID); err == nil {
+				for key, value := range sensors.CalculateContextSwitchRates(usage.ctxSwitches, switches) {
+					ret[key] = value
+				}
+				usage.ctxSwitches = switches
+			} else {
+				c.logger.Debugf("Failed to get context switches for process %d: %v", proc.PID, err)
+			}
+		}
+		if c.info.IncludeFdTrend {
+			if count, err := sensors.ReadProcessFdCount(proc.PID); err == nil {
+				usage.recordFds(now, count, c.info.FdTrendWindow)
+				ret["fd_count"] = count
+				ret["fd_growth_per_min"] = usage.fdGrowthPerMinute()
+				if limits, err := sensors.ReadProcessLimits(ctx, proc.PID); err == nil {
+					if nofile, ok := limits["nofile"]; ok && nofile.Soft > 0 {
+						ret["fd_limit_percent"] = utils.RoundValue(float64(count)/float64(nofile.Soft)*100, 2)
+					}
+				}
+			} else {
+				c.logger.Debugf("Failed to count fds for process %d: %v", proc.PID, err)
+			}
+		}
+		if c.info.IncludeThreadCPU {
+			if threads, err := sensors.ReadProcessThreads(ctx, proc.PID); err == nil {
+				top := make([]interface{}, 0, c.info.ThreadTopN)
+				for _, thread := range sensors.CalculateTopThreads(usage.threads, threads, c.info.ThreadTopN) {
+					top = append(top, thread.Map())
+				}
+				ret["top_threads"] = top
+				usage.threads = threads
+			} else {
+				c.logger.Debugf("Failed to get threads for process %d: %v", proc.PID, err)
+			}
+		}
+		if tree != nil {
+			for key, value := range c.treeReadings(ctx, now, proc.PID, tree, usage) {
+				ret[key] = value
+			}
+		}
 		resp[fmt.Sprintf("%d", proc.Pid)] = ret
 	}
+	c.pruneUsage(seen)
+	c.lifecycle.observe(now, seen)
+	for key, value := range c.lifecycle.readings() {
+		resp[key] = value
+	}
 	return resp, nil
 }
 
-// func resourceToString(r int32) string {
-// 	switch r {
-// 	case process.RLIMIT_AS:
-// 		return "as"
-// 	case process.RLIMIT_CORE:
-// 		return "core"
-// 	case process.RLIMIT_CPU:
-// 		return "cpu"
-// 	case process.RLIMIT_DATA:
-// 		return "data"
-// 	case process.RLIMIT_FSIZE:
-// 		return "fsize"
-// 	case process.RLIMIT_LOCKS:
-// 		return "locks"
-// 	case process.RLIMIT_MEMLOCK:
-// 		return "memlock"
-// 	case process.RLIMIT_MSGQUEUE:
-// 		return "msgqueue"
-// 	case process.RLIMIT_NICE:
-// 		return "nice"
-// 	case process.RLIMIT_NOFILE:
-// 		return "nofile"
-// 	case process.RLIMIT_NPROC:
-// 		return "nproc"
-// 	case process.RLIMIT_RSS:
-// 		return "rss"
-// 	case process.RLIMIT_RTPRIO:
-// 		return "rtprio"
-// 	case process.RLIMIT_RTTIME:
-// 		return "rttime"
-// 	case process.RLIMIT_SIGPENDING:
-// 		return "sigpending"
-// 	case process.RLIMIT_STACK:
-// 		return "stack"
-// 	default:
-// 		return fmt.Sprintf("unknown_%d", r)
-// 	}
-// }
-
 func (c *Config) Close(ctx context.Context) error {
 	c.logger.Infof("Shutting down %s", PrettyName)
 	c.workers.Stop()
+	c.watchdog.close()
 	c.logger.Infof("%s Shutdown complete", PrettyName)
 	return nil
 }
@@ -0,0 +1,87 @@
+package processmonitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// treeSample is the CPU time of every process in a tree, to compute CPU usage from on the next sample
+type treeSample struct {
+	time       time.Time
+	cpuSeconds map[int32]float64
+}
+
+// treeReadings adds up a matched process and its descendants. CPU usage is in percent of one core, so a tree of
+// workers busy on two cores is at 200. Processes that joined the tree since the previous sample have no CPU usage yet.
+func (c *Config) treeReadings(ctx context.Context, now time.Time, root int32, tree *sensors.ProcessTree, usage *processUsage) map[string]interface{} {
+	members := make([]*sensors.TreeProcessUsage, 0)
+	for _, pid := range append([]int32{root}, tree.Descendants(root)...) {
+		member, err := sensors.ReadTreeProcessUsage(ctx, pid)
+		if err != nil {
+			// Exited since the tree was read
+			c.logger.Debugf("Failed to get usage of process %d in the tree of %d: %v", pid, root, err)
+			continue
+		}
+		members = append(members, member)
+	}
+	ret, next := aggregateTree(now, root, members, usage.tree, c.info.IncludeTreeBreakdown)
+	usage.tree = next
+	return ret
+}
+
+// aggregateTree totals the members of the tree rooted at root, prev is nil on the first sample
+func aggregateTree(now time.Time, root int32, members []*sensors.TreeProcessUsage, prev *treeSample, breakdown bool) (map[string]interface{}, *treeSample) {
+	next := &treeSample{time: now, cpuSeconds: make(map[int32]float64, len(members))}
+	seconds := 0.0
+	if prev != nil {
+		seconds = now.Sub(prev.time).Seconds()
+	}
+	var cpu float64
+	var rss uint64
+	var threads int32
+	var fds int
+	children := make(map[string]interface{})
+	for _, member := range members {
+		next.cpuSeconds[member.PID] = member.CPUSeconds
+		memberCPU := 0.0
+		if last, ok := prev.lookup(member.PID); ok && seconds > 0 && member.CPUSeconds > last {
+			memberCPU = (member.CPUSeconds - last) / seconds * 100
+		}
+		cpu += memberCPU
+		rss += member.RSS
+		threads += member.Threads
+		fds += member.Fds
+		if breakdown && member.PID != root {
+			children[fmt.Sprintf("%d", member.PID)] = map[string]interface{}{
+				"name":    member.Name,
+				"cpu":     utils.RoundValue(memberCPU, 2),
+				"rss":     member.RSS,
+				"threads": member.Threads,
+				"fds":     member.Fds,
+			}
+		}
+	}
+	ret := map[string]interface{}{
+		"tree_processes": len(members),
+		"tree_cpu":       utils.RoundValue(cpu, 2),
+		"tree_rss":       rss,
+		"tree_threads":   threads,
+		"tree_fds":       fds,
+	}
+	if breakdown {
+		ret["children"] = children
+	}
+	return ret, next
+}
+
+func (s *treeSample) lookup(pid int32) (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+	cpuSeconds, ok := s.cpuSeconds[pid]
+	return cpuSeconds, ok
+}
@@ -0,0 +1,46 @@
+package processmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+)
+
+func TestAggregateTree(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	members := []*sensors.TreeProcessUsage{
+		{PID: 100, Name: "python3", CPUSeconds: 10, RSS: 50 << 20, Threads: 4, Fds: 20},
+		{PID: 101, Name: "python3", CPUSeconds: 5, RSS: 30 << 20, Threads: 2, Fds: 8},
+	}
+
+	// The first sample has no CPU usage
+	readings, prev := aggregateTree(start, 100, members, nil, true)
+	assert.Equal(t, 0.0, readings["tree_cpu"])
+	assert.Equal(t, 2, readings["tree_processes"])
+	assert.Equal(t, uint64(80<<20), readings["tree_rss"])
+	assert.Equal(t, int32(6), readings["tree_threads"])
+	assert.Equal(t, 28, readings["tree_fds"])
+
+	// The root used half a core, the worker a full core and a new worker joined
+	members = []*sensors.TreeProcessUsage{
+		{PID: 100, Name: "python3", CPUSeconds: 11, RSS: 50 << 20, Threads: 4, Fds: 20},
+		{PID: 101, Name: "python3", CPUSeconds: 7, RSS: 30 << 20, Threads: 2, Fds: 8},
+		{PID: 102, Name: "python3", CPUSeconds: 1, RSS: 10 << 20, Threads: 1, Fds: 3},
+	}
+	readings, _ = aggregateTree(start.Add(2*time.Second), 100, members, prev, true)
+	assert.Equal(t, 150.0, readings["tree_cpu"])
+	assert.Equal(t, 3, readings["tree_processes"])
+	assert.Equal(t, 31, readings["tree_fds"])
+
+	children := readings["children"].(map[string]interface{})
+	require.Len(t, children, 2)
+	assert.Equal(t, map[string]interface{}{"name": "python3", "cpu": 100.0, "rss": uint64(30 << 20), "threads": int32(2), "fds": 8}, children["101"])
+	assert.Equal(t, 0.0, children["102"].(map[string]interface{})["cpu"])
+
+	readings, _ = aggregateTree(start.Add(2*time.Second), 100, members, prev, false)
+	assert.NotContains(t, readings, "children")
+}
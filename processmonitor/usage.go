@@ -0,0 +1,86 @@
+package processmonitor
+
+import (
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+const (
+	defaultFdTrendWindow = 10 * time.Minute
+	defaultThreadTopN    = 5
+)
+
+// fdSample is the number of open fds of a process at a point in time
+type fdSample struct {
+	time  time.Time
+	count int
+}
+
+// processUsage is what the rates and trends of a process are computed from, the previous sample of each counter and
+// the fd counts within the trend window
+type processUsage struct {
+	createTime  time.Time
+	io          *sensors.ProcessIO
+	ctxSwitches *sensors.ContextSwitches
+	fds         []fdSample
+	tree        *treeSample
+	threads     *sensors.ThreadSample
+}
+
+// usageFor returns the usage of a process, starting over if the PID now belongs to a different process
+func (c *Config) usageFor(pid int32, createTime time.Time) *processUsage {
+	if c.usage == nil {
+		c.usage = make(map[int32]*processUsage)
+	}
+	usage, ok := c.usage[pid]
+	if !ok || !usage.createTime.Equal(createTime) {
+		usage = &processUsage{createTime: createTime}
+		c.usage[pid] = usage
+	}
+	return usage
+}
+
+// pruneUsage forgets the processes that are no longer monitored
+func (c *Config) pruneUsage(seen map[int32]trackedProcess) {
+	for pid := range c.usage {
+		if _, ok := seen[pid]; !ok {
+			delete(c.usage, pid)
+		}
+	}
+}
+
+// recordFds adds a sample and drops the ones older than window
+func (u *processUsage) recordFds(now time.Time, count int, window time.Duration) {
+	u.fds = append(u.fds, fdSample{time: now, count: count})
+	start := 0
+	for start < len(u.fds)-1 && now.Sub(u.fds[start].time) > window {
+		start++
+	}
+	u.fds = u.fds[start:]
+}
+
+// fdGrowthPerMinute is the least squares slope of the fd counts in the window. A module that leaks fds keeps growing
+// while one that opens and closes them in bursts averages out to 0.
+func (u *processUsage) fdGrowthPerMinute() float64 {
+	if len(u.fds) < 2 {
+		return 0.0
+	}
+	start := u.fds[0].time
+	n := float64(len(u.fds))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range u.fds {
+		x := sample.time.Sub(start).Minutes()
+		y := float64(sample.count)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0.0
+	}
+	return utils.RoundValue((n*sumXY-sumX*sumY)/denominator, 2)
+}
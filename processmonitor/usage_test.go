@@ -0,0 +1,50 @@
+package processmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFdGrowthPerMinute(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	usage := &processUsage{}
+	usage.recordFds(start, 10, time.Minute)
+	assert.Equal(t, 0.0, usage.fdGrowthPerMinute())
+
+	// Leaking 1 fd every 10 seconds
+	for i := 1; i <= 6; i++ {
+		usage.recordFds(start.Add(time.Duration(i)*10*time.Second), 10+i, time.Minute)
+	}
+	assert.Equal(t, 6.0, usage.fdGrowthPerMinute())
+
+	// Samples older than the window are dropped
+	usage.recordFds(start.Add(70*time.Second), 16, time.Minute)
+	require.Len(t, usage.fds, 7)
+	assert.Equal(t, start.Add(10*time.Second), usage.fds[0].time)
+
+	// Bursts that are closed again average out
+	usage = &processUsage{}
+	for i, count := range []int{10, 20, 10, 20, 10} {
+		usage.recordFds(start.Add(time.Duration(i)*10*time.Second), count, time.Minute)
+	}
+	assert.Equal(t, 0.0, usage.fdGrowthPerMinute())
+}
+
+func TestUsageFor(t *testing.T) {
+	c := &Config{}
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	usage := c.usageFor(100, started)
+	usage.recordFds(started, 10, time.Minute)
+	assert.Same(t, usage, c.usageFor(100, started))
+
+	// The PID was reused by another process
+	assert.Empty(t, c.usageFor(100, started.Add(time.Hour)).fds)
+
+	c.usageFor(200, started)
+	c.pruneUsage(map[int32]trackedProcess{200: {}})
+	assert.Len(t, c.usage, 1)
+	assert.Contains(t, c.usage, int32(200))
+}
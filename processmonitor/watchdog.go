@@ -0,0 +1,436 @@
+package processmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+const (
+	// WatchdogHistoryCommand returns the most recent actions the watchdog took, or would have taken in dry run
+	WatchdogHistoryCommand = "watchdog_history"
+
+	// MetricAbsent is the metric of rules that fire when no process matches
+	MetricAbsent = "absent"
+
+	ActionSignal  = "signal"
+	ActionRenice  = "renice"
+	ActionCommand = "command"
+
+	defaultWatchdogCooldown    = 5 * time.Minute
+	defaultWatchdogHistorySize = 100
+	watchdogCommandTimeout     = 30 * time.Second
+)
+
+// watchdogSignals are the signals a rule can send, by the name kill -s takes
+var watchdogSignals = map[string]bool{"HUP": true, "INT": true, "QUIT": true, "KILL": true, "USR1": true, "USR2": true, "TERM": true}
+
+// watchdogMetrics are the numeric readings of a process a rule can watch, by the include_* option that adds them to
+// the readings. The ones every process has need no option.
+var watchdogMetrics = map[string]string{
+	"cpu":                              "",
+	"threads":                          "",
+	"uptime_seconds":                   "",
+	"open_files":                       "include_open_file_count",
+	"mem_rss":                          "include_mem_info",
+	"mem_hwm":                          "include_mem_info",
+	"mem_data":                         "include_mem_info",
+	"mem_stack":                        "include_mem_info",
+	"mem_swap":                         "include_mem_info",
+	"mem_size":                         "include_mem_info",
+	"mem_pss":                          "include_smaps_rollup",
+	"mem_uss":                          "include_smaps_rollup",
+	"mem_shared_clean":                 "include_smaps_rollup",
+	"mem_shared_dirty":                 "include_smaps_rollup",
+	"mem_private_clean":                "include_smaps_rollup",
+	"mem_private_dirty":                "include_smaps_rollup",
+	"mem_swap_pss":                     "include_smaps_rollup",
+	"io_read_bytes_per_sec":            "include_io",
+	"io_write_bytes_per_sec":           "include_io",
+	"io_rchar_per_sec":                 "include_io",
+	"io_wchar_per_sec":                 "include_io",
+	"ctx_switches_voluntary":           "include_ctx_switches",
+	"ctx_switches_involuntary":         "include_ctx_switches",
+	"ctx_switches_voluntary_per_sec":   "include_ctx_switches",
+	"ctx_switches_involuntary_per_sec": "include_ctx_switches",
+	"fd_count":                         "include_fd_trend",
+	"fd_growth_per_min":                "include_fd_trend",
+	"fd_limit_percent":                 "include_fd_trend",
+	"tree_processes":                   "include_tree",
+	"tree_cpu":                         "include_tree",
+	"tree_rss":                         "include_tree",
+	"tree_threads":                     "include_tree",
+	"tree_fds":                         "include_tree",
+}
+
+// WatchdogRule acts on a process when one of its readings stays above a threshold, or when no process matches
+type WatchdogRule struct {
+	Name string `json:"name"`
+	// Metric is a numeric reading of the process, like cpu, mem_rss, threads or open_files, or absent
+	Metric string `json:"metric"`
+	// Above is the threshold the reading must exceed, unused for absent
+	Above float64 `json:"above,omitempty"`
+	// ForSec is how long the reading must stay above the threshold, or how long no process must match, before acting
+	ForSec int `json:"for_sec,omitempty"`
+	// Action is signal, renice or command. absent rules can only run a command, there is no process to act on.
+	Action string `json:"action"`
+	// Signal is sent by the signal action, defaults to TERM
+	Signal string `json:"signal,omitempty"`
+	// Nice is the niceness the renice action sets, from -20 to 19
+	Nice int `json:"nice,omitempty"`
+	// Command is run by the command action, ex: ["systemctl", "restart", "viam-agent"]
+	Command []string `json:"command,omitempty"`
+	// CooldownSec is the minimum time between two actions of the rule on the same process, defaults to 300
+	CooldownSec int `json:"cooldown_sec,omitempty"`
+}
+
+func (r *WatchdogRule) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if r.Metric == "" {
+		return fmt.Errorf("rule %s: metric is required", r.Name)
+	}
+	if _, ok := watchdogMetrics[r.Metric]; !ok && r.Metric != MetricAbsent {
+		return fmt.Errorf("rule %s: unknown metric %s", r.Name, r.Metric)
+	}
+	if r.ForSec < 0 || r.CooldownSec < 0 {
+		return fmt.Errorf("rule %s: for_sec and cooldown_sec must not be negative", r.Name)
+	}
+	switch r.Action {
+	case ActionSignal:
+		if r.Signal != "" && !watchdogSignals[r.Signal] {
+			return fmt.Errorf("rule %s: unsupported signal %s", r.Name, r.Signal)
+		}
+	case ActionRenice:
+		if r.Nice < -20 || r.Nice > 19 {
+			return fmt.Errorf("rule %s: nice must be between -20 and 19", r.Name)
+		}
+	case ActionCommand:
+		if len(r.Command) == 0 || r.Command[0] == "" {
+			return fmt.Errorf("rule %s: command is required", r.Name)
+		}
+	default:
+		return fmt.Errorf("rule %s: action must be %s, %s or %s", r.Name, ActionSignal, ActionRenice, ActionCommand)
+	}
+	if r.Metric == MetricAbsent && r.Action != ActionCommand {
+		return fmt.Errorf("rule %s: %s rules can only run a command", r.Name, MetricAbsent)
+	}
+	return nil
+}
+
+func (r *WatchdogRule) cooldown() time.Duration {
+	if r.CooldownSec == 0 {
+		return defaultWatchdogCooldown
+	}
+	return time.Duration(r.CooldownSec) * time.Second
+}
+
+// action returns what the rule does to pid, and a description of it in the form of the equivalent command line for
+// the history and logs. Signals and niceness are set with system calls, only the command action runs a command.
+// startTime is when pid started, a process with a different start time reused the PID and is left alone.
+func (r *WatchdogRule) action(pid int32, startTime uint64) (string, func(ctx context.Context) error) {
+	switch r.Action {
+	case ActionSignal:
+		signal := r.Signal
+		if signal == "" {
+			signal = "TERM"
+		}
+		return fmt.Sprintf("kill -s %s %d", signal, pid), func(context.Context) error {
+			if err := checkStartTime(pid, startTime); err != nil {
+				return err
+			}
+			return signalProcess(pid, signal)
+		}
+	case ActionRenice:
+		return fmt.Sprintf("renice -n %d -p %d", r.Nice, pid), func(context.Context) error {
+			if err := checkStartTime(pid, startTime); err != nil {
+				return err
+			}
+			return reniceProcess(pid, r.Nice)
+		}
+	default:
+		cmd := utils.Command{Name: r.Command[0], Args: r.Command[1:], Timeout: watchdogCommandTimeout}
+		return cmd.String(), func(ctx context.Context) error {
+			_, err := cmd.Run(ctx)
+			return err
+		}
+	}
+}
+
+// checkStartTime returns an error unless pid is still the process that started at startTime
+func checkStartTime(pid int32, startTime uint64) error {
+	current, err := sensors.ReadProcessStartTime(pid)
+	if err != nil {
+		return fmt.Errorf("process %d exited: %w", pid, err)
+	}
+	if current != startTime {
+		return fmt.Errorf("process %d exited and its PID was reused", pid)
+	}
+	return nil
+}
+
+// WatchdogAction is an entry of the audit history
+type WatchdogAction struct {
+	Time      time.Time `json:"time"`
+	Rule      string    `json:"rule"`
+	Action    string    `json:"action"`
+	PID       int32     `json:"pid,omitempty"`
+	Metric    string    `json:"metric"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Command   string    `json:"command"`
+	DryRun    bool      `json:"dry_run"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type WatchdogHistoryRequest struct {
+	Limit int `json:"limit"`
+}
+
+type WatchdogHistoryResponse struct {
+	Actions []WatchdogAction `json:"actions"`
+}
+
+// pendingAction is an action the watchdog decided to take, it runs once the lock is released
+type pendingAction struct {
+	action  WatchdogAction
+	run     func(ctx context.Context) error
+	ctx     context.Context // The context of the rules that took the action
+	running *sync.WaitGroup // The actions of the rules that took the action
+}
+
+// watchdog evaluates the rules against every sample. A nil watchdog does nothing.
+type watchdog struct {
+	mu          sync.Mutex
+	running     *sync.WaitGroup    // The actions of the current rules that have not finished yet
+	ctx         context.Context    // The actions of the current rules run on, cancelled when the rules are replaced
+	cancel      context.CancelFunc // Cancels ctx
+	logger      logging.Logger
+	rules       []WatchdogRule
+	dryRun      bool
+	breaching   map[string]time.Time // When the reading of a rule and process went above the threshold
+	lastAction  map[string]time.Time // When a rule last acted on a process
+	absentSince time.Time
+	missing     map[string]bool // Rules whose metric was not in the readings, so it is only logged once
+	history     utils.CappedCollection[WatchdogAction]
+}
+
+func newWatchdog(logger logging.Logger, size int) *watchdog {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watchdog{logger: logger, running: &sync.WaitGroup{}, ctx: ctx, cancel: cancel, history: utils.NewCappedCollection[WatchdogAction](size)}
+}
+
+// configure replaces the rules and forgets their state, the history is kept. The actions of the previous rules are
+// cancelled and waited for, so a slow command does not hold up the reconfigure.
+func (w *watchdog) configure(rules []WatchdogRule, dryRun bool) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	cancel, running := w.cancel, w.running
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.running = &sync.WaitGroup{}
+	w.rules = rules
+	w.dryRun = dryRun
+	w.breaching = make(map[string]time.Time)
+	w.lastAction = make(map[string]time.Time)
+	w.absentSince = time.Time{}
+	w.missing = make(map[string]bool)
+	w.mu.Unlock()
+	cancel()
+	running.Wait()
+}
+
+// evaluate checks the rules against readings, keyed by PID like the sensor's readings, and acts on the ones that have
+// been breached for long enough and are not cooling down. The actions run in the background, so a slow command does
+// not hold up sampling, and are added to the history when they finish.
+func (w *watchdog) evaluate(now time.Time, readings map[string]interface{}) {
+	if w == nil {
+		return
+	}
+	for _, pending := range w.check(now, readings) {
+		go w.run(pending)
+	}
+}
+
+// close cancels the actions that are running and waits for them to return
+func (w *watchdog) close() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	cancel, running := w.cancel, w.running
+	w.mu.Unlock()
+	cancel()
+	running.Wait()
+}
+
+// check updates the state of the rules with readings and returns the actions to take. Dry run actions are only
+// recorded.
+func (w *watchdog) check(now time.Time, readings map[string]interface{}) []pendingAction {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.rules) == 0 {
+		return nil
+	}
+
+	procs := make(map[int32]map[string]interface{})
+	for _, value := range readings {
+		if proc, ok := value.(map[string]interface{}); ok {
+			if pid, ok := proc["pid"].(int32); ok {
+				procs[pid] = proc
+			}
+		}
+	}
+	pids := make([]int32, 0, len(procs))
+	for pid := range procs {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	if len(procs) > 0 {
+		w.absentSince = time.Time{}
+	} else if w.absentSince.IsZero() {
+		w.absentSince = now
+	}
+
+	var pending []pendingAction
+	breaching := make(map[string]time.Time)
+	for i := range w.rules {
+		rule := &w.rules[i]
+		if rule.Metric == MetricAbsent {
+			if len(procs) == 0 {
+				absent := now.Sub(w.absentSince)
+				if absent >= time.Duration(rule.ForSec)*time.Second {
+					pending = w.act(pending, now, rule, 0, absent.Seconds())
+				}
+			}
+			continue
+		}
+		for _, pid := range pids {
+			value, ok := toFloat(procs[pid][rule.Metric])
+			if !ok {
+				if !w.missing[rule.Name] {
+					w.missing[rule.Name] = true
+					w.logger.Warnf("Watchdog rule %s: process %d has no numeric %s reading", rule.Name, pid, rule.Metric)
+				}
+				continue
+			}
+			if value <= rule.Above {
+				continue
+			}
+			key := fmt.Sprintf("%s/%d", rule.Name, pid)
+			since, ok := w.breaching[key]
+			if !ok {
+				since = now
+			}
+			breaching[key] = since
+			if now.Sub(since) >= time.Duration(rule.ForSec)*time.Second {
+				pending = w.act(pending, now, rule, pid, value)
+			}
+		}
+	}
+	w.breaching = breaching
+	return pending
+}
+
+// act adds the action of rule on pid to pending unless it is cooling down. Commands are not tied to a process, so
+// they cool down for the rule as a whole.
+func (w *watchdog) act(pending []pendingAction, now time.Time, rule *WatchdogRule, pid int32, value float64) []pendingAction {
+	key := rule.Name
+	if rule.Action != ActionCommand {
+		key = fmt.Sprintf("%s/%d", rule.Name, pid)
+	}
+	if last, ok := w.lastAction[key]; ok && now.Sub(last) < rule.cooldown() {
+		return pending
+	}
+	var startTime uint64
+	if rule.Action != ActionCommand {
+		var err error
+		if startTime, err = sensors.ReadProcessStartTime(pid); err != nil {
+			w.logger.Debugf("Watchdog rule %s: process %d exited before it could be acted on: %v", rule.Name, pid, err)
+			return pending
+		}
+	}
+	w.lastAction[key] = now
+
+	description, run := rule.action(pid, startTime)
+	action := WatchdogAction{
+		Time:      now,
+		Rule:      rule.Name,
+		Action:    rule.Action,
+		PID:       pid,
+		Metric:    rule.Metric,
+		Value:     utils.RoundValue(value, 2),
+		Threshold: rule.Above,
+		Command:   description,
+		DryRun:    w.dryRun,
+	}
+	if w.dryRun {
+		w.logger.Infof("Watchdog rule %s would run %q, %s is %v (dry run)", rule.Name, description, rule.Metric, action.Value)
+		w.history.Push(action)
+		return pending
+	}
+	w.logger.Warnf("Watchdog rule %s running %q, %s is %v", rule.Name, description, rule.Metric, action.Value)
+	w.running.Add(1)
+	return append(pending, pendingAction{action: action, run: run, ctx: w.ctx, running: w.running})
+}
+
+// run takes an action and records how it went
+func (w *watchdog) run(pending pendingAction) {
+	defer pending.running.Done()
+	ctx, cancel := context.WithTimeout(pending.ctx, watchdogCommandTimeout)
+	defer cancel()
+	if err := pending.run(ctx); err != nil {
+		w.logger.Errorf("Watchdog rule %s failed: %v", pending.action.Rule, err)
+		pending.action.Error = err.Error()
+	}
+	w.history.Push(pending.action)
+}
+
+// actions returns up to limit of the most recent actions, oldest first. A limit of 0 returns everything.
+func (w *watchdog) actions(limit int) []WatchdogAction {
+	if w == nil {
+		return []WatchdogAction{}
+	}
+	actions := w.history.Items()
+	if limit > 0 && limit < len(actions) {
+		actions = actions[len(actions)-limit:]
+	}
+	return actions
+}
+
+func (c *Config) watchdogHistory(ctx context.Context, req WatchdogHistoryRequest) (WatchdogHistoryResponse, error) {
+	if req.Limit < 0 {
+		return WatchdogHistoryResponse{}, errors.New("limit must not be negative")
+	}
+	return WatchdogHistoryResponse{Actions: c.watchdog.actions(req.Limit)}, nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,18 @@
+package processmonitor
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// signalProcess sends a signal, by the name kill -s takes, to pid
+func signalProcess(pid int32, signal string) error {
+	return syscall.Kill(int(pid), unix.SignalNum("SIG"+signal))
+}
+
+// reniceProcess sets the niceness of pid. Like renice, only the thread with the ID of the process is changed, the
+// threads it already started keep theirs.
+func reniceProcess(pid int32, nice int) error {
+	return unix.Setpriority(unix.PRIO_PROCESS, int(pid), nice)
+}
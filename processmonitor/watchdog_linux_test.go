@@ -0,0 +1,109 @@
+package processmonitor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+	"golang.org/x/sys/unix"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+)
+
+// startSleep starts a process for the watchdog to act on, it is killed when the test ends
+func startSleep(t *testing.T) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep could not be started: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return cmd
+}
+
+func TestWatchdog_Signal(t *testing.T) {
+	proc := startSleep(t)
+	pid := int32(proc.Process.Pid)
+	w := newWatchdog(logging.NewTestLogger(t), 10)
+	w.configure([]WatchdogRule{{Name: "rss", Metric: "mem_rss", Above: 100 << 20, ForSec: 10, Action: ActionSignal, CooldownSec: 60}}, false)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	small := map[string]interface{}{"pid": int32(1), "mem_rss": uint64(50 << 20)}
+	large := map[string]interface{}{"pid": pid, "mem_rss": uint64(200 << 20)}
+
+	// Above the limit, but not for long enough
+	w.evaluate(start, procReadings(small, large))
+	w.evaluate(start.Add(5*time.Second), procReadings(small, large))
+	waitForActions(w)
+	assert.Empty(t, w.actions(0))
+
+	w.evaluate(start.Add(10*time.Second), procReadings(small, large))
+	waitForActions(w)
+	err := proc.Wait()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terminated")
+
+	// Cooling down, then the process is gone, so there is nothing to signal
+	w.evaluate(start.Add(20*time.Second), procReadings(small, large))
+	waitForActions(w)
+	assert.Len(t, w.actions(0), 1)
+	w.evaluate(start.Add(71*time.Second), procReadings(small, large))
+	waitForActions(w)
+
+	actions := w.actions(0)
+	require.Len(t, actions, 1)
+	assert.Equal(t, WatchdogAction{
+		Time:      start.Add(10 * time.Second),
+		Rule:      "rss",
+		Action:    ActionSignal,
+		PID:       pid,
+		Metric:    "mem_rss",
+		Value:     float64(200 << 20),
+		Threshold: float64(100 << 20),
+		Command:   fmt.Sprintf("kill -s TERM %d", pid),
+	}, actions[0])
+}
+
+func TestWatchdog_PIDReused(t *testing.T) {
+	proc := startSleep(t)
+	pid := int32(proc.Process.Pid)
+	startTime, err := sensors.ReadProcessStartTime(pid)
+	require.NoError(t, err)
+	rule := WatchdogRule{Name: "cpu", Metric: "cpu", Above: 80, Action: ActionSignal, Signal: "KILL"}
+
+	// The process that was sampled started at another time, the one with its PID now must not be signalled
+	_, run := rule.action(pid, startTime+1)
+	assert.ErrorContains(t, run(context.Background()), "PID was reused")
+	assert.NoError(t, proc.Process.Signal(syscall.Signal(0)))
+
+	_, run = rule.action(pid, startTime)
+	assert.NoError(t, run(context.Background()))
+	assert.Error(t, proc.Wait())
+}
+
+func TestWatchdog_Renice(t *testing.T) {
+	proc := startSleep(t)
+	pid := int32(proc.Process.Pid)
+	w := newWatchdog(logging.NewTestLogger(t), 10)
+	w.configure([]WatchdogRule{{Name: "cpu", Metric: "cpu", Above: 80, Action: ActionRenice, Nice: 5}}, false)
+
+	w.evaluate(time.Now(), procReadings(map[string]interface{}{"pid": pid, "cpu": 95.0}))
+	waitForActions(w)
+	actions := w.actions(0)
+	require.Len(t, actions, 1)
+	assert.Empty(t, actions[0].Error)
+	assert.Equal(t, fmt.Sprintf("renice -n 5 -p %d", pid), actions[0].Command)
+	// The system call returns 20 - nice
+	prio, err := unix.Getpriority(unix.PRIO_PROCESS, int(pid))
+	require.NoError(t, err)
+	assert.Equal(t, 5, 20-prio)
+}
@@ -0,0 +1,217 @@
+package processmonitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+)
+
+func useFakeRunner(t *testing.T) *utilstest.FakeCommandRunner {
+	t.Helper()
+	runner := utilstest.NewFakeCommandRunner()
+	previous := utils.SetCommandRunner(runner)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	return runner
+}
+
+// waitForActions blocks until the actions of the current rules finish
+func waitForActions(w *watchdog) {
+	w.mu.Lock()
+	running := w.running
+	w.mu.Unlock()
+	running.Wait()
+}
+
+func procReadings(procs ...map[string]interface{}) map[string]interface{} {
+	ret := map[string]interface{}{"restart_count": 0}
+	for _, proc := range procs {
+		ret[fmt.Sprintf("%d", proc["pid"])] = proc
+	}
+	return ret
+}
+
+func TestWatchdog_BreachMustBeContinuous(t *testing.T) {
+	runner := useFakeRunner(t)
+	w := newWatchdog(logging.NewTestLogger(t), 10)
+	w.configure([]WatchdogRule{{Name: "cpu", Metric: "cpu", Above: 80, ForSec: 10, Action: ActionCommand, Command: []string{"systemctl", "restart", "perception"}}}, false)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	w.evaluate(start, procReadings(map[string]interface{}{"pid": int32(300), "cpu": 95.0}))
+	w.evaluate(start.Add(5*time.Second), procReadings(map[string]interface{}{"pid": int32(300), "cpu": 20.0}))
+	w.evaluate(start.Add(10*time.Second), procReadings(map[string]interface{}{"pid": int32(300), "cpu": 95.0}))
+	waitForActions(w)
+	assert.Empty(t, runner.Calls())
+
+	runner.On(utilstest.FakeResponse{ExitCode: 1, Stderr: "permission denied"}, "systemctl", "restart", "perception")
+	w.evaluate(start.Add(20*time.Second), procReadings(map[string]interface{}{"pid": int32(300), "cpu": 95.0}))
+	waitForActions(w)
+	assert.Equal(t, []string{"systemctl restart perception"}, runner.Calls())
+	actions := w.actions(0)
+	require.Len(t, actions, 1)
+	assert.Contains(t, actions[0].Error, "permission denied")
+}
+
+// blockingRunner runs every command until release is closed, or the command's context is done
+type blockingRunner struct {
+	*utilstest.FakeCommandRunner
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRunner) Run(ctx context.Context, cmd utils.Command) ([]byte, error) {
+	r.started <- struct{}{}
+	select {
+	case <-r.release:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestWatchdog_ActionsRunInTheBackground(t *testing.T) {
+	runner := &blockingRunner{FakeCommandRunner: utilstest.NewFakeCommandRunner(), started: make(chan struct{}), release: make(chan struct{})}
+	previous := utils.SetCommandRunner(runner)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	w := newWatchdog(logging.NewTestLogger(t), 10)
+	w.configure([]WatchdogRule{{Name: "restart", Metric: MetricAbsent, Action: ActionCommand, Command: []string{"systemctl", "restart", "viam-agent"}}}, false)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	w.evaluate(now, procReadings())
+	<-runner.started
+	// The next sample is not held up by the command
+	w.evaluate(now.Add(time.Second), procReadings())
+	assert.Empty(t, w.actions(0))
+
+	// A reconfigure cancels it instead of waiting for the command's timeout
+	configured := make(chan struct{})
+	go func() {
+		w.configure(nil, false)
+		close(configured)
+	}()
+	select {
+	case <-configured:
+	case <-time.After(time.Second):
+		t.Fatal("configure waited for the running action")
+	}
+	actions := w.actions(0)
+	require.Len(t, actions, 1)
+	assert.Contains(t, actions[0].Error, context.Canceled.Error())
+}
+
+func TestWatchdog_CloseCancelsActions(t *testing.T) {
+	runner := &blockingRunner{FakeCommandRunner: utilstest.NewFakeCommandRunner(), started: make(chan struct{}), release: make(chan struct{})}
+	previous := utils.SetCommandRunner(runner)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+	w := newWatchdog(logging.NewTestLogger(t), 10)
+	w.configure([]WatchdogRule{{Name: "restart", Metric: MetricAbsent, Action: ActionCommand, Command: []string{"systemctl", "restart", "viam-agent"}}}, false)
+
+	w.evaluate(time.Now(), procReadings())
+	<-runner.started
+	w.close()
+	actions := w.actions(0)
+	require.Len(t, actions, 1)
+	assert.Contains(t, actions[0].Error, context.Canceled.Error())
+}
+
+func TestWatchdog_Absent(t *testing.T) {
+	runner := useFakeRunner(t)
+	runner.OnOutput("", "systemctl", "restart", "viam-agent")
+	w := newWatchdog(logging.NewTestLogger(t), 10)
+	w.configure([]WatchdogRule{{Name: "restart", Metric: MetricAbsent, ForSec: 30, Action: ActionCommand, Command: []string{"systemctl", "restart", "viam-agent"}, CooldownSec: 60}}, false)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	w.evaluate(start, procReadings(map[string]interface{}{"pid": int32(100)}))
+	w.evaluate(start.Add(10*time.Second), procReadings())
+	w.evaluate(start.Add(30*time.Second), procReadings())
+	waitForActions(w)
+	assert.Empty(t, runner.Calls())
+	w.evaluate(start.Add(40*time.Second), procReadings())
+	waitForActions(w)
+	assert.Equal(t, []string{"systemctl restart viam-agent"}, runner.Calls())
+	assert.Equal(t, 30.0, w.actions(0)[0].Value)
+
+	// Came back, then disappeared again within the cooldown
+	w.evaluate(start.Add(50*time.Second), procReadings(map[string]interface{}{"pid": int32(101)}))
+	w.evaluate(start.Add(60*time.Second), procReadings())
+	w.evaluate(start.Add(95*time.Second), procReadings())
+	waitForActions(w)
+	assert.Len(t, runner.Calls(), 1)
+	w.evaluate(start.Add(100*time.Second), procReadings())
+	waitForActions(w)
+	assert.Len(t, runner.Calls(), 2)
+}
+
+func TestWatchdog_DryRun(t *testing.T) {
+	runner := useFakeRunner(t)
+	ctx := context.Background()
+	c := &Config{watchdog: newWatchdog(logging.NewTestLogger(t), 10)}
+	c.commands = c.newCommandDispatcher()
+	c.watchdog.configure([]WatchdogRule{{Name: "threads", Metric: "threads", Above: 100, Action: ActionSignal, Signal: "KILL"}}, true)
+
+	// The test itself, dry runs must not act on it
+	pid := int32(os.Getpid())
+	c.watchdog.evaluate(time.Now(), procReadings(map[string]interface{}{"pid": pid, "threads": int32(500)}))
+	assert.Empty(t, runner.Calls())
+
+	res, err := c.DoCommand(ctx, map[string]interface{}{utils.CommandKey: WatchdogHistoryCommand})
+	require.NoError(t, err)
+	actions := res["actions"].([]interface{})
+	require.Len(t, actions, 1)
+	action := actions[0].(map[string]interface{})
+	assert.Equal(t, true, action["dry_run"])
+	assert.Equal(t, fmt.Sprintf("kill -s KILL %d", pid), action["command"])
+	assert.Equal(t, 500.0, action["value"])
+}
+
+func TestWatchdogRule_Validate(t *testing.T) {
+	valid := []WatchdogRule{
+		{Name: "a", Metric: "cpu", Above: 90, Action: ActionSignal},
+		{Name: "b", Metric: "mem_rss", Above: 1, Action: ActionSignal, Signal: "KILL"},
+		{Name: "c", Metric: "cpu", Above: 50, Action: ActionRenice, Nice: 19},
+		{Name: "d", Metric: MetricAbsent, ForSec: 30, Action: ActionCommand, Command: []string{"systemctl", "restart", "viam-agent"}},
+	}
+	for _, rule := range valid {
+		assert.NoError(t, rule.Validate(), rule.Name)
+	}
+	invalid := []WatchdogRule{
+		{Metric: "cpu", Action: ActionSignal},
+		{Name: "no metric", Action: ActionSignal},
+		{Name: "bad action", Metric: "cpu", Action: "reboot"},
+		{Name: "bad signal", Metric: "cpu", Action: ActionSignal, Signal: "STOP"},
+		{Name: "bad nice", Metric: "cpu", Action: ActionRenice, Nice: 20},
+		{Name: "no command", Metric: "cpu", Action: ActionCommand},
+		{Name: "absent signal", Metric: MetricAbsent, Action: ActionSignal},
+		{Name: "negative", Metric: "cpu", Action: ActionSignal, ForSec: -1},
+		{Name: "unknown metric", Metric: "mem_rsss", Above: 1, Action: ActionSignal},
+		{Name: "not numeric", Metric: "cmdline", Action: ActionSignal},
+	}
+	for _, rule := range invalid {
+		assert.Error(t, rule.Validate(), rule.Name)
+	}
+}
+
+func TestValidate_RuleMetrics(t *testing.T) {
+	rss := WatchdogRule{Name: "rss", Metric: "mem_rss", Above: 100 << 20, Action: ActionSignal}
+	files := WatchdogRule{Name: "files", Metric: "open_files", Above: 1000, Action: ActionSignal}
+	cpu := WatchdogRule{Name: "cpu", Metric: "cpu", Above: 90, Action: ActionRenice, Nice: 10}
+
+	_, _, err := (&ComponentConfig{Name: "perception", Rules: []WatchdogRule{cpu}}).Validate("")
+	assert.NoError(t, err)
+	_, _, err = (&ComponentConfig{Name: "perception", IncludeMemInfo: true, IncludeOpenFileCount: true, Rules: []WatchdogRule{rss, files}}).Validate("")
+	assert.NoError(t, err)
+
+	// The rule could never fire without the readings it watches
+	_, _, err = (&ComponentConfig{Name: "perception", Rules: []WatchdogRule{rss}}).Validate("")
+	assert.ErrorContains(t, err, "include_mem_info")
+	_, _, err = (&ComponentConfig{Name: "perception", IncludeMemInfo: true, Rules: []WatchdogRule{files}}).Validate("")
+	assert.ErrorContains(t, err, "include_open_file_count")
+}
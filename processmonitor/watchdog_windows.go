@@ -0,0 +1,13 @@
+package processmonitor
+
+import (
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+func signalProcess(_ int32, _ string) error {
+	return utils.ErrPlatformNotSupported
+}
+
+func reniceProcess(_ int32, _ int) error {
+	return utils.ErrPlatformNotSupported
+}
@@ -0,0 +1,61 @@
+package processscheduler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+)
+
+type ComponentConfig struct {
+	Name              string                   `json:"name"`
+	ExecutablePath    string                   `json:"executable_path"`
+	Selector          *sensors.ProcessSelector `json:"selector,omitempty"`
+	CPUs              string                   `json:"cpus,omitempty"`        // CPU list the threads may run on, ex: 4-7 or 0,2
+	Nice              *int                     `json:"nice,omitempty"`        // Niceness from -20 to 19
+	IOClass           string                   `json:"io_class,omitempty"`    // realtime, best-effort or idle
+	IOLevel           *int                     `json:"io_level,omitempty"`    // Level within io_class from 0 to 7, defaults to 4
+	Policy            string                   `json:"policy,omitempty"`      // other, fifo, rr, batch or idle
+	RTPriority        int                      `json:"rt_priority,omitempty"` // Priority from 1 to 99, required by fifo and rr
+	DryRun            bool                     `json:"dry_run"`               // Report drift without correcting it
+	SleepTimeMs       int                      `json:"sleep_time_ms"`         // Sleep time in milliseconds between enforcements
+	DisablePIDCaching bool                     `json:"disable_pid_caching"`   // Disable caching of PID to avoid repeated lookups
+}
+
+// selector returns the configured selector, name and executable_path are shorthands for a selector with just that field
+func (conf *ComponentConfig) selector() *sensors.ProcessSelector {
+	if conf.Selector != nil {
+		return conf.Selector
+	}
+	return &sensors.ProcessSelector{Name: conf.Name, ExecutablePath: conf.ExecutablePath}
+}
+
+func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
+	set := 0
+	for _, ok := range []bool{conf.Name != "", conf.ExecutablePath != "", conf.Selector != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set == 0 {
+		return nil, nil, errors.New("executable_path, name or selector is required")
+	}
+	if set > 1 {
+		return nil, nil, errors.New("only one of executable_path, name or selector is allowed")
+	}
+	if conf.Selector != nil {
+		if err := conf.Selector.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+	if _, err := newSchedule(conf); err != nil {
+		return nil, nil, err
+	}
+	if conf.ExecutablePath != "" {
+		if _, err := os.Stat(conf.ExecutablePath); os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("executable_path does not exist: %s", conf.ExecutablePath)
+		}
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,295 @@
+package processscheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+)
+
+// The settings a schedule enforces, as they are reported in the drift readings
+const (
+	SettingCPUs       = "cpus"
+	SettingNice       = "nice"
+	SettingIOPriority = "io_priority"
+	SettingPolicy     = "policy"
+
+	defaultIOLevel = 4
+)
+
+// policies are the scheduling policies a schedule can set
+var policies = map[string]bool{
+	sensors.SchedOther: true,
+	sensors.SchedFIFO:  true,
+	sensors.SchedRR:    true,
+	sensors.SchedBatch: true,
+	sensors.SchedIdle:  true,
+}
+
+// ioClasses are the IO classes a schedule can set
+var ioClasses = map[string]bool{
+	sensors.IOClassRealtime:   true,
+	sensors.IOClassBestEffort: true,
+	sensors.IOClassIdle:       true,
+}
+
+// schedule is how every thread of the matched processes should be scheduled, nil and empty settings are left alone
+type schedule struct {
+	cpus       []int
+	nice       *int
+	io         *sensors.IOPriority
+	policy     string
+	rtPriority int
+}
+
+func newSchedule(conf *ComponentConfig) (*schedule, error) {
+	s := &schedule{nice: conf.Nice, policy: conf.Policy, rtPriority: conf.RTPriority}
+	if conf.CPUs != "" {
+		cpus, err := sensors.ParseCPUList(conf.CPUs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpus: %w", err)
+		}
+		s.cpus = cpus
+	}
+	if s.nice != nil && (*s.nice < -20 || *s.nice > 19) {
+		return nil, errors.New("nice must be between -20 and 19")
+	}
+	if conf.IOClass != "" {
+		if !ioClasses[conf.IOClass] {
+			return nil, fmt.Errorf("io_class must be %s, %s or %s", sensors.IOClassRealtime, sensors.IOClassBestEffort, sensors.IOClassIdle)
+		}
+		s.io = &sensors.IOPriority{Class: conf.IOClass}
+		if conf.IOClass == sensors.IOClassIdle {
+			if conf.IOLevel != nil {
+				return nil, errors.New("io_level is not supported by the idle io_class")
+			}
+		} else {
+			s.io.Level = defaultIOLevel
+			if conf.IOLevel != nil {
+				s.io.Level = *conf.IOLevel
+			}
+			if s.io.Level < 0 || s.io.Level > 7 {
+				return nil, errors.New("io_level must be between 0 and 7")
+			}
+		}
+	} else if conf.IOLevel != nil {
+		return nil, errors.New("io_level requires io_class")
+	}
+	if s.policy != "" {
+		if !policies[s.policy] {
+			return nil, fmt.Errorf("policy must be %s, %s, %s, %s or %s", sensors.SchedOther, sensors.SchedFIFO, sensors.SchedRR, sensors.SchedBatch, sensors.SchedIdle)
+		}
+	}
+	if s.realtime() {
+		if s.rtPriority < 1 || s.rtPriority > 99 {
+			return nil, fmt.Errorf("rt_priority must be between 1 and 99 for the %s policy", s.policy)
+		}
+	} else if s.rtPriority != 0 {
+		return nil, fmt.Errorf("rt_priority requires the %s or %s policy", sensors.SchedFIFO, sensors.SchedRR)
+	}
+	if s.cpus == nil && s.nice == nil && s.io == nil && s.policy == "" {
+		return nil, errors.New("at least one of cpus, nice, io_class or policy is required")
+	}
+	return s, nil
+}
+
+func (s *schedule) realtime() bool {
+	return s.policy == sensors.SchedFIFO || s.policy == sensors.SchedRR
+}
+
+// drift returns the settings thread is not scheduled with. io is only looked at when the schedule sets an IO class.
+func (s *schedule) drift(thread sensors.ThreadSchedule, io sensors.IOPriority) []string {
+	ret := []string{}
+	if s.cpus != nil && !slices.Equal(s.cpus, thread.CPUs) {
+		ret = append(ret, SettingCPUs)
+	}
+	if s.nice != nil && *s.nice != thread.Nice {
+		ret = append(ret, SettingNice)
+	}
+	if s.io != nil && *s.io != io {
+		ret = append(ret, SettingIOPriority)
+	}
+	if s.policy != "" && (s.policy != thread.Policy || s.rtPriority != thread.RTPriority) {
+		ret = append(ret, SettingPolicy)
+	}
+	return ret
+}
+
+// apply corrects setting on tid
+func (s *schedule) apply(setting string, tid int32) error {
+	var err error
+	switch setting {
+	case SettingCPUs:
+		err = sensors.SetThreadAffinity(tid, s.cpus)
+	case SettingNice:
+		err = sensors.SetThreadNice(tid, *s.nice)
+	case SettingIOPriority:
+		err = sensors.SetIOPriority(tid, *s.io)
+	default:
+		err = sensors.SetThreadPolicy(tid, s.policy, s.rtPriority)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set the %s of thread %d: %w", setting, tid, err)
+	}
+	return nil
+}
+
+// Map is the desired schedule, reported next to the actual one
+func (s *schedule) Map() map[string]interface{} {
+	ret := make(map[string]interface{})
+	if s.cpus != nil {
+		ret["cpus"] = sensors.FormatCPUList(s.cpus)
+	}
+	if s.nice != nil {
+		ret["nice"] = *s.nice
+	}
+	if s.io != nil {
+		ret["io_priority"] = s.io.String()
+	}
+	if s.policy != "" {
+		ret["policy"] = s.policy
+		ret["rt_priority"] = s.rtPriority
+	}
+	return ret
+}
+
+// processState is what the scheduler remembers about a process between enforcements
+type processState struct {
+	createTime  time.Time
+	corrections int
+	failures    int
+	lastError   string
+}
+
+// stateFor returns the state of a process, starting over if the PID now belongs to a different process
+func (c *Config) stateFor(pid int32, createTime time.Time) *processState {
+	if c.states == nil {
+		c.states = make(map[int32]*processState)
+	}
+	state, ok := c.states[pid]
+	if !ok || !state.createTime.Equal(createTime) {
+		if ok {
+			c.logger.Infof("Process %d restarted, applying the schedule again", pid)
+		}
+		state = &processState{createTime: createTime}
+		c.states[pid] = state
+	}
+	return state
+}
+
+// pruneStates forgets the processes that no longer match
+func (c *Config) pruneStates(seen map[int32]bool) {
+	for pid := range c.states {
+		if !seen[pid] {
+			delete(c.states, pid)
+		}
+	}
+}
+
+// threadIOPriority reads the IO priority of a thread when the schedule sets one
+func (c *Config) threadIOPriority(tid int32) (sensors.IOPriority, error) {
+	if c.schedule.io == nil {
+		return sensors.IOPriority{}, nil
+	}
+	return sensors.ReadIOPriority(tid)
+}
+
+// enforce compares every thread of a process to the schedule and corrects the ones that drifted, unless dry run is
+// set. The readings report the drift that was found and whether the process is in sync after the corrections.
+func (c *Config) enforce(ctx context.Context, pid int32, name string, createTime time.Time) (map[string]interface{}, error) {
+	state := c.stateFor(pid, createTime)
+	threads, err := sensors.ReadProcessSchedules(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := make(map[string]interface{})
+	drifted, outOfSync := 0, 0
+	var main map[string]interface{}
+	for _, thread := range threads {
+		io, err := c.threadIOPriority(thread.TID)
+		if err != nil {
+			// The thread exited, or the kernel has no IO priorities
+			c.fail(state, pid, fmt.Errorf("failed to read the IO priority of thread %d: %w", thread.TID, err))
+			outOfSync++
+			continue
+		}
+		settings := c.schedule.drift(thread, io)
+		if len(settings) > 0 {
+			drifted++
+			for _, setting := range settings {
+				count, _ := drift[setting].(int)
+				drift[setting] = count + 1
+			}
+			if !c.dryRun {
+				thread, io, settings = c.correct(ctx, state, pid, thread, settings)
+			}
+			if len(settings) > 0 {
+				outOfSync++
+			}
+		}
+		if thread.TID == pid {
+			main = thread.Map()
+			if c.schedule.io != nil {
+				main["io_priority"] = io.String()
+			}
+		}
+	}
+
+	ret := map[string]interface{}{
+		"pid":             pid,
+		"name":            name,
+		"threads":         len(threads),
+		"drifted_threads": drifted,
+		"drift":           drift,
+		"in_sync":         outOfSync == 0,
+		"corrections":     state.corrections,
+		"failures":        state.failures,
+	}
+	if main != nil {
+		ret["actual"] = main
+	}
+	if state.lastError != "" {
+		ret["last_error"] = state.lastError
+	}
+	return ret, nil
+}
+
+// correct sets the drifted settings of a thread and reads it back, returning what it is now and the settings that
+// are still off
+func (c *Config) correct(ctx context.Context, state *processState, pid int32, thread sensors.ThreadSchedule, settings []string) (sensors.ThreadSchedule, sensors.IOPriority, []string) {
+	for _, setting := range settings {
+		c.logger.Debugf("Correcting %s of thread %d of process %d", setting, thread.TID, pid)
+		if err := c.schedule.apply(setting, thread.TID); err != nil {
+			c.fail(state, pid, err)
+			continue
+		}
+		state.corrections++
+	}
+	after, err := sensors.ReadThreadSchedule(ctx, pid, thread.TID)
+	if err != nil {
+		// The thread exited, there is nothing left to schedule
+		return thread, sensors.IOPriority{}, nil
+	}
+	io, err := c.threadIOPriority(thread.TID)
+	if err != nil {
+		c.fail(state, pid, err)
+		return after, io, settings
+	}
+	return after, io, c.schedule.drift(after, io)
+}
+
+// fail records an error, only the first one of a process is logged as a warning so a missing capability does not
+// flood the logs every sample
+func (c *Config) fail(state *processState, pid int32, err error) {
+	if state.failures == 0 {
+		c.logger.Warnf("Failed to schedule process %d: %v", pid, err)
+	} else {
+		c.logger.Debugf("Failed to schedule process %d: %v", pid, err)
+	}
+	state.failures++
+	state.lastError = err.Error()
+}
@@ -0,0 +1,128 @@
+package processscheduler
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+	"golang.org/x/sys/unix"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+)
+
+// startSleep starts a process to schedule, it is killed when the test ends
+func startSleep(t *testing.T) int32 {
+	t.Helper()
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep could not be started: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return int32(cmd.Process.Pid)
+}
+
+// allowedCPUs are the CPUs the test, and the processes it starts, may run on
+func allowedCPUs(t *testing.T) []int {
+	t.Helper()
+	var set unix.CPUSet
+	require.NoError(t, unix.SchedGetaffinity(0, &set))
+	cpus := []int{}
+	for cpu := 0; len(cpus) < set.Count(); cpu++ {
+		if set.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
+
+func TestEnforce(t *testing.T) {
+	pid := startSleep(t)
+	cpus := allowedCPUs(t)
+	// Settings any user may set on their own processes
+	s, err := newSchedule(&ComponentConfig{CPUs: sensors.FormatCPUList(cpus[:1]), Nice: intPtr(10), IOClass: sensors.IOClassIdle, Policy: sensors.SchedBatch})
+	require.NoError(t, err)
+	c := &Config{logger: logging.NewTestLogger(t), schedule: s}
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	expected := map[string]interface{}{SettingNice: 1, SettingIOPriority: 1, SettingPolicy: 1}
+	if len(cpus) > 1 {
+		expected[SettingCPUs] = 1
+	}
+	readings, err := c.enforce(context.Background(), pid, "sleep", started)
+	require.NoError(t, err)
+	assert.Equal(t, 1, readings["threads"])
+	assert.Equal(t, 1, readings["drifted_threads"])
+	assert.Equal(t, expected, readings["drift"])
+	assert.Equal(t, true, readings["in_sync"])
+	assert.Equal(t, len(expected), readings["corrections"])
+	assert.Equal(t, 0, readings["failures"])
+	actual := readings["actual"].(map[string]interface{})
+	assert.Equal(t, sensors.FormatCPUList(cpus[:1]), actual["cpus"])
+	assert.Equal(t, 10, actual["nice"])
+	assert.Equal(t, sensors.SchedBatch, actual["policy"])
+	assert.Equal(t, "idle", actual["io_priority"])
+
+	readings, err = c.enforce(context.Background(), pid, "sleep", started)
+	require.NoError(t, err)
+	assert.Equal(t, 0, readings["drifted_threads"])
+	assert.Equal(t, len(expected), readings["corrections"])
+
+	// The process restarted with the same PID, its counts start over
+	readings, err = c.enforce(context.Background(), pid, "sleep", started.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, true, readings["in_sync"])
+	assert.Equal(t, 0, readings["corrections"])
+
+	c.pruneStates(map[int32]bool{})
+	assert.Empty(t, c.states)
+}
+
+func TestEnforceFailure(t *testing.T) {
+	pid := startSleep(t)
+	// The kernel rejects an affinity without a CPU that exists
+	s, err := newSchedule(&ComponentConfig{CPUs: "1023"})
+	require.NoError(t, err)
+	c := &Config{logger: logging.NewTestLogger(t), schedule: s}
+
+	readings, err := c.enforce(context.Background(), pid, "sleep", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, false, readings["in_sync"])
+	assert.Equal(t, 0, readings["corrections"])
+	assert.Equal(t, 1, readings["failures"])
+	assert.Contains(t, readings["last_error"], "failed to set the cpus of thread")
+
+	// It keeps failing
+	readings, err = c.enforce(context.Background(), pid, "sleep", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{SettingCPUs: 1}, readings["drift"])
+	assert.Equal(t, 2, readings["failures"])
+}
+
+func TestEnforceDryRun(t *testing.T) {
+	pid := startSleep(t)
+	before, err := sensors.ReadThreadSchedule(context.Background(), pid, pid)
+	require.NoError(t, err)
+	nice := 19
+	if before.Nice == nice {
+		nice = 18
+	}
+	s, err := newSchedule(&ComponentConfig{Nice: intPtr(nice)})
+	require.NoError(t, err)
+	c := &Config{logger: logging.NewTestLogger(t), schedule: s, dryRun: true}
+
+	readings, err := c.enforce(context.Background(), pid, "sleep", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, false, readings["in_sync"])
+	assert.Equal(t, map[string]interface{}{SettingNice: 1}, readings["drift"])
+	assert.Equal(t, 0, readings["corrections"])
+	after, err := sensors.ReadThreadSchedule(context.Background(), pid, pid)
+	require.NoError(t, err)
+	assert.Equal(t, before.Nice, after.Nice)
+}
@@ -0,0 +1,71 @@
+package processscheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestNewSchedule(t *testing.T) {
+	s, err := newSchedule(&ComponentConfig{CPUs: "7,4-6", Nice: intPtr(-5), IOClass: sensors.IOClassBestEffort, Policy: sensors.SchedRR, RTPriority: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5, 6, 7}, s.cpus)
+	assert.Equal(t, &sensors.IOPriority{Class: sensors.IOClassBestEffort, Level: defaultIOLevel}, s.io)
+	assert.Equal(t, map[string]interface{}{
+		"cpus":        "4-7",
+		"nice":        -5,
+		"io_priority": "best-effort: prio 4",
+		"policy":      sensors.SchedRR,
+		"rt_priority": 10,
+	}, s.Map())
+
+	for name, conf := range map[string]*ComponentConfig{
+		"nothing to enforce":       {},
+		"invalid cpus":             {CPUs: "4-x"},
+		"nice out of range":        {Nice: intPtr(20)},
+		"unknown io class":         {IOClass: "fast"},
+		"io level out of range":    {IOClass: sensors.IOClassRealtime, IOLevel: intPtr(8)},
+		"io level of idle":         {IOClass: sensors.IOClassIdle, IOLevel: intPtr(0)},
+		"io level without a class": {IOLevel: intPtr(0)},
+		"unknown policy":           {Policy: sensors.SchedDeadline},
+		"fifo without a priority":  {Policy: sensors.SchedFIFO},
+		"priority out of range":    {Policy: sensors.SchedRR, RTPriority: 100},
+		"priority of other":        {Policy: sensors.SchedOther, RTPriority: 1},
+	} {
+		_, err := newSchedule(conf)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&ComponentConfig{Name: "perception", CPUs: "4-7"}).Validate("")
+	assert.NoError(t, err)
+	_, _, err = (&ComponentConfig{CPUs: "4-7"}).Validate("")
+	assert.Error(t, err)
+	_, _, err = (&ComponentConfig{Name: "perception"}).Validate("")
+	assert.Error(t, err)
+}
+
+func TestDrift(t *testing.T) {
+	s, err := newSchedule(&ComponentConfig{CPUs: "4-7", Nice: intPtr(-10), IOClass: sensors.IOClassIdle, Policy: sensors.SchedFIFO, RTPriority: 50})
+	require.NoError(t, err)
+	inSync := sensors.ThreadSchedule{TID: 43, CPUs: []int{4, 5, 6, 7}, Nice: -10, Policy: sensors.SchedFIFO, RTPriority: 50}
+	idle := sensors.IOPriority{Class: sensors.IOClassIdle}
+	assert.Empty(t, s.drift(inSync, idle))
+
+	drifted := sensors.ThreadSchedule{TID: 43, CPUs: []int{0, 1, 2, 3}, Nice: 0, Policy: sensors.SchedFIFO, RTPriority: 10}
+	settings := s.drift(drifted, sensors.IOPriority{Class: sensors.IOClassNone, Level: 4})
+	assert.Equal(t, []string{SettingCPUs, SettingNice, SettingIOPriority, SettingPolicy}, settings)
+
+	// Settings that are not configured never drift
+	s, err = newSchedule(&ComponentConfig{Nice: intPtr(-10)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{SettingNice}, s.drift(drifted, sensors.IOPriority{Class: sensors.IOClassNone, Level: 4}))
+}
@@ -0,0 +1,210 @@
+package processscheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	viamutils "go.viam.com/utils"
+)
+
+var (
+	Model       = resource.NewModel(utils.Namespace, "hwmonitor", "process_scheduler")
+	API         = sensor.API
+	PrettyName  = "SBC Process Scheduler"
+	Description = "A sensor that keeps the CPU affinity, nice, IO priority and scheduling policy of processes and their threads as configured and reports drift"
+	Version     = utils.Version
+)
+
+type Config struct {
+	resource.Named
+	readingsLock      sync.RWMutex
+	configLock        sync.Mutex
+	logger            logging.Logger
+	sleepTime         time.Duration
+	selector          *sensors.ProcessSelector
+	schedule          *schedule
+	dryRun            bool
+	disablePIDCaching bool
+	states            map[int32]*processState
+	workers           *viamutils.StoppableWorkers
+	reading           map[string]interface{}
+	config            *ComponentConfig
+	history           *utils.ReadingsHistory
+	commands          *utils.CommandDispatcher
+	refresh           *utils.Refresher
+}
+
+func init() {
+	resource.RegisterComponent(
+		API,
+		Model,
+		resource.Registration[sensor.Sensor, *ComponentConfig]{Constructor: NewSensor})
+}
+
+func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+	logger.Infof("Starting %s %s", PrettyName, Version)
+	b := Config{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		readingsLock: sync.RWMutex{},
+		configLock:   sync.Mutex{},
+		history:      utils.NewReadingsHistory(utils.DefaultHistorySize),
+		refresh:      utils.NewRefresher(),
+	}
+	b.commands = b.newCommandDispatcher()
+
+	if err := b.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Started %s %s", PrettyName, Version)
+	return &b, nil
+}
+
+func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawConf resource.Config) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Reconfiguring %s", PrettyName)
+
+	if c.workers != nil {
+		c.logger.Debug("Stopping background worker")
+		c.workers.Stop()
+		c.logger.Debugf("Background worker stopped")
+	}
+
+	conf, err := resource.NativeConfig[*ComponentConfig](rawConf)
+	if err != nil {
+		return err
+	}
+	if err := conf.selector().Validate(); err != nil {
+		return err
+	}
+	schedule, err := newSchedule(conf)
+	if err != nil {
+		return err
+	}
+
+	// In case the component has changed name
+	c.Named = rawConf.ResourceName().AsNamed()
+	if conf.SleepTimeMs <= 0 {
+		// Default to 1000ms if no sleep time is provided
+		c.logger.Warnf("Invalid sleep time %d, defaulting to 1000ms", conf.SleepTimeMs)
+		conf.SleepTimeMs = 1000 // Default to 1 second
+	}
+	c.sleepTime = time.Duration(conf.SleepTimeMs * int(time.Millisecond))
+	c.selector = conf.selector()
+	c.schedule = schedule
+	c.dryRun = conf.DryRun
+	c.disablePIDCaching = conf.DisablePIDCaching
+	// The counts start over with the new schedule
+	c.states = nil
+	c.config = conf
+	c.workers = viamutils.NewBackgroundStoppableWorkers(c.startUpdating)
+
+	c.logger.Debugf("Reconfigure complete %s", PrettyName)
+	return nil
+}
+
+func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	c.readingsLock.RLock()
+	defer c.readingsLock.RUnlock()
+	return c.reading, nil
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.configLock.Lock()
+			defer c.configLock.Unlock()
+			return c.config
+		},
+		Refresh: c.refresh.Refresh(c.Readings),
+		History: c.history,
+	})
+}
+
+func (c *Config) Close(ctx context.Context) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Shutting down %v", PrettyName)
+	c.workers.Stop()
+	c.logger.Infof("%v Shutdown complete", PrettyName)
+	return nil
+}
+
+// startUpdating is a goroutine that enforces the schedule each sleepTime, so threads a process starts later and
+// processes that restart are scheduled too
+func (c *Config) startUpdating(ctx context.Context) {
+	c.logger.Debugf("Creating process monitor for: %s", c.selector)
+	procMon, err := sensors.NewProcessMonitorWithSelector(c.logger, c.selector, c.disablePIDCaching)
+	if err != nil {
+		c.logger.Errorf("No process monitor could be created: %v", err)
+		return
+	}
+	c.updateReadings(ctx, procMon)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case done := <-c.refresh.C():
+			c.updateReadings(ctx, procMon)
+			close(done)
+		case <-time.After(c.sleepTime):
+			c.updateReadings(ctx, procMon)
+		}
+	}
+}
+
+func (c *Config) updateReadings(ctx context.Context, procMon *sensors.ProcessMonitor) {
+	procs, err := procMon.GetProcessesWithContext(ctx)
+	if err != nil {
+		c.logger.Warnf("Failed to get processes, skipping iteration: %v", err)
+		return
+	}
+
+	ret := map[string]interface{}{
+		"desired": c.schedule.Map(),
+		"dry_run": c.dryRun,
+	}
+	seen := make(map[int32]bool, procs.Len())
+	inSync, drifted := true, 0
+	for _, proc := range procs.AllFromFront() {
+		var createTime time.Time
+		if proc.Process != nil {
+			if ms, err := proc.CreateTimeWithContext(ctx); err == nil {
+				createTime = time.UnixMilli(ms)
+			}
+		}
+		readings, err := c.enforce(ctx, proc.PID, proc.Name, createTime)
+		if err != nil {
+			// The process exited since it was matched
+			c.logger.Debugf("Failed to schedule process %d: %v", proc.PID, err)
+			continue
+		}
+		seen[proc.PID] = true
+		inSync = inSync && readings["in_sync"].(bool)
+		drifted += readings["drifted_threads"].(int)
+		ret[fmt.Sprintf("%d", proc.PID)] = readings
+	}
+	c.pruneStates(seen)
+	ret["process_count"] = len(seen)
+	ret["in_sync"] = inSync
+	ret["drifted_threads"] = drifted
+
+	c.readingsLock.Lock()
+	c.reading = ret
+	c.readingsLock.Unlock()
+	c.history.Record(ret)
+}
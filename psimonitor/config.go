@@ -0,0 +1,16 @@
+package psimonitor
+
+import "errors"
+
+type ComponentConfig struct {
+	SleepTimeMs      int    `json:"sleep_time_ms"`
+	ViamServerCgroup bool   `json:"viam_server_cgroup,omitempty"`
+	Cgroup           string `json:"cgroup,omitempty"`
+}
+
+func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
+	if conf.ViamServerCgroup && conf.Cgroup != "" {
+		return nil, nil, errors.New("only one of cgroup or viam_server_cgroup is allowed")
+	}
+	return nil, nil, nil
+}
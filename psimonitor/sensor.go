@@ -0,0 +1,225 @@
+package psimonitor
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	viamutils "go.viam.com/utils"
+)
+
+var (
+	Model       = resource.NewModel(utils.Namespace, "hwmonitor", "psi_monitor")
+	API         = sensor.API
+	PrettyName  = "SBC Pressure Stall Monitor Sensor"
+	Description = "A sensor that reports the CPU, memory, IO and IRQ pressure stall information of an SBC"
+	Version     = utils.Version
+)
+
+type Config struct {
+	resource.Named
+	readingsLock sync.RWMutex
+	configLock   sync.Mutex
+	logger       logging.Logger
+	sleepTime    time.Duration
+	cgroup       string
+	workers      *viamutils.StoppableWorkers
+	reading      map[string]interface{}
+	config       *ComponentConfig
+	history      *utils.ReadingsHistory
+	commands     *utils.CommandDispatcher
+	refresh      *utils.Refresher
+}
+
+func init() {
+	resource.RegisterComponent(
+		API,
+		Model,
+		resource.Registration[sensor.Sensor, *ComponentConfig]{Constructor: NewSensor})
+}
+
+func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+	logger.Infof("Starting %s %s", PrettyName, Version)
+	b := Config{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		readingsLock: sync.RWMutex{},
+		configLock:   sync.Mutex{},
+		history:      utils.NewReadingsHistory(utils.DefaultHistorySize),
+		refresh:      utils.NewRefresher(),
+	}
+	b.commands = b.newCommandDispatcher()
+
+	if err := b.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Started %s %s", PrettyName, Version)
+	return &b, nil
+}
+
+func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, rawConf resource.Config) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Reconfiguring %s", PrettyName)
+
+	if c.workers != nil {
+		c.logger.Debug("Stopping background worker")
+		c.workers.Stop()
+		c.logger.Debugf("Background worker stopped")
+	}
+
+	conf, err := resource.NativeConfig[*ComponentConfig](rawConf)
+	if err != nil {
+		return err
+	}
+
+	// Fail now instead of logging a warning every sample on kernels without PSI
+	if _, err := sensors.ReadSystemPressure(ctx); err != nil {
+		return err
+	}
+	cgroup, err := resolveCgroup(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	// In case the component has changed name
+	c.Named = rawConf.ResourceName().AsNamed()
+	if conf.SleepTimeMs <= 0 {
+		// Default to 1000ms if no sleep time is provided
+		c.logger.Warnf("Invalid sleep time %d, defaulting to 1000ms", conf.SleepTimeMs)
+		conf.SleepTimeMs = 1000 // Default to 1 second
+	}
+	c.sleepTime = time.Duration(conf.SleepTimeMs * int(time.Millisecond))
+	c.cgroup = cgroup
+	c.config = conf
+	c.workers = viamutils.NewBackgroundStoppableWorkers(c.startUpdating)
+
+	c.logger.Debugf("Reconfigure complete %s", PrettyName)
+	return nil
+}
+
+// resolveCgroup returns the cgroup to read pressure for, the configured one or viam-server's, which is the parent of
+// this module
+func resolveCgroup(ctx context.Context, conf *ComponentConfig) (string, error) {
+	if conf.Cgroup != "" {
+		return conf.Cgroup, nil
+	}
+	if !conf.ViamServerCgroup {
+		return "", nil
+	}
+	return sensors.GetProcessCgroup(ctx, os.Getppid())
+}
+
+func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	c.readingsLock.RLock()
+	defer c.readingsLock.RUnlock()
+	return c.reading, nil
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.configLock.Lock()
+			defer c.configLock.Unlock()
+			return c.config
+		},
+		Refresh: c.refresh.Refresh(c.Readings),
+		History: c.history,
+	})
+}
+
+func (c *Config) Close(ctx context.Context) error {
+	c.configLock.Lock()
+	defer c.configLock.Unlock()
+	c.logger.Infof("Shutting down %v", PrettyName)
+	c.workers.Stop()
+	c.logger.Infof("%v Shutdown complete", PrettyName)
+	return nil
+}
+
+// pressureSample is what the background worker diffs the next sample against
+type pressureSample struct {
+	system map[string]*sensors.Pressure
+	cgroup map[string]*sensors.Pressure
+}
+
+// startUpdating is a goroutine that samples the pressure files every sleepTime so the totals can be reported as rates
+func (c *Config) startUpdating(ctx context.Context) {
+	var last *pressureSample
+	for {
+		if last == nil {
+			last = c.readSample(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case done := <-c.refresh.C():
+			last = c.updateReadings(ctx, last)
+			close(done)
+		case <-time.After(c.sleepTime):
+			last = c.updateReadings(ctx, last)
+		}
+	}
+}
+
+func (c *Config) readSample(ctx context.Context) *pressureSample {
+	system, err := sensors.ReadSystemPressure(ctx)
+	if err != nil {
+		c.logger.Warnf("Failed to read pressure, skipping iteration: %v", err)
+		return nil
+	}
+	sample := &pressureSample{system: system}
+	if c.cgroup != "" {
+		sample.cgroup, err = sensors.ReadCgroupPressure(ctx, c.cgroup)
+		if err != nil {
+			c.logger.Warnf("Failed to read pressure for cgroup %s: %v", c.cgroup, err)
+		}
+	}
+	return sample
+}
+
+// updateReadings computes stall rates since last and returns the sample to diff against next time
+func (c *Config) updateReadings(ctx context.Context, last *pressureSample) *pressureSample {
+	curr := c.readSample(ctx)
+	if curr == nil {
+		return last
+	}
+	if last == nil {
+		return curr
+	}
+	ret := make(map[string]interface{})
+	for resource, pressure := range curr.system {
+		if prev, ok := last.system[resource]; ok {
+			for k, v := range sensors.CalculatePressure(resource, prev, pressure) {
+				ret[k] = v
+			}
+		}
+	}
+	if c.cgroup != "" {
+		ret["cgroup"] = c.cgroup
+		for resource, pressure := range curr.cgroup {
+			if prev, ok := last.cgroup[resource]; ok {
+				for k, v := range sensors.CalculatePressure("cgroup_"+resource, prev, pressure) {
+					ret[k] = v
+				}
+			}
+		}
+	}
+	c.readingsLock.Lock()
+	c.reading = ret
+	c.readingsLock.Unlock()
+	c.history.Record(ret)
+	return curr
+}
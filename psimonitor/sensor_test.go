@@ -0,0 +1,53 @@
+package psimonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+)
+
+func TestUpdateReadings(t *testing.T) {
+	utilstest.UseSensorsFixtures(t)
+	ctx := context.Background()
+	sensor := &Config{
+		logger:  logging.NewTestLogger(t),
+		cgroup:  "/system.slice/viam-server.service",
+		history: utils.NewReadingsHistory(10),
+	}
+
+	last := sensor.updateReadings(ctx, nil)
+	require.NotNil(t, last)
+	assert.Nil(t, sensor.reading)
+
+	// Pretend the previous sample was taken a second ago with 100ms less io stall
+	last.system["io"] = &sensors.Pressure{
+		Timestamp: time.Now().Add(-time.Second),
+		Some:      &sensors.PressureStats{Total: 95618420 - 100000},
+		Full:      last.system["io"].Full,
+	}
+	sensor.updateReadings(ctx, last)
+	require.NotNil(t, sensor.reading)
+	assert.Equal(t, 12.40, sensor.reading["io_some_avg10"])
+	assert.InDelta(t, 10.0, sensor.reading["io_some_stall_rate"], 0.5)
+	assert.Equal(t, 0.0, sensor.reading["cpu_full_stall_rate"])
+	assert.NotContains(t, sensor.reading, "irq_full_avg10")
+	assert.Equal(t, "/system.slice/viam-server.service", sensor.reading["cgroup"])
+	assert.Equal(t, 0.5, sensor.reading["cgroup_memory_some_avg10"])
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&ComponentConfig{ViamServerCgroup: true}).Validate("")
+	assert.NoError(t, err)
+	_, _, err = (&ComponentConfig{Cgroup: "/system.slice/viam-server.service"}).Validate("")
+	assert.NoError(t, err)
+	_, _, err = (&ComponentConfig{ViamServerCgroup: true, Cgroup: "/system.slice/viam-server.service"}).Validate("")
+	assert.ErrorContains(t, err, "only one of cgroup or viam_server_cgroup")
+}
@@ -0,0 +1,73 @@
+package pwmfan
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	SetFanSpeedCommand      = "set_fan_speed"
+	ClearFanOverrideCommand = "clear_fan_override"
+)
+
+type SetFanSpeedRequest struct {
+	SpeedPct   float64 `json:"speed_pct"`
+	DurationMs int     `json:"duration_ms"`
+}
+
+type SetFanSpeedResponse struct {
+	SpeedPct  float64    `json:"speed_pct"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type ClearFanOverrideRequest struct{}
+
+type ClearFanOverrideResponse struct {
+	Cleared bool `json:"cleared"`
+}
+
+// speedOverride pins the fan to a fixed speed instead of following the temperature table
+type speedOverride struct {
+	speed   float64
+	expires time.Time
+}
+
+func (o *speedOverride) active() bool {
+	return o != nil && (o.expires.IsZero() || time.Now().Before(o.expires))
+}
+
+// setFanSpeed overrides the temperature table, either until cleared or for DurationMs
+func (c *Config) setFanSpeed(ctx context.Context, req SetFanSpeedRequest) (SetFanSpeedResponse, error) {
+	if req.SpeedPct < 0 || req.SpeedPct > 100 {
+		return SetFanSpeedResponse{}, errors.New("speed_pct must be between 0 and 100")
+	}
+	if req.DurationMs < 0 {
+		return SetFanSpeedResponse{}, errors.New("duration_ms must not be negative")
+	}
+	override := &speedOverride{speed: req.SpeedPct / 100}
+	resp := SetFanSpeedResponse{SpeedPct: req.SpeedPct}
+	if req.DurationMs > 0 {
+		override.expires = time.Now().Add(time.Duration(req.DurationMs) * time.Millisecond)
+		resp.ExpiresAt = &override.expires
+	}
+	c.overrideLock.Lock()
+	c.override = override
+	c.overrideLock.Unlock()
+	c.logger.Infof("Fan speed overridden to %.0f%%", req.SpeedPct)
+	return resp, nil
+}
+
+func (c *Config) clearFanOverride(ctx context.Context, req ClearFanOverrideRequest) (ClearFanOverrideResponse, error) {
+	c.overrideLock.Lock()
+	defer c.overrideLock.Unlock()
+	cleared := c.override.active()
+	c.override = nil
+	return ClearFanOverrideResponse{Cleared: cleared}, nil
+}
+
+func (c *Config) getOverride() *speedOverride {
+	c.overrideLock.Lock()
+	defer c.overrideLock.Unlock()
+	return c.override
+}
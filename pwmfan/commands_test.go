@@ -0,0 +1,90 @@
+package pwmfan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+func TestSetFanSpeedCommand(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{
+		logger: logging.NewTestLogger(t),
+		config: &CloudConfig{UseInternalFan: true, TemperatureTable: map[string]float64{"50": 100}},
+	}
+	sensor.commands = sensor.newCommandDispatcher()
+
+	res, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: SetFanSpeedCommand, "speed_pct": 75})
+	require.NoError(t, err)
+	assert.Equal(t, 75.0, res["speed_pct"])
+	assert.NotContains(t, res, "expires_at")
+	require.True(t, sensor.getOverride().active())
+	assert.Equal(t, 0.75, sensor.getOverride().speed)
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: ClearFanOverrideCommand})
+	require.NoError(t, err)
+	assert.Equal(t, true, res["cleared"])
+	assert.False(t, sensor.getOverride().active())
+
+	res, err = sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: ClearFanOverrideCommand})
+	require.NoError(t, err)
+	assert.Equal(t, false, res["cleared"])
+}
+
+func TestSetFanSpeedCommandExpires(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{logger: logging.NewTestLogger(t)}
+	sensor.commands = sensor.newCommandDispatcher()
+
+	res, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: SetFanSpeedCommand, "speed_pct": 100, "duration_ms": 50})
+	require.NoError(t, err)
+	assert.Contains(t, res, "expires_at")
+	assert.True(t, sensor.getOverride().active())
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, sensor.getOverride().active())
+}
+
+func TestSetFanSpeedCommandValidation(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{logger: logging.NewTestLogger(t)}
+	sensor.commands = sensor.newCommandDispatcher()
+
+	tests := []struct {
+		name string
+		cmd  map[string]interface{}
+	}{
+		{"SpeedTooHigh", map[string]interface{}{utils.CommandKey: SetFanSpeedCommand, "speed_pct": 101}},
+		{"SpeedNegative", map[string]interface{}{utils.CommandKey: SetFanSpeedCommand, "speed_pct": -1}},
+		{"DurationNegative", map[string]interface{}{utils.CommandKey: SetFanSpeedCommand, "speed_pct": 50, "duration_ms": -1}},
+		{"SpeedNotANumber", map[string]interface{}{utils.CommandKey: SetFanSpeedCommand, "speed_pct": "fast"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sensor.DoCommand(ctx, tt.cmd)
+			assert.Error(t, err)
+			assert.Nil(t, sensor.getOverride())
+		})
+	}
+}
+
+func TestGetConfigCommand(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{
+		config: &CloudConfig{FanPin: "12", BoardName: "pi", TemperatureTable: map[string]float64{"50": 100}},
+	}
+	sensor.commands = sensor.newCommandDispatcher()
+	res, err := sensor.DoCommand(ctx, map[string]interface{}{utils.CommandKey: utils.GetConfigCommand})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"fan_pin":           "12",
+		"board_name":        "pi",
+		"use_internal_fan":  false,
+		"temperature_table": map[string]interface{}{"50": 100.0},
+	}, res["config"])
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/rinzlerlabs/sbcidentify"
 	"github.com/rinzlerlabs/sbcidentify/boardtype"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/resource"
 )
@@ -24,14 +24,14 @@ func newFan(deps resource.Dependencies, boardName string, pin string, useInterna
 		if !sbcidentify.IsBoardType(boardtype.RaspberryPi5B) {
 			return nil, fmt.Errorf("internal fan is only supported on Raspberry Pi 5")
 		}
-		matches, err := filepath.Glob("/sys/class/hwmon/hwmon*/pwm1")
+		matches, err := utils.Glob("/sys/class/hwmon/hwmon*/pwm1")
 		if err != nil {
 			return nil, err
 		}
 		if len(matches) == 0 {
 			return nil, fmt.Errorf("no pwm1 file found in /sys/class/hwmon/hwmon*/")
 		}
-		internalFan, err := os.OpenFile(matches[0], os.O_RDWR, 0644)
+		internalFan, err := utils.OpenFile(matches[0], os.O_RDWR, 0644)
 		if err != nil {
 			return nil, err
 		}
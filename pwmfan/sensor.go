@@ -35,6 +35,11 @@ type Config struct {
 	temps            []float64
 	temperatureFunc  func(ctx context.Context) (*sensors.SystemTemperatures, error)
 	worker           *viam_utils.StoppableWorkers
+	config           *CloudConfig
+	history          *utils.ReadingsHistory
+	commands         *utils.CommandDispatcher
+	overrideLock     sync.Mutex
+	override         *speedOverride
 }
 
 func init() {
@@ -54,7 +59,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -106,6 +113,10 @@ func (c *Config) Reconfigure(ctx context.Context, deps resource.Dependencies, co
 		return err
 	}
 	c.temperatureFunc = tempFunc
+	c.config = newConf
+	c.overrideLock.Lock()
+	c.override = nil
+	c.overrideLock.Unlock()
 	c.worker = viam_utils.NewBackgroundStoppableWorkers(c.startUpdating)
 
 	return nil
@@ -133,10 +144,34 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 		return nil, err
 	}
 
-	return map[string]interface{}{
+	ret := map[string]interface{}{
 		"temperature":   currentTemp,
 		"fan_speed_pct": fan_speed * 100,
-	}, nil
+		"fan_override":  c.getOverride().active(),
+	}
+	c.history.Record(ret)
+	return ret, nil
+}
+
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	d := utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
+	d.Register(SetFanSpeedCommand, utils.NewCommandHandler(c.setFanSpeed))
+	d.Register(ClearFanOverrideCommand, utils.NewCommandHandler(c.clearFanOverride))
+	return d
 }
 
 func (c *Config) Close(ctx context.Context) error {
@@ -170,10 +205,14 @@ func (c *Config) startUpdating(ctx context.Context) {
 			}
 			currentTemp := *temperatures.CPU
 			var desiredSpeed float64
-			for _, targetTemp := range c.temps {
-				if currentTemp >= targetTemp {
-					desiredSpeed = c.temperatureTable[targetTemp]
-					break
+			if override := c.getOverride(); override.active() {
+				desiredSpeed = override.speed
+			} else {
+				for _, targetTemp := range c.temps {
+					if currentTemp >= targetTemp {
+						desiredSpeed = c.temperatureTable[targetTemp]
+						break
+					}
 				}
 			}
 
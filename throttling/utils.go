@@ -3,7 +3,6 @@ package throttling
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/rinzlerlabs/sbcidentify"
 	"github.com/rinzlerlabs/sbcidentify/boardtype"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/raspberrypi"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 )
 
@@ -35,12 +35,10 @@ func getThrottlingStates(ctx context.Context) (map[string]interface{}, error) {
 }
 
 func getRasPiThrottlingStates(ctx context.Context) (map[string]interface{}, error) {
-	proc := exec.CommandContext(ctx, "vcgencmd", "get_throttled")
-	outputBytes, err := proc.Output()
+	output, err := raspberrypi.Vcgencmd().Query(ctx, "get_throttled")
 	if err != nil {
 		return nil, err
 	}
-	output := string(outputBytes)
 	return parseRasPiThrottlingStates(output)
 }
 
@@ -69,7 +67,7 @@ func parseRasPiThrottlingStates(output string) (map[string]interface{}, error) {
 func getJetsonThrottlingStates(ctx context.Context) (map[string]interface{}, error) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
 	defer cancel()
-	dirs, err := filepath.Glob("/sys/class/thermal/cooling_device*")
+	dirs, err := utils.Glob("/sys/class/thermal/cooling_device*")
 	if err != nil {
 		return nil, err
 	}
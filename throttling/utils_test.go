@@ -1,8 +1,11 @@
 package throttling
 
 import (
+	"context"
 	"testing"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,3 +34,17 @@ func Test_GetThrottlingStatesForRasPi(t *testing.T) {
 	assert.True(t, res[ThrottlingOccurred].(bool))
 	assert.True(t, res[SoftTempLimitOccurred].(bool))
 }
+
+func Test_GetRasPiThrottlingStatesFromVcgencmd(t *testing.T) {
+	fake := utilstest.NewFakeCommandRunner().OnOutput("throttled=0x50005\n", "vcgencmd", "get_throttled")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+
+	res, err := getRasPiThrottlingStates(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, res[Undervolt].(bool))
+	assert.True(t, res[CurrentlyThrottled].(bool))
+	assert.True(t, res[UnderVoltOccurred].(bool))
+	assert.True(t, res[ThrottlingOccurred].(bool))
+	assert.False(t, res[SoftTempLimitOccurred].(bool))
+}
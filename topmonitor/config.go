@@ -0,0 +1,10 @@
+package topmonitor
+
+type ComponentConfig struct {
+	SleepTimeMs int `json:"sleep_time_ms"`
+	TopN        int `json:"top_n,omitempty"`
+}
+
+func (conf *ComponentConfig) Validate(path string) ([]string, []string, error) {
+	return nil, nil, nil
+}
@@ -0,0 +1,40 @@
+package topmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.viam.com/rdk/logging"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+func TestUpdateReadings(t *testing.T) {
+	ctx := context.Background()
+	sensor := &Config{
+		logger:  logging.NewTestLogger(t),
+		topN:    3,
+		history: utils.NewReadingsHistory(10),
+	}
+
+	last := sensor.updateReadings(ctx, nil)
+	require.NotNil(t, last)
+	assert.Nil(t, sensor.reading)
+
+	last.Timestamp = time.Now().Add(-time.Second)
+	sensor.updateReadings(ctx, last)
+	require.NotNil(t, sensor.reading)
+	assert.Greater(t, sensor.reading["process_count"], 0)
+	for _, key := range []string{"top_cpu", "top_rss", "top_io", "top_threads"} {
+		top := sensor.reading[key].([]interface{})
+		require.NotEmpty(t, top, key)
+		assert.LessOrEqual(t, len(top), 3, key)
+		entry := top[0].(map[string]interface{})
+		for _, field := range []string{"pid", "name", "user", "cmdline", "cpu_percent", "rss", "io_read_bytes_per_sec", "io_write_bytes_per_sec", "threads"} {
+			assert.Contains(t, entry, field)
+		}
+	}
+}
@@ -18,12 +18,13 @@ type cappedCollection[T interface{}] struct {
 // The collection will hold items of any type specified by the generic type parameter T.
 //
 // Parameters:
-//   - size: The maximum number of items the collection can hold.
+//   - size: The maximum number of items the collection can hold. A size below 1 is raised to 1.
 //
 // Returns:
 //
 //	A pointer to a new CappedCollection instance with the specified size.
 func NewCappedCollection[T interface{}](size int) CappedCollection[T] {
+	size = max(size, 1)
 	return &cappedCollection[T]{
 		items: make([]T, 0, size),
 		size:  size,
@@ -45,15 +46,14 @@ func (q *cappedCollection[T]) Push(item T) {
 	q.position = (q.position + 1) % q.size
 }
 
-// Items returns a slice containing all the items in the CappedCollection.
-// The method locks the collection to ensure thread safety during the read operation.
-// It creates a new slice with a capacity equal to the size of the collection and appends all items to it.
-// The returned slice is a copy of the collection's items at the time of the call.
+// Items returns a copy of the items in the CappedCollection, oldest first. Once the collection is full, Push
+// overwrites the oldest item in place, so the items are copied starting from the next one to be overwritten.
+// This method is thread-safe.
 func (q *cappedCollection[T]) Items() []T {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	ret := make([]T, 0, q.size)
-	ret = append(ret, q.items...)
-
+	ret = append(ret, q.items[q.position:]...)
+	ret = append(ret, q.items[:q.position]...)
 	return ret
 }
@@ -33,5 +33,20 @@ func Test_CappedCollection(t *testing.T) {
 
 	collection.Push(6)
 	assert.Len(t, collection.Items(), 5)
-	assert.Equal(t, []int{6, 2, 3, 4, 5}, collection.Items())
+	assert.Equal(t, []int{2, 3, 4, 5, 6}, collection.Items())
+
+	// Oldest first however many times it wrapped around
+	for i := 7; i <= 13; i++ {
+		collection.Push(i)
+	}
+	assert.Equal(t, []int{9, 10, 11, 12, 13}, collection.Items())
+}
+
+func Test_CappedCollection_SizeBelowOne(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		collection := NewCappedCollection[int](size)
+		collection.Push(1)
+		collection.Push(2)
+		assert.Equal(t, []int{2}, collection.Items())
+	}
 }
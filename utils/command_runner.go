@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCommandTimeout is the deadline applied to a command that does not set its own.
+const DefaultCommandTimeout = 5 * time.Second
+
+var (
+	ErrCommandTimeout  = errors.New("command timed out")
+	ErrCommandNotFound = errors.New("command not found")
+)
+
+// Command is a single invocation of an external program.
+type Command struct {
+	Name  string
+	Args  []string
+	Stdin io.Reader
+	// Timeout bounds the run, DefaultCommandTimeout is used when it is 0.
+	Timeout time.Duration
+}
+
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// CommandError is returned when a command fails to start, exits non-zero or times out.
+type CommandError struct {
+	Command string
+	// ExitCode is -1 when the command did not exit on its own
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Command, e.Err)
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// CommandRunner runs external programs. Every backend goes through it so calls are bounded by a
+// deadline and can be scripted in tests with a utilstest.FakeCommandRunner.
+type CommandRunner interface {
+	// Run runs cmd and returns its stdout. Failures are returned as a *CommandError.
+	Run(ctx context.Context, cmd Command) ([]byte, error)
+	LookPath(file string) (string, error)
+}
+
+type execCommandRunner struct{}
+
+// NewExecCommandRunner returns a CommandRunner that runs programs on the host.
+func NewExecCommandRunner() CommandRunner {
+	return &execCommandRunner{}
+}
+
+func (r *execCommandRunner) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	proc := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	proc.Stdin = cmd.Stdin
+	var stderr bytes.Buffer
+	proc.Stderr = &stderr
+	output, err := proc.Output()
+	if err != nil {
+		cmdErr := &CommandError{Command: cmd.String(), ExitCode: -1, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmdErr.ExitCode = exitErr.ExitCode()
+		} else if errors.Is(err, exec.ErrNotFound) {
+			cmdErr.Err = errors.Join(ErrCommandNotFound, err)
+		}
+		return output, cmdErr
+	}
+	return output, nil
+}
+
+func (r *execCommandRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+var (
+	commandRunnerLock sync.RWMutex
+	commandRunner     = NewExecCommandRunner()
+)
+
+// Runner returns the CommandRunner used for all external programs.
+func Runner() CommandRunner {
+	commandRunnerLock.RLock()
+	defer commandRunnerLock.RUnlock()
+	return commandRunner
+}
+
+// SetCommandRunner replaces the CommandRunner used for all external programs and returns the previous one.
+func SetCommandRunner(r CommandRunner) CommandRunner {
+	commandRunnerLock.Lock()
+	defer commandRunnerLock.Unlock()
+	previous := commandRunner
+	commandRunner = r
+	return previous
+}
+
+// RunCommand runs name with args using the default timeout and returns its stdout.
+func RunCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return Command{Name: name, Args: args}.Run(ctx)
+}
+
+// Run runs the command with the current Runner, applying its deadline and recording metrics.
+func (c Command) Run(ctx context.Context) ([]byte, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultCommandTimeout
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	output, err := Runner().Run(timeoutCtx, c)
+	timedOut := err != nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded)
+	if timedOut {
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) {
+			cmdErr = &CommandError{Command: c.String(), ExitCode: -1}
+			err = cmdErr
+		}
+		cmdErr.Err = fmt.Errorf("%w after %v", ErrCommandTimeout, timeout)
+	}
+	commandMetrics.record(c.Name, time.Since(start), err, timedOut)
+	return output, err
+}
+
+// LookPath searches for file with the current Runner.
+func LookPath(file string) (string, error) {
+	return Runner().LookPath(file)
+}
+
+// CommandStats are the counters kept for each program run through Command.Run.
+type CommandStats struct {
+	Calls         int64         `json:"calls"`
+	Failures      int64         `json:"failures"`
+	Timeouts      int64         `json:"timeouts"`
+	TotalDuration time.Duration `json:"total_duration"`
+	LastDuration  time.Duration `json:"last_duration"`
+	LastError     string        `json:"last_error,omitempty"`
+}
+
+type commandMetricsRegistry struct {
+	mu    sync.Mutex
+	stats map[string]*CommandStats
+}
+
+var commandMetrics = &commandMetricsRegistry{stats: make(map[string]*CommandStats)}
+
+func (m *commandMetricsRegistry) record(name string, duration time.Duration, err error, timedOut bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats, ok := m.stats[name]
+	if !ok {
+		stats = &CommandStats{}
+		m.stats[name] = stats
+	}
+	stats.Calls++
+	stats.TotalDuration += duration
+	stats.LastDuration = duration
+	if err != nil {
+		stats.Failures++
+		stats.LastError = err.Error()
+	}
+	if timedOut {
+		stats.Timeouts++
+	}
+}
+
+// CommandMetrics returns a snapshot of the stats for every program that has been run, keyed by program name.
+func CommandMetrics() map[string]CommandStats {
+	commandMetrics.mu.Lock()
+	defer commandMetrics.mu.Unlock()
+	ret := make(map[string]CommandStats, len(commandMetrics.stats))
+	for name, stats := range commandMetrics.stats {
+		ret[name] = *stats
+	}
+	return ret
+}
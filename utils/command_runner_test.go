@@ -0,0 +1,90 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useCommandRunner(t *testing.T, r utils.CommandRunner) {
+	t.Helper()
+	previous := utils.SetCommandRunner(r)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+}
+
+func TestFakeCommandRunner_ReplaysResponsesInOrder(t *testing.T) {
+	ctx := context.Background()
+	fake := utilstest.NewFakeCommandRunner().
+		OnOutput("temp=45.0'C\n", "vcgencmd", "measure_temp").
+		OnOutput("temp=46.0'C\n", "vcgencmd", "measure_temp")
+	useCommandRunner(t, fake)
+
+	for _, expected := range []string{"temp=45.0'C\n", "temp=46.0'C\n", "temp=46.0'C\n"} {
+		output, err := utils.RunCommand(ctx, "vcgencmd", "measure_temp")
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(output))
+	}
+	assert.Equal(t, []string{"vcgencmd measure_temp", "vcgencmd measure_temp", "vcgencmd measure_temp"}, fake.Calls())
+
+	path, err := utils.LookPath("vcgencmd")
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/bin/vcgencmd", path)
+	_, err = utils.LookPath("nvidia-smi")
+	assert.ErrorIs(t, err, exec.ErrNotFound)
+}
+
+func TestCommand_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+	useCommandRunner(t, utilstest.NewFakeCommandRunner().
+		On(utilstest.FakeResponse{ExitCode: 237, Stderr: "command failed: No such device (-19)"}, "iw", "dev", "wlan1", "link"))
+
+	_, err := utils.RunCommand(ctx, "iw", "dev", "wlan1", "link")
+	var cmdErr *utils.CommandError
+	require.True(t, errors.As(err, &cmdErr))
+	assert.Equal(t, 237, cmdErr.ExitCode)
+	assert.Equal(t, "command failed: No such device (-19)", cmdErr.Stderr)
+	assert.Equal(t, "iw dev wlan1 link: exit status 237: command failed: No such device (-19)", err.Error())
+
+	_, err = utils.RunCommand(ctx, "nvpmodel", "-q")
+	assert.ErrorIs(t, err, utils.ErrCommandNotFound)
+}
+
+func TestCommand_Timeout(t *testing.T) {
+	ctx := context.Background()
+	useCommandRunner(t, utilstest.NewFakeCommandRunner().On(utilstest.FakeResponse{Output: "volt=0.85V", Delay: time.Minute}, "hung-vcgencmd", "measure_volts"))
+	// The metrics are kept for the life of the module, only count the calls of this run
+	before := utils.CommandMetrics()["hung-vcgencmd"]
+
+	start := time.Now()
+	_, err := utils.Command{Name: "hung-vcgencmd", Args: []string{"measure_volts"}, Timeout: 10 * time.Millisecond}.Run(ctx)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, utils.ErrCommandTimeout)
+	var cmdErr *utils.CommandError
+	require.True(t, errors.As(err, &cmdErr))
+	assert.Equal(t, -1, cmdErr.ExitCode)
+
+	stats := utils.CommandMetrics()["hung-vcgencmd"]
+	assert.Equal(t, before.Calls+1, stats.Calls)
+	assert.Equal(t, before.Failures+1, stats.Failures)
+	assert.Equal(t, before.Timeouts+1, stats.Timeouts)
+}
+
+func TestExecCommandRunner(t *testing.T) {
+	if _, err := exec.LookPath("false"); err != nil {
+		t.Skip("This test requires the false command")
+	}
+	_, err := utils.NewExecCommandRunner().Run(context.Background(), utils.Command{Name: "false"})
+	var cmdErr *utils.CommandError
+	require.True(t, errors.As(err, &cmdErr))
+	assert.Equal(t, 1, cmdErr.ExitCode)
+
+	_, err = utils.NewExecCommandRunner().Run(context.Background(), utils.Command{Name: "does-not-exist-hwmonitor"})
+	assert.ErrorIs(t, err, utils.ErrCommandNotFound)
+}
@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CommandKey is the key in a DoCommand request that selects the command to run.
+const CommandKey = "command"
+
+const (
+	CapabilitiesCommand = "capabilities"
+	RefreshCommand      = "refresh"
+	GetConfigCommand    = "get_config"
+	HistoryCommand      = "history"
+
+	// DefaultHistorySize is the number of readings kept for the history command.
+	DefaultHistorySize = 100
+)
+
+var (
+	ErrMissingCommand = errors.New("missing command")
+	ErrUnknownCommand = errors.New("unknown command")
+)
+
+// CommandHandler handles a single DoCommand request. The request map includes the command key.
+type CommandHandler func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error)
+
+// NewCommandHandler wraps a typed command function in a CommandHandler.
+// The request map is decoded into Req and Resp is encoded back into a map using
+// their json tags, so the response only contains types DoCommand can serialize.
+func NewCommandHandler[Req any, Resp any](f func(ctx context.Context, req Req) (Resp, error)) CommandHandler {
+	return func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		var req Req
+		if err := convert(cmd, &req); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		resp, err := f(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		ret := make(map[string]interface{})
+		if err := convert(resp, &ret); err != nil {
+			return nil, fmt.Errorf("invalid response: %w", err)
+		}
+		return ret, nil
+	}
+}
+
+func convert(from interface{}, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
+
+// CommandDispatcher routes DoCommand requests to registered handlers by the value of CommandKey.
+type CommandDispatcher struct {
+	mu       sync.RWMutex
+	handlers *SortedMap[CommandHandler]
+}
+
+// CommonCommands holds the hooks behind the commands every sensor supports.
+// Any hook left nil is not registered.
+type CommonCommands struct {
+	// Config returns the currently applied component config.
+	Config func() interface{}
+	// Refresh forces a new set of readings and returns them.
+	Refresh func(ctx context.Context) (map[string]interface{}, error)
+	// History holds the most recent readings.
+	History *ReadingsHistory
+}
+
+// Refresher lets the refresh command ask a background worker for a sample now instead of waiting for the next one.
+// The worker receives from C and closes what it received once the sample is taken.
+type Refresher struct {
+	requests chan chan struct{}
+}
+
+func NewRefresher() *Refresher {
+	return &Refresher{requests: make(chan chan struct{})}
+}
+
+// C is the channel the worker receives requests on. A nil Refresher never sends.
+func (r *Refresher) C() <-chan chan struct{} {
+	if r == nil {
+		return nil
+	}
+	return r.requests
+}
+
+// Request asks the worker for a sample and waits until it has been taken
+func (r *Refresher) Request(ctx context.Context) error {
+	if r == nil {
+		return errors.New("refresh is not supported")
+	}
+	done := make(chan struct{})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r.requests <- done:
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+	}
+	return nil
+}
+
+// Refresh is a CommonCommands.Refresh hook that requests a sample and returns the readings it produced
+func (r *Refresher) Refresh(readings func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error)) func(ctx context.Context) (map[string]interface{}, error) {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		if err := r.Request(ctx); err != nil {
+			return nil, err
+		}
+		return readings(ctx, nil)
+	}
+}
+
+type CapabilitiesRequest struct{}
+
+type CapabilitiesResponse struct {
+	Commands []string `json:"commands"`
+}
+
+type RefreshRequest struct{}
+
+type RefreshResponse struct {
+	Readings map[string]interface{} `json:"readings"`
+}
+
+type GetConfigRequest struct{}
+
+type GetConfigResponse struct {
+	Config interface{} `json:"config"`
+}
+
+type HistoryRequest struct {
+	Limit int `json:"limit"`
+}
+
+type HistoryResponse struct {
+	Samples []ReadingsSample `json:"samples"`
+}
+
+// NewCommandDispatcher creates a CommandDispatcher with the capabilities command and
+// any of the common commands that have a hook in common.
+func NewCommandDispatcher(common CommonCommands) *CommandDispatcher {
+	d := &CommandDispatcher{handlers: New[CommandHandler]()}
+	d.Register(CapabilitiesCommand, NewCommandHandler(func(ctx context.Context, req CapabilitiesRequest) (CapabilitiesResponse, error) {
+		return CapabilitiesResponse{Commands: d.Commands()}, nil
+	}))
+	if common.Config != nil {
+		d.Register(GetConfigCommand, NewCommandHandler(func(ctx context.Context, req GetConfigRequest) (GetConfigResponse, error) {
+			return GetConfigResponse{Config: common.Config()}, nil
+		}))
+	}
+	if common.Refresh != nil {
+		d.Register(RefreshCommand, NewCommandHandler(func(ctx context.Context, req RefreshRequest) (RefreshResponse, error) {
+			readings, err := common.Refresh(ctx)
+			if err != nil {
+				return RefreshResponse{}, err
+			}
+			return RefreshResponse{Readings: readings}, nil
+		}))
+	}
+	if common.History != nil {
+		d.Register(HistoryCommand, NewCommandHandler(func(ctx context.Context, req HistoryRequest) (HistoryResponse, error) {
+			if req.Limit < 0 {
+				return HistoryResponse{}, errors.New("limit must not be negative")
+			}
+			return HistoryResponse{Samples: common.History.Samples(req.Limit)}, nil
+		}))
+	}
+	return d
+}
+
+// Register adds a handler for the named command, replacing any existing handler.
+func (d *CommandDispatcher) Register(name string, handler CommandHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers.Set(name, handler)
+}
+
+// Commands returns the names of all registered commands in sorted order.
+func (d *CommandDispatcher) Commands() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]string{}, d.handlers.Keys()...)
+}
+
+// DoCommand runs the handler registered for the command named in cmd.
+func (d *CommandDispatcher) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if d == nil {
+		return nil, ErrUnknownCommand
+	}
+	raw, ok := cmd[CommandKey]
+	if !ok {
+		return nil, ErrMissingCommand
+	}
+	name, ok := raw.(string)
+	if !ok || name == "" {
+		return nil, ErrMissingCommand
+	}
+	d.mu.RLock()
+	handler, ok := d.handlers.Get(name)
+	d.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+	}
+	return handler(ctx, cmd)
+}
+
+// ReadingsSample is a single set of readings and the time they were taken.
+type ReadingsSample struct {
+	Time     time.Time              `json:"time"`
+	Readings map[string]interface{} `json:"readings"`
+}
+
+// ReadingsHistory keeps the most recent readings of a sensor. A nil ReadingsHistory discards everything.
+type ReadingsHistory struct {
+	samples CappedCollection[ReadingsSample]
+}
+
+func NewReadingsHistory(size int) *ReadingsHistory {
+	return &ReadingsHistory{samples: NewCappedCollection[ReadingsSample](size)}
+}
+
+// Record adds a set of readings to the history, evicting the oldest sample when full.
+func (h *ReadingsHistory) Record(readings map[string]interface{}) {
+	if h == nil {
+		return
+	}
+	h.samples.Push(ReadingsSample{Time: time.Now(), Readings: readings})
+}
+
+// Samples returns up to limit of the most recent samples, oldest first. A limit of 0 returns everything.
+func (h *ReadingsHistory) Samples(limit int) []ReadingsSample {
+	if h == nil {
+		return []ReadingsSample{}
+	}
+	samples := h.samples.Items()
+	if limit > 0 && limit < len(samples) {
+		samples = samples[len(samples)-limit:]
+	}
+	return samples
+}
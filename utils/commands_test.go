@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestCommandDispatcher_Capabilities(t *testing.T) {
+	ctx := context.Background()
+	d := NewCommandDispatcher(CommonCommands{
+		Config:  func() interface{} { return nil },
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) { return nil, nil },
+		History: NewReadingsHistory(5),
+	})
+	d.Register("custom", func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	res, err := d.DoCommand(ctx, map[string]interface{}{CommandKey: CapabilitiesCommand})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{CapabilitiesCommand, "custom", GetConfigCommand, HistoryCommand, RefreshCommand}, res["commands"])
+}
+
+func TestCommandDispatcher_OnlyRegistersProvidedCommonCommands(t *testing.T) {
+	d := NewCommandDispatcher(CommonCommands{})
+	assert.Equal(t, []string{CapabilitiesCommand}, d.Commands())
+}
+
+func TestCommandDispatcher_Errors(t *testing.T) {
+	ctx := context.Background()
+	d := NewCommandDispatcher(CommonCommands{})
+
+	_, err := d.DoCommand(ctx, map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrMissingCommand)
+
+	_, err = d.DoCommand(ctx, map[string]interface{}{CommandKey: 1})
+	assert.ErrorIs(t, err, ErrMissingCommand)
+
+	_, err = d.DoCommand(ctx, map[string]interface{}{CommandKey: "does_not_exist"})
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+
+	var nilDispatcher *CommandDispatcher
+	_, err = nilDispatcher.DoCommand(ctx, map[string]interface{}{CommandKey: CapabilitiesCommand})
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+}
+
+func TestCommandDispatcher_GetConfig(t *testing.T) {
+	ctx := context.Background()
+	conf := &testConfig{Name: "viam-server", Count: 2}
+	d := NewCommandDispatcher(CommonCommands{Config: func() interface{} { return conf }})
+	res, err := d.DoCommand(ctx, map[string]interface{}{CommandKey: GetConfigCommand})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "viam-server", "count": 2.0}, res["config"])
+}
+
+func TestCommandDispatcher_Refresh(t *testing.T) {
+	ctx := context.Background()
+	refreshErr := errors.New("refresh failed")
+	var fail bool
+	d := NewCommandDispatcher(CommonCommands{Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+		if fail {
+			return nil, refreshErr
+		}
+		return map[string]interface{}{"cpu": 12.5}, nil
+	}})
+	res, err := d.DoCommand(ctx, map[string]interface{}{CommandKey: RefreshCommand})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cpu": 12.5}, res["readings"])
+
+	fail = true
+	_, err = d.DoCommand(ctx, map[string]interface{}{CommandKey: RefreshCommand})
+	assert.ErrorIs(t, err, refreshErr)
+}
+
+func TestRefresher(t *testing.T) {
+	ctx := context.Background()
+	r := NewRefresher()
+	samples := 0
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case done := <-r.C():
+				samples++
+				close(done)
+			}
+		}
+	}()
+	refresh := r.Refresh(func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"samples": samples}, nil
+	})
+	readings, err := refresh(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"samples": 1}, readings)
+
+	// Nobody is receiving, the caller's deadline applies
+	idle := NewRefresher()
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, idle.Request(timeoutCtx), context.DeadlineExceeded)
+
+	var nilRefresher *Refresher
+	assert.Nil(t, nilRefresher.C())
+	assert.Error(t, nilRefresher.Request(ctx))
+}
+
+func TestCommandDispatcher_History(t *testing.T) {
+	ctx := context.Background()
+	history := NewReadingsHistory(3)
+	for i := 0; i < 5; i++ {
+		history.Record(map[string]interface{}{"i": i})
+	}
+	d := NewCommandDispatcher(CommonCommands{History: history})
+
+	res, err := d.DoCommand(ctx, map[string]interface{}{CommandKey: HistoryCommand})
+	require.NoError(t, err)
+	samples := res["samples"].([]interface{})
+	require.Len(t, samples, 3)
+	for i, sample := range samples {
+		assert.Equal(t, float64(i+2), sample.(map[string]interface{})["readings"].(map[string]interface{})["i"])
+	}
+
+	res, err = d.DoCommand(ctx, map[string]interface{}{CommandKey: HistoryCommand, "limit": 1})
+	require.NoError(t, err)
+	samples = res["samples"].([]interface{})
+	require.Len(t, samples, 1)
+	assert.Equal(t, 4.0, samples[0].(map[string]interface{})["readings"].(map[string]interface{})["i"])
+
+	_, err = d.DoCommand(ctx, map[string]interface{}{CommandKey: HistoryCommand, "limit": -1})
+	assert.Error(t, err)
+
+	_, err = d.DoCommand(ctx, map[string]interface{}{CommandKey: HistoryCommand, "limit": "ten"})
+	assert.Error(t, err)
+}
+
+func TestNewCommandHandler_DecodesTypedRequest(t *testing.T) {
+	ctx := context.Background()
+	handler := NewCommandHandler(func(ctx context.Context, req testConfig) (testConfig, error) {
+		req.Count++
+		return req, nil
+	})
+	res, err := handler(ctx, map[string]interface{}{CommandKey: "increment", "name": "fan", "count": 41})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "fan", "count": 42.0}, res)
+}
+
+func TestReadingsHistory_NilIsSafe(t *testing.T) {
+	var history *ReadingsHistory
+	history.Record(map[string]interface{}{"a": 1})
+	assert.Empty(t, history.Samples(0))
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"io"
 	"math"
-	"os"
 	"strconv"
 	"strings"
 )
@@ -18,7 +17,7 @@ var (
 func ReadFileWithContext(ctx context.Context, path string) (string, error) {
 	fileChan := make(chan []byte, 1)
 	errChan := make(chan error, 1)
-	f, err := os.Open(path)
+	f, err := FS().Open(path)
 	if err != nil {
 		return "", err
 	}
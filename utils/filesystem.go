@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The environment variables used to relocate the host filesystem, these match the ones gopsutil uses
+// so both see the same host when the module runs in a container.
+const (
+	HostRootEnv = "HOST_ROOT"
+	HostSysEnv  = "HOST_SYS"
+	HostProcEnv = "HOST_PROC"
+	HostDevEnv  = "HOST_DEV"
+)
+
+// FileSystem is the root all sysfs, procfs and devfs reads go through. Names are the absolute paths
+// the kernel documents (ex: /sys/class/thermal) and are resolved against wherever the root is mounted.
+type FileSystem interface {
+	// Path returns the real path for name.
+	Path(name string) string
+	Open(name string) (*os.File, error)
+	OpenFile(name string, flag int, perm os.FileMode) (*os.File, error)
+	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+	// Glob returns the names matching pattern, the results are absolute paths in the same form as pattern.
+	Glob(pattern string) ([]string, error)
+}
+
+type mount struct {
+	name string
+	path string
+}
+
+type mountedFileSystem struct {
+	// sorted longest name first so the most specific mount wins
+	mounts []mount
+}
+
+// NewFileSystem returns a FileSystem rooted at root, ex: a fixture tree in tests or a chroot.
+func NewFileSystem(root string) FileSystem {
+	return newMountedFileSystem(root, nil)
+}
+
+// NewFileSystemFromEnv returns a FileSystem rooted at HOST_ROOT, with /sys, /proc and /dev
+// optionally relocated by HOST_SYS, HOST_PROC and HOST_DEV. Unset variables fall back to the
+// matching directory under the root, which defaults to /.
+func NewFileSystemFromEnv() FileSystem {
+	root := os.Getenv(HostRootEnv)
+	if root == "" {
+		root = "/"
+	}
+	mounts := make(map[string]string)
+	for name, env := range map[string]string{"/sys": HostSysEnv, "/proc": HostProcEnv, "/dev": HostDevEnv} {
+		if p := os.Getenv(env); p != "" {
+			mounts[name] = p
+		}
+	}
+	return newMountedFileSystem(root, mounts)
+}
+
+func newMountedFileSystem(root string, mounts map[string]string) *mountedFileSystem {
+	f := &mountedFileSystem{mounts: []mount{{name: "/", path: root}}}
+	for name, p := range mounts {
+		f.mounts = append(f.mounts, mount{name: name, path: p})
+	}
+	sort.Slice(f.mounts, func(i, j int) bool {
+		return len(f.mounts[i].name) > len(f.mounts[j].name)
+	})
+	return f
+}
+
+func (f *mountedFileSystem) mountFor(name string) (mount, string, bool) {
+	if !path.IsAbs(name) {
+		return mount{}, name, false
+	}
+	name = path.Clean(name)
+	for _, m := range f.mounts {
+		if m.name == "/" || name == m.name || strings.HasPrefix(name, m.name+"/") {
+			return m, strings.TrimPrefix(name, m.name), true
+		}
+	}
+	return mount{}, name, false
+}
+
+func (f *mountedFileSystem) Path(name string) string {
+	m, rel, ok := f.mountFor(name)
+	if !ok {
+		return name
+	}
+	return filepath.Join(m.path, filepath.FromSlash(rel))
+}
+
+func (f *mountedFileSystem) Open(name string) (*os.File, error) {
+	return os.Open(f.Path(name))
+}
+
+func (f *mountedFileSystem) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(f.Path(name), flag, perm)
+}
+
+func (f *mountedFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(f.Path(name))
+}
+
+func (f *mountedFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(f.Path(name))
+}
+
+func (f *mountedFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(f.Path(name))
+}
+
+func (f *mountedFileSystem) Glob(pattern string) ([]string, error) {
+	m, rel, ok := f.mountFor(pattern)
+	if !ok {
+		return filepath.Glob(pattern)
+	}
+	root := filepath.Clean(m.path)
+	matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+	// Map the matches back to names so callers can keep joining them with kernel paths
+	for i, match := range matches {
+		matches[i] = path.Join(m.name, filepath.ToSlash(strings.TrimPrefix(match, root)))
+	}
+	return matches, nil
+}
+
+var (
+	fileSystemLock sync.RWMutex
+	fileSystem     = NewFileSystemFromEnv()
+)
+
+// FS returns the FileSystem used for all sysfs, procfs and devfs reads.
+func FS() FileSystem {
+	fileSystemLock.RLock()
+	defer fileSystemLock.RUnlock()
+	return fileSystem
+}
+
+// SetFileSystem replaces the FileSystem used for all sysfs, procfs and devfs reads and returns the previous one.
+func SetFileSystem(f FileSystem) FileSystem {
+	fileSystemLock.Lock()
+	defer fileSystemLock.Unlock()
+	previous := fileSystem
+	fileSystem = f
+	return previous
+}
+
+func Glob(pattern string) ([]string, error) {
+	return FS().Glob(pattern)
+}
+
+func Stat(name string) (fs.FileInfo, error) {
+	return FS().Stat(name)
+}
+
+func ReadFile(name string) ([]byte, error) {
+	return FS().ReadFile(name)
+}
+
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	return FS().ReadDir(name)
+}
+
+func OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return FS().OpenFile(name, flag, perm)
+}
+
+// Readlink returns the target of the symlink name, ex: /proc/<pid>/exe. The target is not resolved against the root.
+func Readlink(name string) (string, error) {
+	return os.Readlink(FS().Path(name))
+}
+
+// maxSymlinks is how many symlinks EvalSymlinks follows before giving up, like the kernel's limit
+const maxSymlinks = 40
+
+// EvalSymlinks returns name with every symlink in it resolved, like filepath.EvalSymlinks, except that absolute
+// targets are resolved against the root, so a link on the host like /usr/local/bin/viam-server to /opt/viam/bin
+// resolves to the host's /opt. Relative names are resolved as they are.
+func EvalSymlinks(name string) (string, error) {
+	if !path.IsAbs(name) {
+		return filepath.EvalSymlinks(name)
+	}
+	resolved := "/"
+	remaining := strings.Split(name, "/")
+	links := 0
+	for len(remaining) > 0 {
+		part := remaining[0]
+		remaining = remaining[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+		next := path.Join(resolved, part)
+		info, err := os.Lstat(FS().Path(next))
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("too many symlinks in %s", name)
+		}
+		target, err := os.Readlink(FS().Path(next))
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+		remaining = append(strings.Split(target, "/"), remaining...)
+	}
+	return resolved, nil
+}
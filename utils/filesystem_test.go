@@ -0,0 +1,84 @@
+package utils_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
+)
+
+func TestFileSystem_ResolvesAgainstRoot(t *testing.T) {
+	root := utilstest.UseFixtureFS(t, map[string]string{
+		"/sys/class/thermal/thermal_zone0/temp": "45000\n",
+		"/sys/class/thermal/thermal_zone1/temp": "46000\n",
+	})
+
+	assert.Equal(t, filepath.Join(root, "sys", "class", "thermal"), utils.FS().Path("/sys/class/thermal"))
+
+	temp, err := utils.ReadInt64FromFileWithContext(context.Background(), "/sys/class/thermal/thermal_zone0/temp")
+	require.NoError(t, err)
+	assert.Equal(t, int64(45000), temp)
+
+	matches, err := utils.Glob("/sys/class/thermal/thermal_zone*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/sys/class/thermal/thermal_zone0", "/sys/class/thermal/thermal_zone1"}, matches)
+
+	entries, err := utils.ReadDir("/sys/class/thermal")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	_, err = utils.Stat("/sys/class/thermal/thermal_zone2")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileSystem_FromEnv(t *testing.T) {
+	root := t.TempDir()
+	sys := t.TempDir()
+	utilstest.WriteFixture(t, root, "proc/net/wireless", "root proc")
+	utilstest.WriteFixture(t, root, "sys/class/hwmon/hwmon0/pwm1", "root sys")
+	utilstest.WriteFixture(t, sys, "class/hwmon/hwmon2/pwm1", "host sys")
+	t.Setenv(utils.HostRootEnv, root)
+	t.Setenv(utils.HostSysEnv, sys)
+	t.Setenv(utils.HostProcEnv, "")
+	t.Setenv(utils.HostDevEnv, "")
+	f := utils.NewFileSystemFromEnv()
+
+	data, err := f.ReadFile("/proc/net/wireless")
+	require.NoError(t, err)
+	assert.Equal(t, "root proc", string(data))
+
+	matches, err := f.Glob("/sys/class/hwmon/hwmon*/pwm1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/sys/class/hwmon/hwmon2/pwm1"}, matches)
+
+	// only whole path segments are relocated
+	assert.Equal(t, filepath.Join(root, "system"), f.Path("/system"))
+}
+
+func TestFileSystem_RelativePathsAreUnchanged(t *testing.T) {
+	f := utils.NewFileSystem(t.TempDir())
+	assert.Equal(t, "testdata/file.txt", f.Path("testdata/file.txt"))
+}
+
+func TestEvalSymlinks_ResolvesAgainstRoot(t *testing.T) {
+	root := utilstest.UseFixtureFS(t, map[string]string{"/opt/viam/bin/viam-server-0.51.0": ""})
+	utilstest.SymlinkFixture(t, root, "/usr/local/bin/viam-server", "/opt/viam/bin/viam-server-0.51.0")
+	utilstest.SymlinkFixture(t, root, "/usr/bin/viam", "../local/bin/viam-server")
+
+	resolved, err := utils.EvalSymlinks("/usr/local/bin/viam-server")
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/viam/bin/viam-server-0.51.0", resolved)
+
+	resolved, err = utils.EvalSymlinks("/usr/bin/viam")
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/viam/bin/viam-server-0.51.0", resolved)
+
+	_, err = utils.EvalSymlinks("/usr/bin/missing")
+	assert.True(t, os.IsNotExist(err))
+}
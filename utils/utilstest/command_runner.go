@@ -0,0 +1,106 @@
+package utilstest
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// FakeResponse is a scripted result for a FakeCommandRunner.
+type FakeResponse struct {
+	Output   string
+	Stderr   string
+	ExitCode int
+	// Err is returned instead of an exit status, ex: utils.ErrCommandNotFound
+	Err error
+	// Delay simulates a slow command, it is cut short if the deadline passes
+	Delay time.Duration
+}
+
+// FakeCommandRunner is a utils.CommandRunner that replays scripted responses. Responses for the same
+// command line are returned in order and the last one repeats.
+type FakeCommandRunner struct {
+	mu        sync.Mutex
+	responses map[string][]FakeResponse
+	programs  map[string]bool
+	calls     []string
+}
+
+func NewFakeCommandRunner() *FakeCommandRunner {
+	return &FakeCommandRunner{
+		responses: make(map[string][]FakeResponse),
+		programs:  make(map[string]bool),
+	}
+}
+
+// On queues a response for the command line name args.
+func (f *FakeCommandRunner) On(response FakeResponse, name string, args ...string) *FakeCommandRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := utils.Command{Name: name, Args: args}.String()
+	f.responses[key] = append(f.responses[key], response)
+	f.programs[name] = true
+	return f
+}
+
+// OnOutput queues a successful response for the command line name args.
+func (f *FakeCommandRunner) OnOutput(output string, name string, args ...string) *FakeCommandRunner {
+	return f.On(FakeResponse{Output: output}, name, args...)
+}
+
+// Calls returns the command lines that have been run, in order.
+func (f *FakeCommandRunner) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.calls...)
+}
+
+func (f *FakeCommandRunner) Run(ctx context.Context, cmd utils.Command) ([]byte, error) {
+	key := cmd.String()
+	f.mu.Lock()
+	f.calls = append(f.calls, key)
+	queue := f.responses[key]
+	if len(queue) == 0 {
+		f.mu.Unlock()
+		return nil, &utils.CommandError{Command: key, ExitCode: -1, Err: utils.ErrCommandNotFound}
+	}
+	response := queue[0]
+	if len(queue) > 1 {
+		f.responses[key] = queue[1:]
+	}
+	f.mu.Unlock()
+
+	if response.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, &utils.CommandError{Command: key, ExitCode: -1, Err: ctx.Err()}
+		case <-time.After(response.Delay):
+		}
+	}
+	if response.Err != nil {
+		return nil, &utils.CommandError{Command: key, ExitCode: -1, Stderr: response.Stderr, Err: response.Err}
+	}
+	if response.ExitCode != 0 {
+		return []byte(response.Output), &utils.CommandError{
+			Command:  key,
+			ExitCode: response.ExitCode,
+			Stderr:   response.Stderr,
+			Err:      fmt.Errorf("exit status %d", response.ExitCode),
+		}
+	}
+	return []byte(response.Output), nil
+}
+
+// LookPath finds any program that has a scripted response.
+func (f *FakeCommandRunner) LookPath(file string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.programs[file] {
+		return "/usr/bin/" + file, nil
+	}
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
@@ -0,0 +1,73 @@
+// Package utilstest has the helpers tests use to read sysfs, procfs and devfs from a fixture tree and to script the
+// external commands a backend runs
+package utilstest
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+)
+
+// UseFileSystem replaces the FileSystem for the rest of a test and restores the previous one when it ends, ex: to
+// read the fixture tree in a package's testdata directory.
+func UseFileSystem(t testing.TB, f utils.FileSystem) {
+	t.Helper()
+	previous := utils.SetFileSystem(f)
+	t.Cleanup(func() { utils.SetFileSystem(previous) })
+}
+
+// UseSensorsFixtures reads sysfs, procfs and devfs from the fixture tree of internal/sensors for the rest of a test, so
+// components are tested against the same files as the parsers they use.
+func UseSensorsFixtures(t testing.TB) {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	UseFileSystem(t, utils.NewFileSystem(filepath.Join(filepath.Dir(file), "..", "..", "internal", "sensors", "testdata")))
+}
+
+// UseFixtureFS writes files, keyed by their absolute path like /proc/42/stat, under a temporary root and reads all
+// sysfs, procfs and devfs files from it for the rest of a test. Names ending in / are created as empty directories.
+// It returns the root so the test can change the fixture with WriteFixture.
+func UseFixtureFS(t testing.TB, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, contents := range files {
+		WriteFixture(t, root, name, contents)
+	}
+	UseFileSystem(t, utils.NewFileSystem(root))
+	return root
+}
+
+// WriteFixture writes, or replaces, a file of the fixture at root. Names ending in / are created as empty directories.
+func WriteFixture(t testing.TB, root, name, contents string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(name))
+	if strings.HasSuffix(name, "/") {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// SymlinkFixture adds a symlink to the fixture at root, like the /proc/<pid>/fd and /proc/<pid>/exe links. The
+// target does not have to exist.
+func SymlinkFixture(t testing.TB, root, name, target string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, p); err != nil {
+		t.Fatal(err)
+	}
+}
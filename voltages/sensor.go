@@ -8,6 +8,8 @@ import (
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 
+	"github.com/rinzlerlabs/sbcidentify"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/raspberrypi"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 )
@@ -27,6 +29,9 @@ type Config struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 	sensors    []sensors.PowerSensor
+	config     *ComponentConfig
+	history    *utils.ReadingsHistory
+	commands   *utils.CommandDispatcher
 }
 
 func init() {
@@ -46,7 +51,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.RWMutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -59,6 +66,11 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 	defer c.mu.Unlock()
 	c.logger.Debugf("Reconfiguring %s", PrettyName)
 
+	newConf, err := resource.NativeConfig[*ComponentConfig](conf)
+	if err != nil {
+		return err
+	}
+
 	// In case the module has changed name
 	c.Named = conf.ResourceName().AsNamed()
 
@@ -75,6 +87,7 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 		return err
 	}
 	c.sensors = sensors
+	c.config = newConf
 
 	return nil
 }
@@ -94,9 +107,32 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 			ret[name+"_"+k] = v
 		}
 	}
+	c.history.Record(ret)
 	return ret, nil
 }
 
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	d := utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
+	if sbcidentify.IsRaspberryPi() {
+		raspberrypi.RegisterVcgencmdCommands(d)
+	}
+	return d
+}
+
 func (c *Config) Close(ctx context.Context) error {
 	c.logger.Infof("Shutting down %s", PrettyName)
 	c.mu.Lock()
@@ -5,6 +5,7 @@ import (
 
 	"github.com/rinzlerlabs/sbcidentify"
 	"github.com/rinzlerlabs/sbcidentify/boardtype"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/jetson"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/linux/raspberrypi"
 	"github.com/rinzlerlabs/viam-sbc-hwmonitor/internal/sensors"
@@ -17,5 +18,5 @@ func getPowerSensors(ctx context.Context, logger logging.Logger) ([]sensors.Powe
 	} else if sbcidentify.IsBoardType(boardtype.NVIDIA) {
 		return jetson.GetPowerSensors(ctx, logger)
 	}
-	return make([]sensors.PowerSensor, 0), nil
+	return linux.GetPowerSensors(ctx, logger)
 }
@@ -27,6 +27,9 @@ type Config struct {
 	cancelCtx   context.Context
 	cancelFunc  func()
 	wifiMonitor WifiMonitor
+	config      *ComponentConfig
+	history     *utils.ReadingsHistory
+	commands    *utils.CommandDispatcher
 }
 
 func init() {
@@ -46,7 +49,9 @@ func NewSensor(ctx context.Context, deps resource.Dependencies, conf resource.Co
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
 		mu:         sync.Mutex{},
+		history:    utils.NewReadingsHistory(utils.DefaultHistorySize),
 	}
+	b.commands = b.newCommandDispatcher()
 
 	if err := b.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -73,6 +78,7 @@ func (c *Config) Reconfigure(ctx context.Context, _ resource.Dependencies, conf
 		return errors.New("no suitable wifi monitor found")
 	}
 	c.wifiMonitor = mon
+	c.config = newConf
 
 	return nil
 }
@@ -82,7 +88,7 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 	defer c.mu.Unlock()
 	ret := make(map[string]interface{})
 	if c.wifiMonitor != nil {
-		status, err := c.wifiMonitor.GetNetworkStatus()
+		status, err := c.wifiMonitor.GetNetworkStatus(ctx)
 		if err == ErrAdapterNotFound {
 			ret["err"] = "adapter not found"
 		} else if err == ErrNotConnected {
@@ -99,9 +105,28 @@ func (c *Config) Readings(ctx context.Context, extra map[string]interface{}) (ma
 		ret["network"] = "unknown"
 	}
 
+	c.history.Record(ret)
 	return ret, nil
 }
 
+func (c *Config) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return c.commands.DoCommand(ctx, cmd)
+}
+
+func (c *Config) newCommandDispatcher() *utils.CommandDispatcher {
+	return utils.NewCommandDispatcher(utils.CommonCommands{
+		Config: func() interface{} {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.config
+		},
+		Refresh: func(ctx context.Context) (map[string]interface{}, error) {
+			return c.Readings(ctx, nil)
+		},
+		History: c.history,
+	})
+}
+
 func (c *Config) Close(ctx context.Context) error {
 	c.logger.Infof("Shutting down %s", PrettyName)
 	c.cancelFunc()
@@ -1,6 +1,9 @@
 package wifimonitor
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var (
 	ErrNotConnected    = errors.New("not connected to a network")
@@ -9,7 +12,7 @@ var (
 )
 
 type WifiMonitor interface {
-	GetNetworkStatus() (*networkStatus, error)
+	GetNetworkStatus(ctx context.Context) (*networkStatus, error)
 }
 
 type networkStatus struct {
@@ -1,28 +1,28 @@
 package wifimonitor
 
 import (
+	"context"
 	"errors"
-	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"go.viam.com/rdk/logging"
 )
 
 func (c *Config) newWifiMonitor(adapter string) WifiMonitor {
 	// iw has the best stats
-	if _, err := exec.LookPath("iw"); err == nil {
+	if _, err := utils.LookPath("iw"); err == nil {
 		c.logger.Infof("Using iw for wifi stats")
 		return &iwWifiMonitor{adapter: adapter, logger: c.logger}
 	}
 	// nmcli has good stats
-	if _, err := exec.LookPath("nmcli"); err == nil {
+	if _, err := utils.LookPath("nmcli"); err == nil {
 		c.logger.Infof("Using nmcli for wifi stats")
 		return &nmcliWifiMonitor{adapter: adapter, logger: c.logger}
 	}
 	// proc has basic stats
-	if _, err := os.Stat("/proc/net/wireless"); err == nil {
+	if _, err := utils.Stat("/proc/net/wireless"); err == nil {
 		c.logger.Infof("Using /proc/net/wireless for wifi stats")
 		return &procWifiMonitor{adapter: adapter, logger: c.logger}
 	}
@@ -34,9 +34,8 @@ type nmcliWifiMonitor struct {
 	adapter string
 }
 
-func (w *nmcliWifiMonitor) GetNetworkStatus() (*networkStatus, error) {
-	cmd := exec.Command("nmcli", "-t", "-f", "ACTIVE,NAME,SSID,CHAN,FREQ,RATE,SIGNAL,DEVICE", "dev", "wifi")
-	out, err := cmd.Output()
+func (w *nmcliWifiMonitor) GetNetworkStatus(ctx context.Context) (*networkStatus, error) {
+	out, err := utils.RunCommand(ctx, "nmcli", "-t", "-f", "ACTIVE,NAME,SSID,CHAN,FREQ,RATE,SIGNAL,DEVICE", "dev", "wifi")
 	if err != nil {
 		return nil, err
 	}
@@ -85,11 +84,11 @@ type iwWifiMonitor struct {
 	adapter string
 }
 
-func (w *iwWifiMonitor) GetNetworkStatus() (*networkStatus, error) {
-	cmd := exec.Command("iw", "dev", w.adapter, "link")
-	out, err := cmd.Output()
+func (w *iwWifiMonitor) GetNetworkStatus(ctx context.Context) (*networkStatus, error) {
+	out, err := utils.RunCommand(ctx, "iw", "dev", w.adapter, "link")
 	if err != nil {
-		if err.Error() == "exit status 237" {
+		var cmdErr *utils.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.ExitCode == 237 {
 			return nil, ErrAdapterNotFound
 		}
 		return nil, err
@@ -140,8 +139,8 @@ type procWifiMonitor struct {
 	adapter string
 }
 
-func (w *procWifiMonitor) GetNetworkStatus() (*networkStatus, error) {
-	out, err := os.ReadFile("/proc/net/wireless")
+func (w *procWifiMonitor) GetNetworkStatus(_ context.Context) (*networkStatus, error) {
+	out, err := utils.ReadFile("/proc/net/wireless")
 	if err != nil {
 		return nil, err
 	}
@@ -1,10 +1,13 @@
 package wifimonitor
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils/utilstest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -98,3 +101,14 @@ func TestLinuxNmcliWifiMonitor(t *testing.T) {
 		})
 	}
 }
+
+func TestLinuxIwWifiMonitorAdapterMissingExitCode(t *testing.T) {
+	fake := utilstest.NewFakeCommandRunner().
+		On(utilstest.FakeResponse{ExitCode: 237, Stderr: "command failed: No such device (-19)"}, "iw", "dev", "wlan1", "link")
+	previous := utils.SetCommandRunner(fake)
+	t.Cleanup(func() { utils.SetCommandRunner(previous) })
+
+	w := &iwWifiMonitor{adapter: "wlan1"}
+	_, err := w.GetNetworkStatus(context.Background())
+	assert.Equal(t, ErrAdapterNotFound, err)
+}
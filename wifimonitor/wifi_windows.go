@@ -3,12 +3,13 @@ package wifimonitor
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 
+	"github.com/rinzlerlabs/viam-sbc-hwmonitor/utils"
 	"go.viam.com/rdk/logging"
 )
 
@@ -21,9 +22,8 @@ type wifiMonitor struct {
 	logger  logging.Logger
 }
 
-func (w *wifiMonitor) GetNetworkStatus() (*networkStatus, error) {
-	cmd := exec.Command("netsh", "wlan", "show", "interfaces")
-	out, err := cmd.Output()
+func (w *wifiMonitor) GetNetworkStatus(ctx context.Context) (*networkStatus, error) {
+	out, err := utils.RunCommand(ctx, "netsh", "wlan", "show", "interfaces")
 	if err != nil {
 		return nil, errors.Join(err, errors.New("error running command"))
 	}